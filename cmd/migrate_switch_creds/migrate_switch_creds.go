@@ -0,0 +1,67 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// migrate_switch_creds moves the SNMP passwords of switches that SLS has in
+// plaintext to Vault, and replaces them in SLS with vault:// references.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Cray-HPE/hms-reds/internal/mapping"
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
+)
+
+func main() {
+	var sls string
+	var dryRun bool
+	flag.StringVar(&sls, "sls", "cray-sls/v1", "System Layout Service location as [host[:port]][/path]")
+	flag.BoolVar(&dryRun, "dry-run", false, "If set, only report the switches whose passwords would be moved")
+	flag.Parse()
+
+	// The same paths as REDS
+	storeConfig, err := securestore.ConfigFromEnvironment()
+	if err == nil {
+		err = securestore.SetConfig(storeConfig)
+	}
+	if err != nil {
+		fmt.Printf("Invalid secure store configuration: %s\n", err)
+		os.Exit(1)
+	}
+
+	mapping.ConfigureSLSMode(sls, nil, nil, nil, "reds-migrate-switch-creds")
+	migrations, err := mapping.MigrateSwitchPasswords(dryRun)
+
+	report, _ := json.MarshalIndent(migrations, "", "  ")
+	fmt.Println(string(report))
+	if err != nil {
+		fmt.Printf("Unable to get the switches from SLS: %s\n", err)
+		os.Exit(1)
+	}
+	for _, m := range migrations {
+		if m.Result == mapping.MIGRATION_FAILED {
+			os.Exit(1)
+		}
+	}
+}
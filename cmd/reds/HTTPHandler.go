@@ -23,9 +23,18 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
+	base "github.com/Cray-HPE/hms-base"
+	"github.com/Cray-HPE/hms-reds/internal/leader"
+	"github.com/Cray-HPE/hms-reds/internal/mapping"
+	"github.com/Cray-HPE/hms-reds/internal/model"
+	"github.com/Cray-HPE/hms-reds/internal/plan"
+	"github.com/Cray-HPE/hms-reds/internal/rotation"
 	"github.com/gorilla/mux"
 )
 
@@ -51,6 +60,383 @@ func doLivenessCheck(w http.ResponseWriter, r *http.Request) {
 	respond_204(w)
 }
 
+// Status of this REDS instance
+type serviceStatus struct {
+	Instance     string        `json:"instance"`
+	LeaseBackend string        `json:"leaseBackend"`
+	Leader       leader.Status `json:"leader"`
+
+	LastOnboarding mapping.OnboardingSummary `json:"lastOnboarding"`
+}
+
+/*
+ * Returns the status of this instance, including who the current leader is.
+ */
+func doStatus(w http.ResponseWriter, r *http.Request) {
+	st := serviceStatus{
+		Instance:     serviceName,
+		LeaseBackend: leaseBackend,
+
+		LastOnboarding: mapping.GetLastOnboardingSummary(),
+	}
+	if elector != nil {
+		st.Leader = elector.Status()
+	} else {
+		// Without leader election every instance acts as leader.
+		st.Leader = leader.Status{
+			Identity: serviceName,
+			Leader:   serviceName,
+			IsLeader: true,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(st)
+	if err != nil {
+		log.Printf("WARNING: Unable to encode status: %s", err)
+	}
+}
+
+/*
+ * Returns the onboarding status of each BMC.
+ */
+func doNodeStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(mapping.GetNodeStatuses())
+	if err != nil {
+		log.Printf("WARNING: Unable to encode node status: %s", err)
+	}
+}
+
+/*
+ * Returns whether each switch took its SNMP credentials when last checked.
+ */
+func doSwitchStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(mapping.GetSwitchStatuses())
+	if err != nil {
+		log.Printf("WARNING: Unable to encode switch status: %s", err)
+	}
+}
+
+/*
+ * Returns where the credentials of each switch came from.  With
+ * ?plaintext=true, only switches whose passwords SLS has in plaintext.
+ */
+func doSwitchCredentialSources(w http.ResponseWriter, r *http.Request) {
+	plaintextOnly := false
+	if v := r.URL.Query().Get("plaintext"); v != "" {
+		var err error
+		plaintextOnly, err = strconv.ParseBool(v)
+		if err != nil {
+			base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "Invalid plaintext "+v)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(mapping.GetSwitchCredentialSources(plaintextOnly))
+	if err != nil {
+		log.Printf("WARNING: Unable to encode switch credential sources: %s", err)
+	}
+}
+
+/*
+ * Returns the vault:// references in SLS that don't resolve.
+ */
+func doUnresolvedReferences(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(mapping.GetUnresolvedReferences())
+	if err != nil {
+		log.Printf("WARNING: Unable to encode unresolved references: %s", err)
+	}
+}
+
+// The changes REDS would have made in dry-run mode
+type planStatus struct {
+	DryRun  bool         `json:"dryRun"`
+	Dropped int          `json:"dropped"`
+	Entries []plan.Entry `json:"entries"`
+}
+
+/*
+ * Returns the plan recorded in dry-run mode.  Outside dry-run mode the plan
+ * is always empty.
+ */
+func doPlan(w http.ResponseWriter, r *http.Request) {
+	ps := planStatus{
+		DryRun:  planRecorder != nil,
+		Entries: []plan.Entry{},
+	}
+	if planRecorder != nil {
+		entries, dropped := planRecorder.Entries()
+		if entries != nil {
+			ps.Entries = entries
+		}
+		ps.Dropped = dropped
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(ps)
+	if err != nil {
+		log.Printf("WARNING: Unable to encode plan: %s", err)
+	}
+}
+
+/*
+ * Returns the credential policies a component would get its BMC and switch
+ * credentials from.  The role, subRole and type query parameters override
+ * what would otherwise come from SLS and the xname.
+ */
+func doCredentialPolicy(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	target := model.PolicyTarget{
+		Xname:   base.NormalizeHMSCompID(q.Get("xname")),
+		Role:    q.Get("role"),
+		SubRole: q.Get("subRole"),
+		Type:    q.Get("type"),
+	}
+	if base.GetHMSType(target.Xname) == base.HMSTypeInvalid {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "Invalid or missing xname")
+		return
+	}
+
+	res, err := mapping.ResolveCredentialPolicies(target)
+	if err != nil {
+		log.Printf("WARNING: Unable to resolve credential policies for %s: %s", target.Xname, err)
+		base.SendProblemDetailsGeneric(w, http.StatusServiceUnavailable,
+			"Unable to get credential policies: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(res)
+	if err != nil {
+		log.Printf("WARNING: Unable to encode credential policy: %s", err)
+	}
+}
+
+// sendDefaultsError answers with the status fitting an error from the
+// default credentials history.
+func sendDefaultsError(w http.ResponseWriter, kind string, err error) {
+	switch {
+	case kind != model.DEFAULTS_KIND_BMC && kind != model.DEFAULTS_KIND_SWITCH:
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, model.ErrNoSuchVersion):
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, err.Error())
+	default:
+		log.Printf("WARNING: Unable to get the history of the %s: %s", kind, err)
+		base.SendProblemDetailsGeneric(w, http.StatusServiceUnavailable,
+			"Unable to get default credentials history: "+err.Error())
+	}
+}
+
+/*
+ * Returns the kept versions of the BMC (defaults) or switch
+ * (switch_defaults) default credentials, oldest first, passwords redacted.
+ */
+func doDefaultsVersions(w http.ResponseWriter, r *http.Request) {
+	kind := mux.Vars(r)["kind"]
+	versions, err := mapping.GetRedsCredStore().GetCredentialHistory(kind)
+	if err != nil {
+		sendDefaultsError(w, kind, err)
+		return
+	}
+
+	redacted := make([]model.CredentialVersion, 0, len(versions))
+	for _, v := range versions {
+		redacted = append(redacted, v.Redacted())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(redacted)
+	if err != nil {
+		log.Printf("WARNING: Unable to encode default credentials history: %s", err)
+	}
+}
+
+// What changed between two versions of default credentials
+type defaultsDiff struct {
+	From    int                      `json:"from"`
+	To      int                      `json:"to"`
+	Changes []model.CredentialChange `json:"changes"`
+}
+
+/*
+ * Returns what changed between two versions of default credentials.  to
+ * defaults to the latest version.
+ */
+func doDefaultsDiff(w http.ResponseWriter, r *http.Request) {
+	kind := mux.Vars(r)["kind"]
+	store := mapping.GetRedsCredStore()
+	versions, err := store.GetCredentialHistory(kind)
+	if err != nil {
+		sendDefaultsError(w, kind, err)
+		return
+	}
+
+	q := r.URL.Query()
+	from, err := strconv.Atoi(q.Get("from"))
+	if err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "Invalid or missing from version")
+		return
+	}
+	to := 0
+	if len(versions) > 0 {
+		to = versions[len(versions)-1].Version
+	}
+	if q.Get("to") != "" {
+		to, err = strconv.Atoi(q.Get("to"))
+		if err != nil {
+			base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "Invalid to version")
+			return
+		}
+	}
+
+	a, err := store.GetCredentialVersion(kind, from)
+	if err != nil {
+		sendDefaultsError(w, kind, err)
+		return
+	}
+	b, err := store.GetCredentialVersion(kind, to)
+	if err != nil {
+		sendDefaultsError(w, kind, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(defaultsDiff{
+		From:    from,
+		To:      to,
+		Changes: model.DiffCredentialVersions(a, b),
+	})
+	if err != nil {
+		log.Printf("WARNING: Unable to encode default credentials diff: %s", err)
+	}
+}
+
+// Body of a request to roll back default credentials
+type rollbackRequest struct {
+	Version int `json:"version"`
+}
+
+/*
+ * Makes a prior version of default credentials current again.  The
+ * rollback is recorded as a new version.
+ */
+func doDefaultsRollback(w http.ResponseWriter, r *http.Request) {
+	kind := mux.Vars(r)["kind"]
+	var req rollbackRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "Unable to decode request: "+err.Error())
+		return
+	}
+
+	actor := "API request from " + r.RemoteAddr
+	if agent := r.Header.Get(base.USERAGENT); agent != "" {
+		actor += " (" + agent + ")"
+	}
+	v, err := mapping.GetRedsCredStore().RollbackDefaultCredentials(kind, req.Version, actor)
+	if err != nil {
+		sendDefaultsError(w, kind, err)
+		return
+	}
+	log.Printf("INFO: Rolled the %s back to version %d as version %d for %s", kind, req.Version, v.Version, actor)
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(v.Redacted())
+	if err != nil {
+		log.Printf("WARNING: Unable to encode default credentials version: %s", err)
+	}
+}
+
+// Body of a request to start a rotation job
+type rotationRequest struct {
+	Xnames []string `json:"xnames"`
+}
+
+/*
+ * Starts a job rotating the passwords of the given BMCs.  The job runs in
+ * the background; its progress is at the returned Location.
+ */
+func doStartRotation(w http.ResponseWriter, r *http.Request) {
+	var req rotationRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "Unable to decode request: "+err.Error())
+		return
+	}
+
+	job, err := rotations.Start(req.Xnames)
+	if err != nil {
+		status := http.StatusBadRequest
+		msg := "Unable to start rotation: " + err.Error()
+		if _, ok := err.(*rotation.BusyError); ok {
+			status = http.StatusConflict
+		} else if err == rotation.ErrNotLeader {
+			status = http.StatusServiceUnavailable
+			if elector != nil && elector.Leader() != "" {
+				msg += "; the leader is " + elector.Leader()
+			}
+		}
+		base.SendProblemDetailsGeneric(w, status, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/v1/rotation/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode(job)
+	if err != nil {
+		log.Printf("WARNING: Unable to encode rotation job: %s", err)
+	}
+}
+
+/*
+ * Returns the recent rotation jobs, oldest first.
+ */
+func doRotationJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(rotations.Jobs())
+	if err != nil {
+		log.Printf("WARNING: Unable to encode rotation jobs: %s", err)
+	}
+}
+
+/*
+ * Returns the kept rotation outcomes that need a person.
+ */
+func doRotationOutcomes(w http.ResponseWriter, r *http.Request) {
+	outcomes, err := rotations.Outcomes()
+	if err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusServiceUnavailable,
+			"Unable to read rotation outcomes from Vault: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(outcomes)
+	if err != nil {
+		log.Printf("WARNING: Unable to encode rotation outcomes: %s", err)
+	}
+}
+
+/*
+ * Returns a rotation job and the outcome for each BMC so far.
+ */
+func doRotationJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := rotations.Job(mux.Vars(r)["id"])
+	if !ok {
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "No such rotation job")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(job)
+	if err != nil {
+		log.Printf("WARNING: Unable to encode rotation job: %s", err)
+	}
+}
+
 func run_HTTPsrv() {
 	router := mux.NewRouter()
 
@@ -60,6 +446,20 @@ func run_HTTPsrv() {
 
 	subrouter.HandleFunc("/readiness", doReadinessCheck).Methods("GET")
 	subrouter.HandleFunc("/liveness", doLivenessCheck).Methods("GET")
+	subrouter.HandleFunc("/status", doStatus).Methods("GET")
+	subrouter.HandleFunc("/status/nodes", doNodeStatus).Methods("GET")
+	subrouter.HandleFunc("/status/switches", doSwitchStatus).Methods("GET")
+	subrouter.HandleFunc("/plan", doPlan).Methods("GET")
+	subrouter.HandleFunc("/credentials/policy", doCredentialPolicy).Methods("GET")
+	subrouter.HandleFunc("/credentials/switches", doSwitchCredentialSources).Methods("GET")
+	subrouter.HandleFunc("/credentials/references", doUnresolvedReferences).Methods("GET")
+	subrouter.HandleFunc("/credentials/defaults/{kind}/versions", doDefaultsVersions).Methods("GET")
+	subrouter.HandleFunc("/credentials/defaults/{kind}/diff", doDefaultsDiff).Methods("GET")
+	subrouter.HandleFunc("/credentials/defaults/{kind}/rollback", doDefaultsRollback).Methods("POST")
+	subrouter.HandleFunc("/rotation/jobs", doStartRotation).Methods("POST")
+	subrouter.HandleFunc("/rotation/jobs", doRotationJobs).Methods("GET")
+	subrouter.HandleFunc("/rotation/jobs/{id}", doRotationJob).Methods("GET")
+	subrouter.HandleFunc("/rotation/outcomes", doRotationOutcomes).Methods("GET")
 
 	log.Fatal(http.ListenAndServe(httpListen, router))
 }
@@ -42,11 +42,21 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+	"github.com/Cray-HPE/hms-reds/internal/leader"
 	"github.com/Cray-HPE/hms-reds/internal/mapping"
+	"github.com/Cray-HPE/hms-reds/internal/passwords"
+	"github.com/Cray-HPE/hms-reds/internal/plan"
+	"github.com/Cray-HPE/hms-reds/internal/rotation"
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
 	"github.com/Cray-HPE/hms-reds/internal/smdclient"
+	"github.com/Cray-HPE/hms-reds/internal/verify"
+	sstorage "github.com/Cray-HPE/hms-securestorage"
 )
 
 // Service/instance name
@@ -70,6 +80,144 @@ var sls string
 
 var insecure bool
 
+// CA bundle used to verify HSM's certificate
+var caURI string
+
+// Optional onboarding policy file
+var onboardingPolicyFile string
+
+// Picking default credentials by BMC vendor
+var vendorPolicyFile string
+var vendorFallback string
+var vendorProbe bool
+
+// Onboarding concurrency
+var onboardWorkers int
+var onboardNodeTimeout int
+
+// Number of items sent to HSM in one bulk request
+var hsmBatchSize int
+
+// Whether existing HSM endpoint credentials may be overwritten
+var hsmOverwriteProtected bool
+
+// Site defaults for components SLS doesn't describe fully
+var defaultArch string
+var defaultNetType string
+var defaultClass string
+
+// Handling of BMCs HSM fails to discover
+var discoveryRetries int
+var discoveryTimeout int
+var rediscoverRefreshCreds bool
+
+// Generated BMC passwords
+var generatePasswords bool
+var passwordRulesFile string
+
+// Checking BMC and switch credentials before use
+var verifyCredentials string
+var verifyTimeout int
+
+// BMC password rotation.  Jobs are kept by the instance that runs them,
+// outcomes needing a person in Vault.
+var rotationWorkers int
+var rotationTimeout int
+var rotations *rotation.Manager
+
+// In dry-run mode changes to HSM and Vault are recorded in planRecorder
+// instead of being made.  planRecorder is nil otherwise.
+var dryRun bool
+var planRecorder *plan.Recorder
+
+// Whether the secure store paths are checked at startup
+var storageCheck bool
+
+// Leader election settings.  Only the leader runs the SLS watchers; the
+// other replicas just serve the API.
+var leaseBackend string
+var leaseFile string
+var leaseTTL int
+var leaseRenew int
+
+// Our elector, nil if leader election is disabled
+var elector *leader.Elector
+
+var watcherLock sync.Mutex
+var switchQuitChan chan bool
+var nodeQuitChan chan bool
+
+// startWatchers starts the SLS watchers if they aren't already running.
+func startWatchers() {
+	watcherLock.Lock()
+	defer watcherLock.Unlock()
+	if switchQuitChan != nil {
+		return
+	}
+
+	log.Printf("INFO: Starting SLS watchers")
+	switchQuitChan = make(chan bool)
+	go mapping.WatchSLSNewSwitches(switchQuitChan)
+
+	nodeQuitChan = make(chan bool)
+	go mapping.WatchSLSNewManagementNodes(nodeQuitChan)
+}
+
+// stopWatchers stops the SLS watchers if they are running.
+func stopWatchers() {
+	watcherLock.Lock()
+	defer watcherLock.Unlock()
+	if switchQuitChan == nil {
+		return
+	}
+
+	// Closing rather than sending doesn't wait for a watcher to finish its
+	// current cycle, which would hold up the elector's lease renewal.
+	log.Printf("INFO: Stopping SLS watchers")
+	close(switchQuitChan)
+	close(nodeQuitChan)
+	switchQuitChan = nil
+	nodeQuitChan = nil
+}
+
+// newLeaseStore creates the lease backend selected by -lease-backend, or
+// returns nil if leader election is disabled.
+func newLeaseStore() leader.LeaseStore {
+	switch leaseBackend {
+	case "none":
+		return nil
+	case "file":
+		return leader.NewFileLeaseStore(leaseFile)
+	case "securestorage":
+		for {
+			ss, err := securestore.Connect()
+			if err != nil {
+				log.Printf("ERROR: Secure Store connection for leader lease failed - %s", err)
+				time.Sleep(5 * time.Second)
+				continue
+			}
+			return leader.NewSecureStoreLeaseStore(securestore.GetConfig().LeaderPath(), ss)
+		}
+	default:
+		log.Fatalf("Unknown lease backend '%s'", leaseBackend)
+	}
+	return nil
+}
+
+// checkSecureStore makes sure the credential paths in the secure store can
+// be read and written, or only read in dry-run mode, and exits if not.
+func checkSecureStore() {
+	ss, err := securestore.Connect()
+	if err != nil {
+		log.Fatalf("Unable to connect to secure storage: %s", err)
+	}
+	err = securestore.Check(ss, securestore.GetConfig().Paths(), !dryRun)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	log.Printf("INFO: Secure store paths checked")
+}
+
 func main() {
 	log.Print("Starting reds")
 
@@ -80,6 +228,33 @@ func main() {
 	flag.StringVar(&hsm, "hsm", "http://cray-smd/hsm/v2", "Hardware State Manager location as URI, e.g. [scheme]://[host[:port]][/path]")
 	flag.StringVar(&sls, "sls", "cray-sls/v1", "System Layout Service location as [host[:port]][/path]")
 	flag.BoolVar(&insecure, "insecure", false, "If set, allow insecure connections to Hardware State Manager.")
+	flag.StringVar(&caURI, "ca-uri", "", "CA bundle for verifying Hardware State Manager's certificate: a file or "+hms_certs.VaultCAChainURI+" (default: system roots)")
+	flag.StringVar(&leaseBackend, "lease-backend", "securestorage", "Where to keep the leader election lease: securestorage, file or none (no election)")
+	flag.StringVar(&leaseFile, "lease-file", "/var/run/reds/leader.json", "Lease file used by the 'file' lease backend")
+	flag.IntVar(&leaseTTL, "lease-ttl", 30, "Leader lease time to live in seconds")
+	flag.IntVar(&leaseRenew, "lease-renew", 10, "Leader lease renewal period in seconds")
+	flag.IntVar(&onboardWorkers, "onboard-workers", 10, "Number of BMCs onboarded in parallel")
+	flag.IntVar(&onboardNodeTimeout, "onboard-node-timeout", 60, "Seconds allowed for onboarding a single node")
+	flag.IntVar(&hsmBatchSize, "hsm-batch-size", smdclient.DefaultBatchSize, "Number of endpoints or components sent to HSM in one request")
+	flag.BoolVar(&hsmOverwriteProtected, "hsm-overwrite-protected", false, "If set, overwrite the User and Password of endpoints HSM already has")
+	flag.IntVar(&discoveryRetries, "discovery-retries", 3, "Times HSM is asked to rediscover a BMC it failed to discover")
+	flag.IntVar(&discoveryTimeout, "discovery-timeout", 600, "Seconds HSM is given to discover a BMC")
+	flag.BoolVar(&rediscoverRefreshCreds, "rediscover-refresh-creds", false, "If set, send a BMC's credentials from Vault to HSM when rediscovering it")
+	flag.StringVar(&defaultArch, "default-arch", "X86", "Arch given to master node components when SLS has none")
+	flag.StringVar(&defaultNetType, "default-net-type", "Sling", "NetType given to master node components when SLS has none")
+	flag.StringVar(&defaultClass, "default-class", "", "Class given to master node components when SLS has none")
+	flag.StringVar(&onboardingPolicyFile, "onboarding-policy", "", "JSON file selecting which SLS nodes to onboard (default: River management nodes)")
+	flag.StringVar(&vendorPolicyFile, "vendor-policy", "", "JSON file with xname prefix to vendor rules, fallback vendors and whether to probe Redfish, for picking BMC default credentials")
+	flag.StringVar(&vendorFallback, "vendor-fallback", "", "Comma separated vendors whose default credentials are tried when a BMC's vendor has none (default: Cray)")
+	flag.BoolVar(&vendorProbe, "vendor-probe", false, "If set, ask a BMC's Redfish service root for its vendor when SLS and the vendor rules don't say")
+	flag.BoolVar(&generatePasswords, "generate-passwords", false, "If set, give each new BMC a generated password of its own instead of the shared default")
+	flag.StringVar(&passwordRulesFile, "password-rules", "", "JSON file with the password complexity rules of each BMC vendor")
+	flag.StringVar(&verifyCredentials, "verify-credentials", mapping.VERIFY_MODE_OFF, "Check BMC and switch credentials work before use: off, report (in the node and switch status) or block (also don't register BMCs that reject them)")
+	flag.IntVar(&verifyTimeout, "verify-timeout", 10, "Seconds allowed for checking the credentials of a single BMC or switch")
+	flag.IntVar(&rotationWorkers, "rotation-workers", 10, "Number of BMCs a password rotation job rotates in parallel")
+	flag.IntVar(&rotationTimeout, "rotation-timeout", 60, "Seconds allowed for rotating the password of a single BMC")
+	flag.BoolVar(&storageCheck, "storage-check", true, "If set, check at startup that the credential paths in the secure store can be read and written (only read in dry-run mode)")
+	flag.BoolVar(&dryRun, "dry-run", false, "If set, record the changes REDS would make to HSM and Vault in a plan (GET /v1/plan) instead of making them")
 	flag.Parse()
 
 	serviceName, err = base.GetServiceInstanceName()
@@ -91,6 +266,21 @@ func main() {
 	log.Printf("Configuration: instance name: %s", serviceName)
 	log.Printf("Configuration: http-listen: %s", httpListen)
 	log.Printf("Configuration: hsm: %s", hsm)
+	log.Printf("Configuration: insecure: %t, ca-uri: %s", insecure, caURI)
+	log.Printf("Configuration: lease-backend: %s", leaseBackend)
+	log.Printf("Configuration: dry-run: %t", dryRun)
+
+	storeConfig, err := securestore.ConfigFromEnvironment()
+	if err == nil {
+		err = securestore.SetConfig(storeConfig)
+	}
+	if err != nil {
+		log.Fatalf("Invalid secure store configuration: %s", err)
+	}
+	log.Printf("Configuration: secure store: %s", storeConfig)
+	if storageCheck {
+		checkSecureStore()
+	}
 	log.Print("Started reds")
 
 	//Init the secure TLS stuff
@@ -102,26 +292,127 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	err = smdclient.ConfigureTLS(insecure, caURI)
+	if err != nil {
+		log.Fatalf("Unable to set up TLS for HSM: %s", err)
+	}
+	smdclient.SetOverwriteProtected(hsmOverwriteProtected)
 
-	mapping.ConfigureSLSMode(sls, nil, nil, nil, serviceName)
+	// In dry-run mode every user of Vault goes through the planning storage
+	var storage sstorage.SecureStorage
+	if dryRun {
+		log.Printf("INFO: Dry-run mode: changes will be recorded in the plan, not made")
+		planRecorder = plan.NewRecorder(plan.DefaultMaxEntries)
+		smdclient.SetClient(smdclient.NewPlanningClient(smdclient.GetClient(), planRecorder))
 
-	switchQuitChan := make(chan bool)
-	go mapping.WatchSLSNewSwitches(switchQuitChan)
+		ss, err := securestore.Connect()
+		if err != nil {
+			log.Fatalf("Unable to connect to secure storage: %s", err)
+		}
+		storage = plan.NewSecureStorage(ss, planRecorder)
+		mapping.ConfigureSLSMode(sls, nil, &storage, nil, serviceName)
+	} else {
+		mapping.ConfigureSLSMode(sls, nil, nil, nil, serviceName)
+	}
 
-	nodeQuitChan := make(chan bool)
-	go mapping.WatchSLSNewManagementNodes(nodeQuitChan)
+	// Load up the stored mapping file (if any) and send to SNMP.  Done before
+	// anything takes the credential store, so they all share it.
+	mapping.SetStorage(storage)
+
+	if onboardingPolicyFile != "" {
+		policy, err := mapping.LoadOnboardingPolicy(onboardingPolicyFile)
+		if err == nil {
+			err = mapping.SetOnboardingPolicy(policy)
+		}
+		if err != nil {
+			log.Fatalf("Unable to load onboarding policy: %s", err)
+		}
+	}
+	log.Printf("Configuration: onboarding policy: %s", mapping.GetOnboardingPolicy())
+
+	vendorPolicy := mapping.DefaultVendorPolicy
+	if vendorPolicyFile != "" {
+		vendorPolicy, err = mapping.LoadVendorPolicy(vendorPolicyFile)
+		if err != nil {
+			log.Fatalf("Unable to load vendor policy: %s", err)
+		}
+	}
+	if vendorFallback != "" {
+		vendorPolicy.Fallback = nil
+		for _, vendor := range strings.Split(vendorFallback, ",") {
+			vendorPolicy.Fallback = append(vendorPolicy.Fallback, strings.TrimSpace(vendor))
+		}
+	}
+	if vendorProbe {
+		vendorPolicy.Probe = true
+	}
+	err = mapping.SetVendorPolicy(vendorPolicy)
+	if err != nil {
+		log.Fatalf("Invalid vendor policy: %s", err)
+	}
+
+	mapping.SetOnboardingConcurrency(onboardWorkers, time.Duration(onboardNodeTimeout)*time.Second)
+	mapping.SetHSMBatchSize(hsmBatchSize)
+	err = mapping.SetComponentDefaults(mapping.ComponentDefaults{
+		Arch:    defaultArch,
+		NetType: defaultNetType,
+		Class:   defaultClass,
+	})
+	if err != nil {
+		log.Fatalf("Invalid component defaults: %s", err)
+	}
+	passwordRules := passwords.RuleSet{}
+	if passwordRulesFile != "" {
+		passwordRules, err = passwords.LoadRuleSet(passwordRulesFile)
+		if err != nil {
+			log.Fatalf("Unable to load password rules: %s", err)
+		}
+	}
+	var bmcs rotation.BMC = passwords.NewRedfishApplier(30 * time.Second)
+	if planRecorder != nil {
+		bmcs = plan.NewApplier(planRecorder)
+	}
+	err = mapping.SetPasswordGeneration(generatePasswords, passwordRules, bmcs)
+	if err != nil {
+		log.Fatalf("Invalid password generation settings: %s", err)
+	}
+	err = mapping.SetCredentialVerification(verifyCredentials,
+		verify.NewRedfishVerifier(time.Duration(verifyTimeout)*time.Second),
+		verify.NewSNMPVerifier(time.Duration(verifyTimeout)*time.Second),
+		time.Duration(verifyTimeout)*time.Second)
+	if err != nil {
+		log.Fatalf("Invalid credential verification settings: %s", err)
+	}
+	rotations = rotation.NewManager(mapping.GetCompCredStore(), bmcs, passwordRules, mapping.BMCTarget,
+		rotationWorkers, time.Duration(rotationTimeout)*time.Second)
+	rotations.SetOutcomeStore(mapping.GetRedsCredStore())
+
+	mapping.SetDiscoveryRetry(discoveryRetries, time.Duration(discoveryTimeout)*time.Second, rediscoverRefreshCreds)
+
+	electorQuitChan := make(chan bool)
+	if store := newLeaseStore(); store != nil {
+		elector = leader.NewElector(store, serviceName,
+			time.Duration(leaseTTL)*time.Second, time.Duration(leaseRenew)*time.Second)
+		elector.OnStartedLeading(startWatchers)
+		elector.OnStoppedLeading(stopWatchers)
+		rotations.SetLeaderCheck(elector.IsLeader)
+		go elector.Run(electorQuitChan)
+	} else {
+		startWatchers()
+	}
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 	go func() {
 		<-c
 
-		switchQuitChan <- true
-		nodeQuitChan <- true
+		if elector != nil {
+			// Resigning stops the watchers and lets another replica take over.
+			electorQuitChan <- true
+		} else {
+			stopWatchers()
+		}
 	}()
 
-	// Load up the stored mapping file (if any) and send to SNMP
-	mapping.SetStorage(nil)
-
 	run_HTTPsrv()
 }
@@ -23,74 +23,127 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/loader"
 	"github.com/Cray-HPE/hms-reds/internal/model"
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
 	securestorage "github.com/Cray-HPE/hms-securestorage"
 )
 
+// Set the default mode and inputs, so the Kubernetes job can choose them
+const (
+	ENV_MODE  = "VAULT_LOADER_MODE"
+	ENV_INPUT = "VAULT_LOADER_INPUT"
+)
+
 func main() {
 	var secureStorage securestorage.SecureStorage
 	var credStorage *model.RedsCredStore
+	var compStorage *compcredentials.CompCredStore
+	var mode string
+	var inputs string
+
+	defaultMode := loader.MODE_APPLY
+	if v, ok := os.LookupEnv(ENV_MODE); ok && v != "" {
+		defaultMode = v
+	}
+	flag.StringVar(&mode, "mode", defaultMode, "plan (only report the changes), apply (store what differs from Vault) or no-clobber (only store what Vault has nothing for); default from "+ENV_MODE)
+	flag.StringVar(&inputs, "input", os.Getenv(ENV_INPUT), "Comma separated JSON, YAML or CSV files, or directories of them such as mounted secrets, named after the kind of document they hold, e.g. bmc-defaults.yaml; default from "+ENV_INPUT)
+	flag.Parse()
+
+	if err := loader.CheckMode(mode); err != nil {
+		fmt.Printf("%s\n", err)
+		os.Exit(1)
+	}
 
-	defaultNodeCredentials, ok := os.LookupEnv("VAULT_REDFISH_BMC_DEFAULTS")
-	if !ok {
-		panic("Value not set for VAULT_REDFISH_BMC_DEFAULTS")
+	// Nothing is stored unless all of the input is valid
+	docs := loader.FromEnvironment()
+	for _, input := range strings.Split(inputs, ",") {
+		if input = strings.TrimSpace(input); input == "" {
+			continue
+		}
+		inputDocs, err := loader.ReadInput(input)
+		if err != nil {
+			fmt.Printf("Unable to read %s: %s\n", input, err)
+			os.Exit(1)
+		}
+		docs = append(docs, inputDocs...)
+	}
+	var err error
+	if strings.TrimSpace(inputs) == "" {
+		// Without inputs, the defaults must be in the environment
+		err = loader.Require(docs, loader.KIND_BMC_DEFAULTS, loader.KIND_SWITCH_DEFAULTS)
+	} else if len(docs) == 0 {
+		err = fmt.Errorf("nothing to load in %s", inputs)
+	}
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		os.Exit(1)
+	}
+	desired, err := loader.Parse(docs)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		os.Exit(1)
 	}
 
-	defaultSwitchCredentials, ok := os.LookupEnv("VAULT_REDFISH_SWITCH_DEFAULTS")
-	if !ok {
-		panic("Value not set for VAULT_REDFISH_SWITCH_DEFAULTS")
+	// The same paths as REDS
+	storeConfig, err := securestore.ConfigFromEnvironment()
+	if err == nil {
+		err = securestore.SetConfig(storeConfig)
+	}
+	if err != nil {
+		fmt.Printf("Invalid secure store configuration: %s\n", err)
+		os.Exit(1)
 	}
+	fmt.Printf("Secure store: %s\n", storeConfig)
 
 	// Setup Vault. It's kind of a big deal, so we'll wait forever for this to work.
 	fmt.Println("Connecting to Vault...")
 	for {
-		var err error
 		// Start a connection to Vault
-		if secureStorage, err = securestorage.NewVaultAdapter(""); err != nil {
+		if secureStorage, err = securestore.Connect(); err != nil {
 			fmt.Printf("Unable to connect to Vault (%s)...trying again in 5 seconds.\n", err)
 			time.Sleep(5 * time.Second)
 		} else {
 			fmt.Println("Connected to Vault")
-			credStorage = model.NewRedsCredStore("secret/reds-creds", secureStorage)
+			credStorage = model.NewRedsCredStore(storeConfig.RedsCredsPath(), secureStorage)
+			compStorage = compcredentials.NewCompCredStore(storeConfig.HMSCredsPath(), secureStorage)
+			// Recorded in the history of the defaults
+			credStorage.Actor = "vault_loader"
+			if host, err := os.Hostname(); err == nil {
+				credStorage.Actor += " on " + host
+			}
 			break
 		}
 	}
 
-	// Node BMC defaults
-	var nodeCredentials map[string]model.RedsCredentials
-	err := json.Unmarshal([]byte(defaultNodeCredentials), &nodeCredentials)
-	if err != nil {
-		fmt.Printf("Unable to unmarshal defaults for node BMCs: %s", err)
+	// A plan only reads
+	paths := []string{storeConfig.RedsCredsPath()}
+	if desired.Components != nil {
+		paths = append(paths, storeConfig.HMSCredsPath())
 	}
-
-	// Uncomment the following lines if you want to debug what is getting put into Vault.
-	//prettyCredentials, _ := json.MarshalIndent(nodeCredentials, "\t", "   ")
-	//fmt.Printf("Loading:\n\t%s\n\n", prettyCredentials)
-
-	err = credStorage.StoreDefaultCredentials(nodeCredentials)
+	err = securestore.Check(secureStorage, paths, mode != loader.MODE_PLAN)
 	if err != nil {
-		fmt.Printf("Unable to store defaults for node BMCs: %s", err)
+		fmt.Printf("%s\n", err)
+		os.Exit(1)
 	}
 
-	// Switch defaults
-	var switchCredentials model.SwitchCredentials
-	err = json.Unmarshal([]byte(defaultSwitchCredentials), &switchCredentials)
+	plan, err := loader.MakePlan(credStorage, compStorage, desired, mode)
 	if err != nil {
-		fmt.Printf("Unable to unmarshal defaults for switches: %s", err)
+		fmt.Printf("%s\n", err)
+		os.Exit(1)
 	}
-
-	// Uncomment the following lines if you want to debug what is getting put into Vault.
-	//prettyCredentials, _ = json.MarshalIndent(switchCredentials, "\t", "   ")
-	//fmt.Printf("Loading:\n\t%s\n\n", prettyCredentials)
-
-	err = credStorage.StoreDefaultSwitchCredentials(switchCredentials)
+	err = plan.Apply(credStorage, compStorage)
+	plan.Write(os.Stdout)
 	if err != nil {
-		fmt.Printf("Unable to store defaults for switches: %s", err)
+		fmt.Printf("%s\n", err)
+		os.Exit(1)
 	}
 
 	fmt.Println("Done.")
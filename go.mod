@@ -8,7 +8,9 @@ require (
 	github.com/Cray-HPE/hms-compcredentials v1.11.2
 	github.com/Cray-HPE/hms-securestorage v1.12.2
 	github.com/gorilla/mux v1.8.0
+	github.com/gosnmp/gosnmp v1.32.0
 	github.com/mitchellh/mapstructure v1.3.3
 	golang.org/x/sys v0.0.0-20200923182605-d9f96fdee20d // indirect
 	gopkg.in/resty.v1 v1.12.0
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,230 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package leader implements lease-based leader election between REDS
+// replicas, so that only one of them polls SLS, seeds credentials and
+// registers endpoints with HSM.
+package leader
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Status is a snapshot of the election as seen by this instance.
+type Status struct {
+	Identity       string `json:"identity"`
+	Leader         string `json:"leader"`
+	IsLeader       bool   `json:"isLeader"`
+	LeaseExpiresAt string `json:"leaseExpiresAt,omitempty"`
+}
+
+// Elector runs the acquire/renew loop for one instance.
+type Elector struct {
+	store       LeaseStore
+	identity    string
+	ttl         time.Duration
+	renewPeriod time.Duration
+
+	onStarted func()
+	onStopped func()
+
+	lock     sync.Mutex
+	isLeader bool
+	lease    *Lease
+	// Local deadline for our own lease, so we step down even if the store
+	// becomes unreachable and we can't tell whether somebody else took over.
+	deadline time.Time
+
+	now func() time.Time
+}
+
+// NewElector creates an elector for identity.  The lease is valid for ttl and
+// is renewed (or, for followers, retried) every renewPeriod, which should be
+// well under ttl.
+func NewElector(store LeaseStore, identity string, ttl time.Duration, renewPeriod time.Duration) *Elector {
+	return &Elector{
+		store:       store,
+		identity:    identity,
+		ttl:         ttl,
+		renewPeriod: renewPeriod,
+		now:         time.Now,
+	}
+}
+
+// OnStartedLeading sets the function called when this instance becomes leader.
+func (e *Elector) OnStartedLeading(f func()) {
+	e.onStarted = f
+}
+
+// OnStoppedLeading sets the function called when this instance loses or
+// gives up leadership.
+func (e *Elector) OnStoppedLeading(f func()) {
+	e.onStopped = f
+}
+
+// IsLeader reports whether this instance currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.isLeader
+}
+
+// Leader returns the identity of the last known lease holder, or "" if none.
+func (e *Elector) Leader() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.lease == nil || e.lease.Expired(e.now()) {
+		return ""
+	}
+	return e.lease.Holder
+}
+
+// Status returns a snapshot of the election state.
+func (e *Elector) Status() Status {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	st := Status{
+		Identity: e.identity,
+		IsLeader: e.isLeader,
+	}
+	if e.lease != nil && !e.lease.Expired(e.now()) {
+		st.Leader = e.lease.Holder
+		st.LeaseExpiresAt = e.lease.ExpiresAt
+	}
+	return st
+}
+
+// Run takes part in the election until quitChan fires, at which point any
+// lease held is released.
+func (e *Elector) Run(quitChan chan bool) {
+	e.tick()
+
+	ticker := time.NewTicker(e.renewPeriod)
+	for {
+		select {
+		case <-quitChan:
+			log.Printf("Info: Leader election shutting down")
+			ticker.Stop()
+			e.resign()
+			return
+		case <-ticker.C:
+			e.tick()
+		}
+	}
+}
+
+// tick makes one attempt to acquire or renew the lease.
+func (e *Elector) tick() {
+	now := e.now()
+
+	cur, err := e.store.Get()
+	if err != nil {
+		log.Printf("WARNING: Unable to read leader lease: %s", err)
+		e.checkDeadline(now)
+		return
+	}
+
+	e.lock.Lock()
+	e.lease = cur
+	e.lock.Unlock()
+
+	if cur != nil && cur.Holder != e.identity && !cur.Expired(now) {
+		// Somebody else holds a valid lease.
+		e.setLeader(false)
+		return
+	}
+
+	next := Lease{
+		Holder:     e.identity,
+		AcquiredAt: now.Format(time.RFC3339Nano),
+		RenewedAt:  now.Format(time.RFC3339Nano),
+		ExpiresAt:  now.Add(e.ttl).Format(time.RFC3339Nano),
+	}
+	if cur != nil && cur.Holder == e.identity {
+		next.AcquiredAt = cur.AcquiredAt
+	}
+
+	ok, err := e.store.CompareAndPut(cur, next)
+	if err != nil {
+		log.Printf("WARNING: Unable to write leader lease: %s", err)
+		e.checkDeadline(now)
+		return
+	}
+	if !ok {
+		log.Printf("INFO: Lost race for leader lease")
+		e.setLeader(false)
+		return
+	}
+
+	e.lock.Lock()
+	e.lease = &next
+	e.deadline = now.Add(e.ttl)
+	e.lock.Unlock()
+	e.setLeader(true)
+}
+
+// checkDeadline steps down if our own lease ran out while we couldn't talk
+// to the store.
+func (e *Elector) checkDeadline(now time.Time) {
+	e.lock.Lock()
+	expired := e.isLeader && !now.Before(e.deadline)
+	e.lock.Unlock()
+	if expired {
+		log.Printf("WARNING: Leader lease expired without renewal")
+		e.setLeader(false)
+	}
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.lock.Lock()
+	was := e.isLeader
+	e.isLeader = leader
+	e.lock.Unlock()
+
+	if leader && !was {
+		log.Printf("INFO: %s became leader", e.identity)
+		if e.onStarted != nil {
+			e.onStarted()
+		}
+	} else if !leader && was {
+		log.Printf("INFO: %s is no longer leader", e.identity)
+		if e.onStopped != nil {
+			e.onStopped()
+		}
+	}
+}
+
+func (e *Elector) resign() {
+	if !e.IsLeader() {
+		return
+	}
+	e.setLeader(false)
+	err := e.store.Release(e.identity)
+	if err != nil {
+		log.Printf("WARNING: Unable to release leader lease: %s", err)
+	}
+	e.lock.Lock()
+	e.lease = nil
+	e.lock.Unlock()
+}
@@ -0,0 +1,162 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package leader
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type memSS struct {
+	kvstore map[string]string
+}
+
+func (ms memSS) Store(key string, value interface{}) error {
+	jsonVal, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	ms.kvstore[key] = string(jsonVal)
+	return nil
+}
+func (ms memSS) Lookup(key string, output interface{}) error {
+	jVal, ok := ms.kvstore[key]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal([]byte(jVal), output)
+}
+func (ms memSS) Delete(key string) error {
+	delete(ms.kvstore, key)
+	return nil
+}
+func (ms memSS) LookupKeys(keyPath string) ([]string, error) { return nil, nil }
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestElectors(store LeaseStore, clock *fakeClock, ids ...string) []*Elector {
+	var electors []*Elector
+	for _, id := range ids {
+		e := NewElector(store, id, 30*time.Second, 10*time.Second)
+		e.now = clock.now
+		electors = append(electors, e)
+	}
+	return electors
+}
+
+func testElection(t *testing.T, store LeaseStore) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	electors := newTestElectors(store, clock, "reds-a", "reds-b")
+	a, b := electors[0], electors[1]
+
+	started, stopped := 0, 0
+	a.OnStartedLeading(func() { started++ })
+	a.OnStoppedLeading(func() { stopped++ })
+
+	a.tick()
+	b.tick()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("Expected reds-a to lead, got a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	if b.Leader() != "reds-a" {
+		t.Fatalf("Follower reports leader %q, expected reds-a", b.Leader())
+	}
+
+	// Renewals keep the lease with the leader.
+	for i := 0; i < 5; i++ {
+		clock.t = clock.t.Add(10 * time.Second)
+		a.tick()
+		b.tick()
+	}
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("Leadership moved during renewals: a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	if started != 1 {
+		t.Fatalf("OnStartedLeading called %d times, expected 1", started)
+	}
+
+	// If the leader stops renewing, the follower takes over after the TTL.
+	clock.t = clock.t.Add(31 * time.Second)
+	b.tick()
+	if !b.IsLeader() {
+		t.Fatalf("Follower did not take over expired lease")
+	}
+	a.tick()
+	if a.IsLeader() {
+		t.Fatalf("Old leader did not step down")
+	}
+	if stopped != 1 {
+		t.Fatalf("OnStoppedLeading called %d times, expected 1", stopped)
+	}
+	st := a.Status()
+	if st.Leader != "reds-b" || st.IsLeader || st.Identity != "reds-a" {
+		t.Fatalf("Unexpected status %+v", st)
+	}
+
+	// Resigning hands the lease over right away.
+	b.resign()
+	a.tick()
+	if !a.IsLeader() {
+		t.Fatalf("reds-a did not take over released lease")
+	}
+}
+
+func TestElection_FileLeaseStore(t *testing.T) {
+	testElection(t, NewFileLeaseStore(filepath.Join(t.TempDir(), "lease.json")))
+}
+
+func TestElection_SecureStoreLeaseStore(t *testing.T) {
+	testElection(t, NewSecureStoreLeaseStore("reds-leader/lease", memSS{kvstore: map[string]string{}}))
+}
+
+func TestFileLeaseStore_CompareAndPut(t *testing.T) {
+	store := NewFileLeaseStore(filepath.Join(t.TempDir(), "lease.json"))
+	first := Lease{Holder: "reds-a", ExpiresAt: "2026-01-01T00:00:30Z"}
+
+	ok, err := store.CompareAndPut(nil, first)
+	if err != nil || !ok {
+		t.Fatalf("Initial put failed: ok=%v err=%v", ok, err)
+	}
+	ok, err = store.CompareAndPut(nil, Lease{Holder: "reds-b"})
+	if err != nil || ok {
+		t.Fatalf("Put against stale lease should fail: ok=%v err=%v", ok, err)
+	}
+	if err = store.Release("reds-b"); err != nil {
+		t.Fatalf("Release by non-holder returned %s", err)
+	}
+	if cur, _ := store.Get(); cur == nil || cur.Holder != "reds-a" {
+		t.Fatalf("Lease released by non-holder: %+v", cur)
+	}
+	if err = store.Release("reds-a"); err != nil {
+		t.Fatalf("Release failed: %s", err)
+	}
+	if cur, _ := store.Get(); cur != nil {
+		t.Fatalf("Lease still present after release: %+v", cur)
+	}
+}
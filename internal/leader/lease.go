@@ -0,0 +1,241 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package leader
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	sstorage "github.com/Cray-HPE/hms-securestorage"
+)
+
+// Lease records which REDS instance currently holds leadership and until
+// when.  Times are kept as RFC3339 strings so they survive a round trip
+// through the secure store unchanged.
+type Lease struct {
+	Holder     string `json:"holder"`
+	AcquiredAt string `json:"acquiredAt"`
+	RenewedAt  string `json:"renewedAt"`
+	ExpiresAt  string `json:"expiresAt"`
+}
+
+// Expired reports whether the lease is no longer valid at the given time.
+// A lease with an unparseable expiry is treated as expired.
+func (l Lease) Expired(now time.Time) bool {
+	expires, err := time.Parse(time.RFC3339Nano, l.ExpiresAt)
+	if err != nil {
+		return true
+	}
+	return !now.Before(expires)
+}
+
+// LeaseStore is the pluggable backend the leader election keeps its lease in.
+type LeaseStore interface {
+	// Get returns the current lease, or nil if no lease has been written.
+	Get() (*Lease, error)
+	// CompareAndPut writes next only if the stored lease still matches
+	// prev (nil meaning no lease).  It returns false if somebody else
+	// changed the lease in the meantime.
+	CompareAndPut(prev *Lease, next Lease) (bool, error)
+	// Release removes the lease if it is held by holder.
+	Release(holder string) error
+}
+
+func sameLease(a, b *Lease) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// Secure store (Vault) backend
+/////////////////////////////////////////////////////////////////////////////
+
+// SecureStoreLeaseStore keeps the lease in the secure store.  Vault's KV
+// engine has no compare-and-swap, so the write is followed by a read back to
+// confirm we won; the window for two writers racing is much shorter than the
+// lease TTL, and the loser notices on its next renewal.
+type SecureStoreLeaseStore struct {
+	Key string
+	SS  sstorage.SecureStorage
+}
+
+// NewSecureStoreLeaseStore creates a lease store under key in ss.
+func NewSecureStoreLeaseStore(key string, ss sstorage.SecureStorage) *SecureStoreLeaseStore {
+	return &SecureStoreLeaseStore{
+		Key: key,
+		SS:  ss,
+	}
+}
+
+func (s *SecureStoreLeaseStore) Get() (*Lease, error) {
+	var lease Lease
+	err := s.SS.Lookup(s.Key, &lease)
+	if err != nil {
+		return nil, err
+	}
+	if lease.Holder == "" {
+		return nil, nil
+	}
+	return &lease, nil
+}
+
+func (s *SecureStoreLeaseStore) CompareAndPut(prev *Lease, next Lease) (bool, error) {
+	cur, err := s.Get()
+	if err != nil {
+		return false, err
+	}
+	if !sameLease(cur, prev) {
+		return false, nil
+	}
+	err = s.SS.Store(s.Key, next)
+	if err != nil {
+		return false, err
+	}
+	cur, err = s.Get()
+	if err != nil {
+		return false, err
+	}
+	return sameLease(cur, &next), nil
+}
+
+func (s *SecureStoreLeaseStore) Release(holder string) error {
+	cur, err := s.Get()
+	if err != nil {
+		return err
+	}
+	if cur == nil || cur.Holder != holder {
+		return nil
+	}
+	return s.SS.Delete(s.Key)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// Local file backend
+/////////////////////////////////////////////////////////////////////////////
+
+// FileLeaseStore keeps the lease in a JSON file, serializing updates with an
+// flock on a sibling ".lock" file.  Only useful when every replica shares a
+// filesystem, i.e. tests and single-host setups.
+type FileLeaseStore struct {
+	Path string
+}
+
+// NewFileLeaseStore creates a lease store backed by the file at path.
+func NewFileLeaseStore(path string) *FileLeaseStore {
+	return &FileLeaseStore{Path: path}
+}
+
+func (s *FileLeaseStore) withLock(f func() error) error {
+	err := os.MkdirAll(filepath.Dir(s.Path), 0755)
+	if err != nil {
+		return err
+	}
+	lf, err := os.OpenFile(s.Path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+
+	err = syscall.Flock(int(lf.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		return err
+	}
+	defer syscall.Flock(int(lf.Fd()), syscall.LOCK_UN)
+
+	return f()
+}
+
+func (s *FileLeaseStore) read() (*Lease, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var lease Lease
+	err = json.Unmarshal(data, &lease)
+	if err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
+
+func (s *FileLeaseStore) Get() (lease *Lease, err error) {
+	err = s.withLock(func() error {
+		lease, err = s.read()
+		return err
+	})
+	return
+}
+
+func (s *FileLeaseStore) CompareAndPut(prev *Lease, next Lease) (ok bool, err error) {
+	err = s.withLock(func() error {
+		cur, rerr := s.read()
+		if rerr != nil {
+			return rerr
+		}
+		if !sameLease(cur, prev) {
+			return nil
+		}
+		data, merr := json.Marshal(next)
+		if merr != nil {
+			return merr
+		}
+		tmp := s.Path + ".tmp"
+		if werr := ioutil.WriteFile(tmp, data, 0644); werr != nil {
+			return werr
+		}
+		if rerr := os.Rename(tmp, s.Path); rerr != nil {
+			return rerr
+		}
+		ok = true
+		return nil
+	})
+	return
+}
+
+func (s *FileLeaseStore) Release(holder string) error {
+	return s.withLock(func() error {
+		cur, err := s.read()
+		if err != nil {
+			return err
+		}
+		if cur == nil || cur.Holder != holder {
+			return nil
+		}
+		err = os.Remove(s.Path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	})
+}
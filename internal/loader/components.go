@@ -0,0 +1,131 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package loader
+
+import (
+	"fmt"
+
+	base "github.com/Cray-HPE/hms-base"
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/model"
+)
+
+// ComponentCredentials are the credentials of one BMC or switch in the
+// manifest.  BMCs have a username and password; switches an SNMP username
+// and SNMP passwords.
+type ComponentCredentials struct {
+	Xname            string `json:"xname"`
+	Username         string `json:"username"`
+	Password         string `json:"password,omitempty"`
+	SNMPAuthPassword string `json:"snmpAuthPassword,omitempty"`
+	SNMPPrivPassword string `json:"snmpPrivPassword,omitempty"`
+}
+
+// isSwitch reports whether the credentials are for a switch rather than a
+// BMC.
+func (c ComponentCredentials) isSwitch() bool {
+	switch base.GetHMSType(c.Xname) {
+	case base.MgmtSwitch, base.MgmtHLSwitch, base.CDUMgmtSwitch:
+		return true
+	}
+	return false
+}
+
+// validateComponents normalizes the xnames and checks each entry is for a
+// BMC or switch, once, with the credentials it takes.
+func validateComponents(components []ComponentCredentials) []string {
+	var problems []string
+	seen := make(map[string]bool)
+	for i := range components {
+		c := &components[i]
+		c.Xname = base.NormalizeHMSCompID(c.Xname)
+		problem := func(format string, a ...interface{}) {
+			problems = append(problems, fmt.Sprintf("entry %d (%s): ", i+1, c.Xname)+fmt.Sprintf(format, a...))
+		}
+
+		hmsType := base.GetHMSType(c.Xname)
+		switch {
+		case c.Xname == "":
+			problem("has no xname")
+			continue
+		case hmsType == base.HMSTypeInvalid:
+			problem("invalid xname")
+			continue
+		case seen[c.Xname]:
+			problem("is given more than once")
+		}
+		seen[c.Xname] = true
+
+		if c.Username == "" {
+			problem("has no username")
+		}
+		switch {
+		case c.isSwitch():
+			if c.Password != "" {
+				problem("switches take an snmpAuthPassword and snmpPrivPassword, not a password")
+			}
+			profile := model.SwitchCredentials{SNMPUsername: c.Username, SNMPAuthPassword: c.SNMPAuthPassword,
+				SNMPPrivPassword: c.SNMPPrivPassword}
+			if err := profile.Validate(); err != nil {
+				problem("%s", err)
+			}
+		case base.IsHMSTypeController(hmsType):
+			if c.Password == "" {
+				problem("has no password")
+			}
+			if c.SNMPAuthPassword != "" || c.SNMPPrivPassword != "" {
+				problem("only switches take SNMP passwords")
+			}
+		default:
+			problem("is a %s, not a BMC or switch", hmsType)
+		}
+	}
+	return problems
+}
+
+// diffComponent lists the changes the manifest makes to what Vault has for
+// a component.  Only the credentials the component takes are compared.
+func diffComponent(current compcredentials.CompCredentials, c ComponentCredentials) []model.CredentialChange {
+	changes := []model.CredentialChange{}
+	changes = model.DiffField(changes, c.Xname, "username", current.Username, c.Username, false)
+	if c.isSwitch() {
+		changes = model.DiffField(changes, c.Xname, "snmpAuthPassword", current.SNMPAuthPass, c.SNMPAuthPassword, true)
+		changes = model.DiffField(changes, c.Xname, "snmpPrivPassword", current.SNMPPrivPass, c.SNMPPrivPassword, true)
+	} else {
+		changes = model.DiffField(changes, c.Xname, "password", current.Password, c.Password, true)
+	}
+	return changes
+}
+
+// apply returns the credentials to store for the component: what Vault has,
+// with the ones from the manifest.
+func (c ComponentCredentials) apply(current compcredentials.CompCredentials) compcredentials.CompCredentials {
+	current.Xname = c.Xname
+	current.Username = c.Username
+	if c.isSwitch() {
+		current.SNMPAuthPass = c.SNMPAuthPassword
+		current.SNMPPrivPass = c.SNMPPrivPassword
+	} else {
+		current.Password = c.Password
+	}
+	return current
+}
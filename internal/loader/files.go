@@ -0,0 +1,225 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package loader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// formatOf works out the format of a file from its extension.  Files with
+// none, like the keys of mounted Kubernetes secrets, are read as YAML, which
+// takes JSON too.
+func formatOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FORMAT_JSON
+	case ".csv":
+		return FORMAT_CSV
+	default:
+		return FORMAT_YAML
+	}
+}
+
+// kindOf returns the kind of document a file holds, from its name without
+// the extension, e.g. bmc-defaults.yaml.
+func kindOf(name string) string {
+	name = filepath.Base(name)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml", ".csv":
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name
+}
+
+// ReadInput reads the documents in a file, or in each file of a directory.
+// Files are named after the kind of document they hold, e.g.
+// bmc-defaults.json or policies.yaml.  Hidden files and subdirectories of a
+// directory are skipped, so a mounted Kubernetes secret can be read as is.
+func ReadInput(path string) ([]Document, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		doc, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return []Document{doc}, nil
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var docs []Document
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name := filepath.Join(path, entry.Name())
+		// Secret keys are symlinks into a hidden directory
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		doc, err := readFile(name)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func readFile(name string) (Document, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return Document{}, err
+	}
+	return Document{Kind: kindOf(name), Source: name, Format: formatOf(name), Data: data}, nil
+}
+
+// toJSON converts a document to JSON.
+func toJSON(doc Document) ([]byte, error) {
+	switch doc.Format {
+	case FORMAT_JSON, "":
+		return doc.Data, nil
+	case FORMAT_YAML:
+		return yamlToJSON(doc.Data)
+	case FORMAT_CSV:
+		if doc.Kind != KIND_COMPONENT_CREDENTIALS {
+			return nil, fmt.Errorf("only %s can be CSV", KIND_COMPONENT_CREDENTIALS)
+		}
+		return csvToJSON(doc.Data)
+	}
+	return nil, fmt.Errorf("unknown format %q", doc.Format)
+}
+
+// yamlToJSON converts a YAML document to JSON.  Keys repeated in a mapping
+// are an error.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.UnmarshalStrict(data, &value); err != nil {
+		return nil, err
+	}
+	value, err := jsonValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// jsonValue turns the mappings YAML decodes into maps JSON can encode.
+func jsonValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			s, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("key %v isn't a string", key)
+			}
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			m[s] = converted
+		}
+		return m, nil
+	case []interface{}:
+		for i, item := range v {
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// The CSV columns whose cells aren't trimmed, as spaces may be part of a
+// password
+var csvPasswordColumns = map[string]bool{
+	"password":         true,
+	"snmpAuthPassword": true,
+	"snmpPrivPassword": true,
+}
+
+// csvToJSON converts CSV with a header row to a JSON array with an object
+// for each row, keyed by the header.  Empty cells are left out, and lines
+// starting with # are comments.  Spaces before a cell are skipped, so a
+// quoted cell may follow a comma and a space; a password starting with a
+// space has to be quoted.  Other than that passwords are kept as given, and
+// all other cells are trimmed.
+func csvToJSON(data []byte) ([]byte, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("no header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	rows := []map[string]string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string)
+		for i, value := range record {
+			if !csvPasswordColumns[header[i]] {
+				value = strings.TrimSpace(value)
+			}
+			if value != "" {
+				row[header[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return json.Marshal(rows)
+}
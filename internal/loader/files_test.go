@@ -0,0 +1,130 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSecret lays out files the way Kubernetes mounts a secret: in a hidden
+// directory, linked to through ..data.
+func writeSecret(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..2026_10_18_00_00_00.000000001")
+	if err := os.Mkdir(data, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Base(data), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(data, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadInput(t *testing.T) {
+	dir := writeSecret(t, map[string]string{
+		"bmc-defaults":         "Cray:\n  username: root\n  password: initial0\n",
+		"switch-defaults.json": switchDefaults,
+		"policies.yml": "- name: masters\n  role: Management\n  subRole: Master\n" +
+			"  credentials: {username: root, password: master}\n",
+		"component-credentials.csv": "# BMCs\nxname, username, password\n" +
+			" x3000c0s1b0 , root ,\" bmc \"\nx3000c0s2b0, root, \"pass,word\"\n",
+	})
+	docs, err := ReadInput(dir)
+	if err != nil {
+		t.Fatalf("ReadInput() error = %v", err)
+	}
+	formats := map[string]string{KIND_BMC_DEFAULTS: FORMAT_YAML, KIND_SWITCH_DEFAULTS: FORMAT_JSON,
+		KIND_POLICIES: FORMAT_YAML, KIND_COMPONENT_CREDENTIALS: FORMAT_CSV}
+	if len(docs) != len(formats) {
+		t.Errorf("ReadInput() = %d documents, want %d", len(docs), len(formats))
+	}
+	for _, doc := range docs {
+		if formats[doc.Kind] != doc.Format || !strings.HasPrefix(doc.Source, dir) {
+			t.Errorf("ReadInput() document %s from %s as %s", doc.Kind, doc.Source, doc.Format)
+		}
+	}
+
+	desired, err := Parse(docs)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if desired.BMCDefaults["Cray"].Password != "initial0" || desired.SwitchDefaults.SNMPUsername != "testuser" ||
+		(*desired.Policies)[0].Credentials.Password != "master" || len(desired.Components) != 2 ||
+		desired.Components[0].Xname != "x3000c0s1b0" || desired.Components[0].Username != "root" ||
+		desired.Components[0].Password != " bmc " || desired.Components[1].Password != "pass,word" {
+		t.Errorf("Parse() = %+v", desired)
+	}
+
+	docs, err = ReadInput(filepath.Join(dir, "bmc-defaults"))
+	if err != nil || len(docs) != 1 || docs[0].Kind != KIND_BMC_DEFAULTS {
+		t.Errorf("ReadInput() of a file = %v, %v", docs, err)
+	}
+	if _, err := ReadInput(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("ReadInput() of a missing file should fail")
+	}
+}
+
+func TestParse_formats(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     Document
+		problem string
+	}{
+		{"repeated key", Document{Kind: KIND_BMC_DEFAULTS, Format: FORMAT_YAML,
+			Data: []byte("Cray: {username: root, password: a}\nCray: {username: root, password: b}\n")},
+			"already set"},
+		{"number password", Document{Kind: KIND_BMC_DEFAULTS, Format: FORMAT_YAML,
+			Data: []byte("Cray: {username: root, password: 12345}\n")}, "cannot unmarshal number"},
+		{"non-string key", Document{Kind: KIND_BMC_DEFAULTS, Format: FORMAT_YAML,
+			Data: []byte("1: {username: root, password: a}\n")}, "key 1 isn't a string"},
+		{"empty YAML", Document{Kind: KIND_SWITCH_DEFAULTS, Format: FORMAT_YAML}, "is null"},
+		{"CSV defaults", Document{Kind: KIND_BMC_DEFAULTS, Format: FORMAT_CSV, Data: []byte("a,b\n")},
+			"only component-credentials can be CSV"},
+		{"CSV unknown column", Document{Kind: KIND_COMPONENT_CREDENTIALS, Format: FORMAT_CSV,
+			Data: []byte("xname,username,pasword\nx3000c0s1b0,root,a\n")}, `unknown field "pasword"`},
+		{"CSV short row", Document{Kind: KIND_COMPONENT_CREDENTIALS, Format: FORMAT_CSV,
+			Data: []byte("xname,username,password\nx3000c0s1b0,root\n")}, "wrong number of fields"},
+		{"CSV no header", Document{Kind: KIND_COMPONENT_CREDENTIALS, Format: FORMAT_CSV}, "no header row"},
+		{"unknown kind", Document{Kind: "bmc-default", Format: FORMAT_YAML, Data: []byte("{}")},
+			"unknown kind of document"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doc.Source = "test"
+			if _, err := Parse([]Document{tt.doc}); err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("Parse() error = %v, want it to mention %q", err, tt.problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// Package loader loads the default credentials and credential policies
+// REDS seeds Vault from, and the credentials of particular BMCs and
+// switches.  Its input is declarative: each document is
+// checked against its schema, compared with what Vault has, and stored only
+// if it differs.
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-reds/internal/model"
+)
+
+// Environment variables the documents are read from
+const (
+	ENV_BMC_DEFAULTS    = "VAULT_REDFISH_BMC_DEFAULTS"
+	ENV_SWITCH_DEFAULTS = "VAULT_REDFISH_SWITCH_DEFAULTS"
+	ENV_POLICIES        = "VAULT_REDS_CREDENTIAL_POLICIES"
+	// The per-xname credential manifest
+	ENV_COMPONENT_CREDENTIALS = "VAULT_REDS_COMPONENT_CREDENTIALS"
+)
+
+// Kinds of document
+const (
+	KIND_BMC_DEFAULTS    = "bmc-defaults"
+	KIND_SWITCH_DEFAULTS = "switch-defaults"
+	KIND_POLICIES        = "policies"
+	// Credentials of particular BMCs and switches, by xname
+	KIND_COMPONENT_CREDENTIALS = "component-credentials"
+)
+
+// Formats of document
+const (
+	FORMAT_JSON = "json"
+	// Also takes JSON
+	FORMAT_YAML = "yaml"
+	// Only for component credentials
+	FORMAT_CSV = "csv"
+)
+
+// Document is one kind of input, where it came from and its format.
+type Document struct {
+	Kind   string
+	Source string
+	Format string
+	Data   []byte
+}
+
+// Desired is what the documents say Vault should have.  Kinds that weren't
+// given are nil and left alone.
+type Desired struct {
+	BMCDefaults    map[string]model.RedsCredentials
+	SwitchDefaults *model.SwitchCredentials
+	Policies       *[]model.CredentialPolicy
+	Components     []ComponentCredentials
+	// Where each kind came from
+	Sources map[string]string
+}
+
+// ValidationError lists every problem found in the documents.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid input:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// FromEnvironment reads the JSON documents set in the environment.
+func FromEnvironment() []Document {
+	var docs []Document
+	for _, env := range []struct {
+		name string
+		kind string
+	}{
+		{ENV_BMC_DEFAULTS, KIND_BMC_DEFAULTS},
+		{ENV_SWITCH_DEFAULTS, KIND_SWITCH_DEFAULTS},
+		{ENV_POLICIES, KIND_POLICIES},
+		{ENV_COMPONENT_CREDENTIALS, KIND_COMPONENT_CREDENTIALS},
+	} {
+		if value, ok := os.LookupEnv(env.name); ok {
+			docs = append(docs, Document{Kind: env.kind, Source: env.name, Format: FORMAT_JSON,
+				Data: []byte(value)})
+		}
+	}
+	return docs
+}
+
+// Require checks there is a document of each of kinds.
+func Require(docs []Document, kinds ...string) error {
+	var missing []string
+	for _, kind := range kinds {
+		found := false
+		for _, doc := range docs {
+			found = found || doc.Kind == kind
+		}
+		if !found {
+			missing = append(missing, "no "+kind+" given")
+		}
+	}
+	if len(missing) > 0 {
+		return &ValidationError{Problems: missing}
+	}
+	return nil
+}
+
+// decodeStrict decodes data into v, failing on unknown fields, values of
+// the wrong type and anything after the JSON value.
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		if err == io.EOF {
+			return errors.New("no JSON value")
+		}
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+// Parse checks each document against the schema of its kind and returns
+// what they describe.  The schema of each kind is its model type, strictly:
+// unknown fields, wrong types and null are rejected.  On top of that
+//
+//	bmc-defaults:    an object of at least one vendor, each with a username
+//	                 and password
+//	switch-defaults: an SNMPUsername, and a valid SNMPv3 profile (see
+//	                 model.SwitchCredentials.Validate)
+//	policies:        an array of valid policies with distinct names (see
+//	                 model.CredentialPolicy.Validate)
+//	component-credentials: an array of ComponentCredentials, see
+//	                 validateComponents
+//
+// YAML and CSV documents are converted to JSON first.  All problems are returned together in a *ValidationError.
+func Parse(docs []Document) (Desired, error) {
+	desired := Desired{Sources: make(map[string]string)}
+	var problems []string
+	problem := func(doc Document, format string, a ...interface{}) {
+		problems = append(problems, doc.Kind+" ("+doc.Source+"): "+fmt.Sprintf(format, a...))
+	}
+	seen := make(map[string]Document)
+
+	for _, doc := range docs {
+		if prior, ok := seen[doc.Kind]; ok {
+			problem(doc, "also given by %s", prior.Source)
+			continue
+		}
+		seen[doc.Kind] = doc
+		desired.Sources[doc.Kind] = doc.Source
+		data, err := toJSON(doc)
+		if err != nil {
+			problem(doc, "%s", err)
+			continue
+		}
+		doc.Data = data
+		if bytes.Equal(bytes.TrimSpace(doc.Data), []byte("null")) {
+			problem(doc, "is null")
+			continue
+		}
+
+		switch doc.Kind {
+		case KIND_BMC_DEFAULTS:
+			var defaults map[string]model.RedsCredentials
+			if err := decodeStrict(doc.Data, &defaults); err != nil {
+				problem(doc, "%s", err)
+				continue
+			}
+			if len(defaults) == 0 {
+				problem(doc, "has no vendors")
+			}
+			for _, vendor := range sortedKeys(defaults) {
+				creds := defaults[vendor]
+				if strings.TrimSpace(vendor) == "" {
+					problem(doc, "has a vendor with no name")
+				}
+				if creds.Username == "" {
+					problem(doc, "vendor %s has no username", vendor)
+				}
+				if creds.Password == "" {
+					problem(doc, "vendor %s has no password", vendor)
+				}
+			}
+			desired.BMCDefaults = defaults
+
+		case KIND_SWITCH_DEFAULTS:
+			var defaults model.SwitchCredentials
+			if err := decodeStrict(doc.Data, &defaults); err != nil {
+				problem(doc, "%s", err)
+				continue
+			}
+			if defaults.SNMPUsername == "" {
+				problem(doc, "has no SNMPUsername")
+			}
+			if err := defaults.Validate(); err != nil {
+				problem(doc, "%s", err)
+			}
+			desired.SwitchDefaults = &defaults
+
+		case KIND_POLICIES:
+			var policies []model.CredentialPolicy
+			if err := decodeStrict(doc.Data, &policies); err != nil {
+				problem(doc, "%s", err)
+				continue
+			}
+			names := make(map[string]bool)
+			for i, p := range policies {
+				if err := p.Validate(); err != nil {
+					problem(doc, "policy %d: %s", i+1, err)
+				}
+				if names[p.Name] {
+					problem(doc, "credential policy %s is defined more than once", p.Name)
+				}
+				names[p.Name] = true
+			}
+			if policies == nil {
+				policies = []model.CredentialPolicy{}
+			}
+			desired.Policies = &policies
+
+		case KIND_COMPONENT_CREDENTIALS:
+			var components []ComponentCredentials
+			if err := decodeStrict(doc.Data, &components); err != nil {
+				problem(doc, "%s", err)
+				continue
+			}
+			for _, p := range validateComponents(components) {
+				problem(doc, "%s", p)
+			}
+			if components == nil {
+				components = []ComponentCredentials{}
+			}
+			desired.Components = components
+
+		default:
+			problem(doc, "unknown kind of document")
+		}
+	}
+
+	if len(problems) > 0 {
+		return Desired{}, &ValidationError{Problems: problems}
+	}
+	return desired, nil
+}
+
+func sortedKeys(m map[string]model.RedsCredentials) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,363 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/model"
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
+)
+
+// vaultMock keeps values as JSON, like Vault; a missing key reads back as
+// nothing.
+type vaultMock struct {
+	data      map[string][]byte
+	failStore bool
+}
+
+func newVaultMock() *vaultMock {
+	return &vaultMock{data: make(map[string][]byte)}
+}
+
+func (v *vaultMock) Store(key string, value interface{}) error {
+	if v.failStore {
+		return errors.New("vault is sealed")
+	}
+	data, err := json.Marshal(value)
+	v.data[key] = data
+	return err
+}
+
+func (v *vaultMock) Lookup(key string, output interface{}) error {
+	if data, ok := v.data[key]; ok {
+		return json.Unmarshal(data, output)
+	}
+	return nil
+}
+
+func (v *vaultMock) Delete(key string) error {
+	delete(v.data, key)
+	return nil
+}
+
+func (v *vaultMock) LookupKeys(keyPath string) ([]string, error) { return nil, nil }
+
+const (
+	bmcDefaults    = `{"Cray": {"username": "root", "password": "initial0"}}`
+	switchDefaults = `{"SNMPUsername": "testuser", "SNMPAuthPassword": "authpass", "SNMPPrivPassword": "privpass"}`
+	policies       = `[{"name": "masters", "role": "Management", "subRole": "Master",
+		"credentials": {"username": "root", "password": "master"}}]`
+)
+
+func docs(bmc, sw, pol string) []Document {
+	ret := []Document{
+		{Kind: KIND_BMC_DEFAULTS, Source: ENV_BMC_DEFAULTS, Data: []byte(bmc)},
+		{Kind: KIND_SWITCH_DEFAULTS, Source: ENV_SWITCH_DEFAULTS, Data: []byte(sw)},
+	}
+	if pol != "" {
+		ret = append(ret, Document{Kind: KIND_POLICIES, Source: ENV_POLICIES, Data: []byte(pol)})
+	}
+	return ret
+}
+
+func TestParse(t *testing.T) {
+	desired, err := Parse(docs(bmcDefaults, switchDefaults, policies))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if desired.BMCDefaults["Cray"].Password != "initial0" || desired.SwitchDefaults.SNMPUsername != "testuser" ||
+		len(*desired.Policies) != 1 || desired.Sources[KIND_POLICIES] != ENV_POLICIES {
+		t.Errorf("Parse() = %+v", desired)
+	}
+	if desired, _ := Parse(docs(bmcDefaults, switchDefaults, "")); desired.Policies != nil {
+		t.Errorf("Parse() without policies = %+v, want them left alone", desired)
+	}
+	if desired, _ := Parse(docs(bmcDefaults, switchDefaults, "[]")); desired.Policies == nil ||
+		len(*desired.Policies) != 0 {
+		t.Errorf("Parse() of no policies = %+v, want them emptied", desired)
+	}
+
+	tests := []struct {
+		name     string
+		docs     []Document
+		problems []string
+	}{
+		{"bad JSON", docs(`{"Cray": {"username": "root",`, switchDefaults, ""), []string{"bmc-defaults"}},
+		{"unknown field", docs(`{"Cray": {"username": "root", "passwd": "x"}}`, switchDefaults, ""),
+			[]string{`unknown field "passwd"`}},
+		{"wrong type", docs(bmcDefaults, `{"SNMPUsername": 3}`, ""), []string{"switch-defaults"}},
+		{"trailing data", docs(bmcDefaults+`}`, switchDefaults, ""), []string{"after the JSON value"}},
+		{"null", docs("null", switchDefaults, ""), []string{"bmc-defaults (VAULT_REDFISH_BMC_DEFAULTS): is null"}},
+		{"empty", docs("", switchDefaults, ""), []string{"no JSON value"}},
+		{"no vendors", docs("{}", switchDefaults, ""), []string{"has no vendors"}},
+		{"missing fields", docs(`{"Cray": {"username": "root"}, "HPE": {"password": "x"}}`, `{}`, ""),
+			[]string{"vendor Cray has no password", "vendor HPE has no username", "has no SNMPUsername"}},
+		{"invalid profile", docs(bmcDefaults, `{"SNMPUsername": "u", "SNMPAuthProtocol": "rot13"}`, ""),
+			[]string{"rot13"}},
+		{"invalid policies", docs(bmcDefaults, switchDefaults, `[{"name": "all"}, {"name": "x", "role": "r",
+			"credentials": {}}, {"name": "x", "role": "r", "credentials": {}}]`),
+			[]string{"policy 1: credential policy all would match everything", "x is defined more than once"}},
+		{"twice", append(docs(bmcDefaults, switchDefaults, ""),
+			Document{Kind: KIND_BMC_DEFAULTS, Source: "bmc.json", Data: []byte(bmcDefaults)}),
+			[]string{"bmc-defaults (bmc.json): also given by VAULT_REDFISH_BMC_DEFAULTS"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.docs)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Parse() error = %v, want a ValidationError", err)
+			}
+			for _, p := range tt.problems {
+				if !strings.Contains(err.Error(), p) {
+					t.Errorf("Parse() error = %v, want it to mention %q", err, p)
+				}
+			}
+			if strings.Contains(err.Error(), "initial0") {
+				t.Errorf("Parse() error = %v shows a password", err)
+			}
+		})
+	}
+}
+
+func TestFromEnvironment(t *testing.T) {
+	for _, name := range []string{ENV_BMC_DEFAULTS, ENV_SWITCH_DEFAULTS, ENV_POLICIES, ENV_COMPONENT_CREDENTIALS} {
+		if value, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, value)
+		} else {
+			defer os.Unsetenv(name)
+		}
+		os.Unsetenv(name)
+	}
+	os.Setenv(ENV_SWITCH_DEFAULTS, switchDefaults)
+	err := Require(FromEnvironment(), KIND_BMC_DEFAULTS, KIND_SWITCH_DEFAULTS)
+	if err == nil || !strings.Contains(err.Error(), "no bmc-defaults given") {
+		t.Errorf("Require() without BMC defaults error = %v", err)
+	}
+	os.Setenv(ENV_BMC_DEFAULTS, bmcDefaults)
+	got := FromEnvironment()
+	if len(got) != 2 || got[0].Kind != KIND_BMC_DEFAULTS || string(got[1].Data) != switchDefaults ||
+		got[1].Format != FORMAT_JSON {
+		t.Errorf("FromEnvironment() = %v", got)
+	}
+	if err := Require(got, KIND_BMC_DEFAULTS, KIND_SWITCH_DEFAULTS); err != nil {
+		t.Errorf("Require() error = %v", err)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	vault := newVaultMock()
+	store := model.NewRedsCredStore(securestore.DefaultConfig().RedsCredsPath(), vault)
+	comps := compcredentials.NewCompCredStore("secret/hms-creds", vault)
+	desired, err := Parse(docs(bmcDefaults, switchDefaults, policies))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// A plan stores nothing
+	plan, err := MakePlan(store, comps, desired, MODE_PLAN)
+	if err != nil {
+		t.Fatalf("MakePlan() error = %v", err)
+	}
+	if err := plan.Apply(store, comps); err != nil || len(vault.data) != 0 {
+		t.Errorf("Apply() of a plan = %v, stored %d keys", err, len(vault.data))
+	}
+	for _, item := range plan.Items {
+		if item.Action != ACTION_CREATE || len(item.Changes) == 0 {
+			t.Errorf("MakePlan() item %+v, want %s", item, ACTION_CREATE)
+		}
+	}
+
+	plan, _ = MakePlan(store, comps, desired, MODE_APPLY)
+	if err := plan.Apply(store, comps); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if creds, _ := store.GetDefaultCredentials(); creds["Cray"].Password != "initial0" {
+		t.Errorf("GetDefaultCredentials() after Apply() = %v", creds)
+	}
+
+	// Applying the same documents again changes nothing
+	plan, _ = MakePlan(store, comps, desired, MODE_APPLY)
+	for _, item := range plan.Items {
+		if item.Action != ACTION_UNCHANGED {
+			t.Errorf("MakePlan() item %+v, want %s", item, ACTION_UNCHANGED)
+		}
+	}
+	history, _ := store.GetCredentialHistory(model.DEFAULTS_KIND_BMC)
+	plan.Apply(store, comps)
+	if again, _ := store.GetCredentialHistory(model.DEFAULTS_KIND_BMC); len(again) != len(history) {
+		t.Errorf("Apply() of unchanged defaults added a version")
+	}
+
+	// No-clobber leaves what's there alone
+	desired, _ = Parse(docs(`{"Cray": {"username": "root", "password": "changed0"}}`, switchDefaults, ""))
+	plan, _ = MakePlan(store, comps, desired, MODE_NO_CLOBBER)
+	if plan.Items[0].Action != ACTION_SKIP {
+		t.Errorf("MakePlan() no-clobber item %+v, want %s", plan.Items[0], ACTION_SKIP)
+	}
+	plan.Apply(store, comps)
+	if creds, _ := store.GetDefaultCredentials(); creds["Cray"].Password != "initial0" {
+		t.Errorf("GetDefaultCredentials() after a no-clobber Apply() = %v", creds)
+	}
+
+	plan, _ = MakePlan(store, comps, desired, MODE_APPLY)
+	want := model.CredentialChange{Key: "Cray", Field: "password", Change: model.CHANGE_CHANGED,
+		From: model.REDACTED, To: model.REDACTED}
+	if plan.Items[0].Action != ACTION_UPDATE || len(plan.Items[0].Changes) != 1 || plan.Items[0].Changes[0] != want {
+		t.Errorf("MakePlan() item %+v, want an update of the password", plan.Items[0])
+	}
+	var out bytes.Buffer
+	plan.Apply(store, comps)
+	plan.Write(&out)
+	if creds, _ := store.GetDefaultCredentials(); creds["Cray"].Password != "changed0" {
+		t.Errorf("GetDefaultCredentials() after Apply() = %v", creds)
+	}
+	if !strings.Contains(out.String(), "bmc-defaults (VAULT_REDFISH_BMC_DEFAULTS): update, applied") ||
+		!strings.Contains(out.String(), "~ Cray password: <REDACTED> -> <REDACTED>") ||
+		strings.Contains(out.String(), "changed0") {
+		t.Errorf("Write() = %s", out.String())
+	}
+
+	if _, err := MakePlan(store, comps, desired, "force"); err == nil {
+		t.Errorf("MakePlan() with an unknown mode should fail")
+	}
+}
+
+func TestPlan_storeFails(t *testing.T) {
+	vault := newVaultMock()
+	store := model.NewRedsCredStore(securestore.DefaultConfig().RedsCredsPath(), vault)
+	comps := compcredentials.NewCompCredStore("secret/hms-creds", vault)
+	desired, _ := Parse(docs(bmcDefaults, switchDefaults, ""))
+	plan, err := MakePlan(store, comps, desired, MODE_APPLY)
+	if err != nil {
+		t.Fatalf("MakePlan() error = %v", err)
+	}
+	vault.failStore = true
+	if err := plan.Apply(store, comps); err == nil {
+		t.Errorf("Apply() should fail when Vault does")
+	}
+	for _, item := range plan.Items {
+		if item.Result != RESULT_FAILED || item.Error == "" {
+			t.Errorf("Apply() item %+v, want %s", item, RESULT_FAILED)
+		}
+	}
+}
+
+func TestPlan_components(t *testing.T) {
+	vault := newVaultMock()
+	store := model.NewRedsCredStore(securestore.DefaultConfig().RedsCredsPath(), vault)
+	comps := compcredentials.NewCompCredStore("secret/hms-creds", vault)
+	comps.StoreCompCred(compcredentials.CompCredentials{Xname: "x3000c0s1b0", URL: "x3000c0s1b0/redfish/v1",
+		Username: "root", Password: "initial0"})
+
+	manifest := Document{Kind: KIND_COMPONENT_CREDENTIALS, Source: "component-credentials.csv", Format: FORMAT_CSV,
+		Data: []byte("xname,username,password,snmpAuthPassword,snmpPrivPassword\n" +
+			"x3000c0s1b0,root,changed0,,\n" +
+			"x3000c0s2b0,root,newbmc0,,\n" +
+			"x3000c0w14,snmpuser,,authpass,privpass\n")}
+	desired, err := Parse([]Document{manifest})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if desired.BMCDefaults != nil || desired.SwitchDefaults != nil || len(desired.Components) != 3 {
+		t.Fatalf("Parse() = %+v", desired)
+	}
+
+	plan, err := MakePlan(store, comps, desired, MODE_NO_CLOBBER)
+	if err != nil {
+		t.Fatalf("MakePlan() error = %v", err)
+	}
+	actions := []string{ACTION_SKIP, ACTION_CREATE, ACTION_CREATE}
+	for i, item := range plan.Items {
+		if item.Kind != KIND_COMPONENT_CREDENTIALS || item.Xname != desired.Components[i].Xname ||
+			item.Action != actions[i] {
+			t.Errorf("MakePlan() item %d = %+v, want %s", i, item, actions[i])
+		}
+	}
+	if err := plan.Apply(store, comps); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if cred, _ := comps.GetCompCred("x3000c0s1b0"); cred.Password != "initial0" {
+		t.Errorf("GetCompCred() after a no-clobber Apply() = %v", cred)
+	}
+	if cred, _ := comps.GetCompCred("x3000c0w14"); cred.Username != "snmpuser" || cred.SNMPAuthPass != "authpass" ||
+		cred.SNMPPrivPass != "privpass" || cred.Password != "" {
+		t.Errorf("GetCompCred() of a switch after Apply() = %v", cred)
+	}
+
+	plan, _ = MakePlan(store, comps, desired, MODE_APPLY)
+	want := []model.CredentialChange{{Key: "x3000c0s1b0", Field: "password", Change: model.CHANGE_CHANGED,
+		From: model.REDACTED, To: model.REDACTED}}
+	if plan.Items[0].Action != ACTION_UPDATE || !reflect.DeepEqual(plan.Items[0].Changes, want) ||
+		plan.Items[1].Action != ACTION_UNCHANGED || plan.Items[2].Action != ACTION_UNCHANGED {
+		t.Errorf("MakePlan() = %+v", plan.Items)
+	}
+	var out bytes.Buffer
+	plan.Apply(store, comps)
+	plan.Write(&out)
+	if !strings.Contains(out.String(), "component-credentials x3000c0s1b0 (component-credentials.csv): update, applied") ||
+		strings.Contains(out.String(), "changed0") {
+		t.Errorf("Write() = %s", out.String())
+	}
+	// What the manifest doesn't cover is kept
+	if cred, _ := comps.GetCompCred("x3000c0s1b0"); cred.Password != "changed0" || cred.URL != "x3000c0s1b0/redfish/v1" {
+		t.Errorf("GetCompCred() after Apply() = %v", cred)
+	}
+}
+
+func Test_validateComponents(t *testing.T) {
+	components := []ComponentCredentials{
+		{Xname: "x3000c0s1b0", Username: "root", Password: "bmc"},
+		{Xname: "X3000C0W14", Username: "snmp", SNMPAuthPassword: "a"},
+		{Xname: "x3000c0s01b0", Username: "root", Password: "again"},
+		{Xname: "x3000c0s2b0", Username: "root", SNMPAuthPassword: "a"},
+		{Xname: "x3000c0w15", Username: "snmp", Password: "p"},
+		{Xname: "x3000c0w16", Username: "snmp", SNMPPrivPassword: "p"},
+		{Xname: "x3000c0s3b0n0", Username: "root", Password: "node"},
+		{Xname: "bogus", Username: "root", Password: "bogus"},
+		{Username: "root", Password: "none"},
+	}
+	want := []string{
+		"entry 3 (x3000c0s1b0): is given more than once",
+		"entry 4 (x3000c0s2b0): has no password",
+		"entry 4 (x3000c0s2b0): only switches take SNMP passwords",
+		"entry 5 (x3000c0w15): switches take an snmpAuthPassword and snmpPrivPassword, not a password",
+		"entry 6 (x3000c0w16): SNMP privacy needs authentication, but there is no authentication password",
+		"entry 7 (x3000c0s3b0n0): is a Node, not a BMC or switch",
+		"entry 8 (bogus): invalid xname",
+		"entry 9 (): has no xname",
+	}
+	if got := validateComponents(components); !reflect.DeepEqual(got, want) {
+		t.Errorf("validateComponents() = %q, want %q", got, want)
+	}
+	if components[1].Xname != "x3000c0w14" {
+		t.Errorf("validateComponents() left xname %s", components[1].Xname)
+	}
+}
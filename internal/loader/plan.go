@@ -0,0 +1,244 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package loader
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/model"
+)
+
+// Modes of the loader
+const (
+	// Only report what would change
+	MODE_PLAN = "plan"
+	// Store every document that differs from Vault
+	MODE_APPLY = "apply"
+	// Only store documents Vault has nothing for yet
+	MODE_NO_CLOBBER = "no-clobber"
+)
+
+// What happens to a document
+const (
+	ACTION_CREATE    = "create"
+	ACTION_UPDATE    = "update"
+	ACTION_UNCHANGED = "unchanged"
+	// Vault already has something and the mode is no-clobber
+	ACTION_SKIP = "skip"
+)
+
+// Results of applying an item
+const (
+	RESULT_APPLIED = "applied"
+	RESULT_FAILED  = "failed"
+)
+
+// Item is what happens to one document, or one component of the component
+// credentials, with the changes it makes to Vault.  Passwords in the changes
+// are redacted.
+type Item struct {
+	Kind    string                   `json:"kind"`
+	Xname   string                   `json:"xname,omitempty"`
+	Source  string                   `json:"source"`
+	Action  string                   `json:"action"`
+	Changes []model.CredentialChange `json:"changes"`
+	Result  string                   `json:"result,omitempty"`
+	Error   string                   `json:"error,omitempty"`
+}
+
+// Plan is what the loader does to bring Vault in line with the documents.
+type Plan struct {
+	Mode  string `json:"mode"`
+	Items []Item `json:"items"`
+
+	desired Desired
+	// What Vault has for each component, by xname
+	components map[string]compcredentials.CompCredentials
+}
+
+// CheckMode checks mode is one of the loader's modes.
+func CheckMode(mode string) error {
+	switch mode {
+	case MODE_PLAN, MODE_APPLY, MODE_NO_CLOBBER:
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q, expected %s, %s or %s", mode, MODE_PLAN, MODE_APPLY, MODE_NO_CLOBBER)
+}
+
+// action works out what happens to a document, given whether Vault has
+// anything for it and the changes it would make.
+func action(mode string, exists bool, changes []model.CredentialChange) string {
+	switch {
+	case len(changes) == 0:
+		return ACTION_UNCHANGED
+	case !exists:
+		return ACTION_CREATE
+	case mode == MODE_NO_CLOBBER:
+		return ACTION_SKIP
+	default:
+		return ACTION_UPDATE
+	}
+}
+
+// MakePlan compares what Vault has with desired.  comps is only used for
+// component credentials.
+func MakePlan(store *model.RedsCredStore, comps *compcredentials.CompCredStore, desired Desired,
+	mode string) (*Plan, error) {
+	if err := CheckMode(mode); err != nil {
+		return nil, err
+	}
+	plan := &Plan{Mode: mode, Items: []Item{}, desired: desired,
+		components: make(map[string]compcredentials.CompCredentials)}
+	add := func(kind string, exists bool, changes []model.CredentialChange) {
+		plan.Items = append(plan.Items, Item{
+			Kind:    kind,
+			Source:  desired.Sources[kind],
+			Action:  action(mode, exists, changes),
+			Changes: changes,
+		})
+	}
+
+	if desired.BMCDefaults != nil {
+		current, err := store.GetDefaultCredentials()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the current BMC defaults: %s", err)
+		}
+		add(KIND_BMC_DEFAULTS, len(current) > 0, model.DiffCredentialVersions(
+			model.CredentialVersion{Defaults: current},
+			model.CredentialVersion{Defaults: desired.BMCDefaults}))
+	}
+
+	if desired.SwitchDefaults != nil {
+		current, err := store.GetDefaultSwitchCredentials()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the current switch defaults: %s", err)
+		}
+		add(KIND_SWITCH_DEFAULTS, !reflect.DeepEqual(current, model.SwitchCredentials{}),
+			model.DiffCredentialVersions(
+				model.CredentialVersion{SwitchDefaults: &current},
+				model.CredentialVersion{SwitchDefaults: desired.SwitchDefaults}))
+	}
+
+	if desired.Policies != nil {
+		current, err := store.GetCredentialPolicies()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the current credential policies: %s", err)
+		}
+		add(KIND_POLICIES, len(current) > 0, model.DiffCredentialPolicies(current, *desired.Policies))
+	}
+
+	for _, c := range desired.Components {
+		current, err := comps.GetCompCred(c.Xname)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the current credentials of %s: %s", c.Xname, err)
+		}
+		plan.components[c.Xname] = current
+		exists := current.Username != "" || current.Password != "" || current.SNMPAuthPass != "" ||
+			current.SNMPPrivPass != ""
+		add(KIND_COMPONENT_CREDENTIALS, exists, diffComponent(current, c))
+		plan.Items[len(plan.Items)-1].Xname = c.Xname
+	}
+
+	return plan, nil
+}
+
+// Apply stores the documents and component credentials the plan creates or
+// updates, unless it's only a plan.  Every item is tried; the error says how
+// many failed.
+func (p *Plan) Apply(store *model.RedsCredStore, comps *compcredentials.CompCredStore) error {
+	if p.Mode == MODE_PLAN {
+		return nil
+	}
+	failed := 0
+	for i := range p.Items {
+		item := &p.Items[i]
+		if item.Action != ACTION_CREATE && item.Action != ACTION_UPDATE {
+			continue
+		}
+		var err error
+		switch item.Kind {
+		case KIND_BMC_DEFAULTS:
+			err = store.StoreDefaultCredentials(p.desired.BMCDefaults)
+		case KIND_SWITCH_DEFAULTS:
+			err = store.StoreDefaultSwitchCredentials(*p.desired.SwitchDefaults)
+		case KIND_POLICIES:
+			err = store.StoreCredentialPolicies(*p.desired.Policies)
+		case KIND_COMPONENT_CREDENTIALS:
+			for _, c := range p.desired.Components {
+				if c.Xname == item.Xname {
+					err = comps.StoreCompCred(c.apply(p.components[c.Xname]))
+				}
+			}
+		}
+		if err != nil {
+			item.Result = RESULT_FAILED
+			item.Error = err.Error()
+			failed++
+		} else {
+			item.Result = RESULT_APPLIED
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of the items failed to store", failed)
+	}
+	return nil
+}
+
+func formatChange(c model.CredentialChange) string {
+	switch c.Change {
+	case model.CHANGE_ADDED:
+		return fmt.Sprintf("+ %s %s = %s", c.Key, c.Field, c.To)
+	case model.CHANGE_REMOVED:
+		return fmt.Sprintf("- %s %s (was %s)", c.Key, c.Field, c.From)
+	default:
+		return fmt.Sprintf("~ %s %s: %s -> %s", c.Key, c.Field, c.From, c.To)
+	}
+}
+
+// Write writes the plan, with the result of each item once applied, and a
+// summary.
+func (p *Plan) Write(w io.Writer) {
+	counts := make(map[string]int)
+	for _, item := range p.Items {
+		counts[item.Action]++
+		fmt.Fprintf(w, "%s", item.Kind)
+		if item.Xname != "" {
+			fmt.Fprintf(w, " %s", item.Xname)
+		}
+		fmt.Fprintf(w, " (%s): %s", item.Source, item.Action)
+		if item.Result != "" {
+			fmt.Fprintf(w, ", %s", item.Result)
+		}
+		if item.Error != "" {
+			fmt.Fprintf(w, ": %s", item.Error)
+		}
+		fmt.Fprintln(w)
+		for _, c := range item.Changes {
+			fmt.Fprintf(w, "    %s\n", formatChange(c))
+		}
+	}
+	fmt.Fprintf(w, "Mode %s: %d to create, %d to update, %d unchanged, %d skipped.\n", p.Mode,
+		counts[ACTION_CREATE], counts[ACTION_UPDATE], counts[ACTION_UNCHANGED], counts[ACTION_SKIP])
+}
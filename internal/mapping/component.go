@@ -0,0 +1,161 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	base "github.com/Cray-HPE/hms-base"
+)
+
+// ComponentDefaults are the attributes given to components REDS creates
+// when SLS doesn't have them.  An empty Class means none.
+type ComponentDefaults struct {
+	Arch    string
+	NetType string
+	Class   string
+}
+
+var componentDefaults = ComponentDefaults{
+	Arch:    base.ArchX86.String(),
+	NetType: base.NetSling.String(),
+}
+
+// SetComponentDefaults sets the site defaults for component attributes SLS
+// doesn't provide.  Empty fields keep their current default.
+func SetComponentDefaults(defaults ComponentDefaults) error {
+	d := componentDefaults
+	if defaults.Arch != "" {
+		if d.Arch = base.VerifyNormalizeArch(defaults.Arch); d.Arch == "" {
+			return fmt.Errorf("invalid default arch %q", defaults.Arch)
+		}
+	}
+	if defaults.NetType != "" {
+		if d.NetType = base.VerifyNormalizeNetType(defaults.NetType); d.NetType == "" {
+			return fmt.Errorf("invalid default net type %q", defaults.NetType)
+		}
+	}
+	if defaults.Class != "" {
+		if d.Class = base.VerifyNormalizeClass(defaults.Class); d.Class == "" {
+			return fmt.Errorf("invalid default class %q", defaults.Class)
+		}
+	}
+	componentDefaults = d
+	return nil
+}
+
+// GetComponentDefaults returns the site defaults for component attributes.
+func GetComponentDefaults() ComponentDefaults {
+	return componentDefaults
+}
+
+// slsAttribute returns a string ExtraProperty normalized with normalize, or
+// def if SLS doesn't have it.
+func slsAttribute(node GenericHardware, key string, def string, normalize func(string) string) (string, error) {
+	raw := extraPropertyString(node, key)
+	if raw == "" {
+		return def, nil
+	}
+	if val := normalize(raw); val != "" {
+		return val, nil
+	}
+	return "", fmt.Errorf("invalid %s %q for %s in SLS", key, raw, node.Xname)
+}
+
+// slsNID returns the NID of a node from SLS, or "" if it has none.  SLS
+// returns numbers as float64, but json.Number, ints and numeric strings are
+// accepted too.
+func slsNID(node GenericHardware) (json.Number, error) {
+	props, _ := node.ExtraPropertiesRaw.(map[string]interface{})
+	raw, ok := props["NID"]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	var nid float64
+	var err error
+	switch val := raw.(type) {
+	case float64:
+		nid = val
+	case int:
+		nid = float64(val)
+	case json.Number:
+		nid, err = val.Float64()
+	case string:
+		nid, err = strconv.ParseFloat(val, 64)
+	default:
+		err = fmt.Errorf("unexpected type %T", raw)
+	}
+	if err != nil || nid < 0 || nid != math.Trunc(nid) || nid > math.MaxInt32 {
+		return "", fmt.Errorf("invalid NID %v for %s in SLS", raw, node.Xname)
+	}
+	return json.Number(strconv.FormatInt(int64(nid), 10)), nil
+}
+
+// slsAliases returns the Aliases of a node from SLS.
+func slsAliases(node GenericHardware) []string {
+	props, _ := node.ExtraPropertiesRaw.(map[string]interface{})
+	raw, _ := props["Aliases"].([]interface{})
+	var aliases []string
+	for _, a := range raw {
+		if alias, ok := a.(string); ok && alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// componentFromSLS builds the HSM component for a node from its SLS entry,
+// filling in the site defaults for attributes SLS doesn't have.  Invalid
+// values in SLS are errors rather than being replaced by defaults.
+func componentFromSLS(node GenericHardware) (base.Component, error) {
+	comp := base.Component{
+		ID:    node.Xname,
+		State: base.StatePopulated.String(),
+	}
+	var err error
+	if comp.Role, err = slsAttribute(node, "Role", "", base.VerifyNormalizeRole); err != nil {
+		return comp, err
+	}
+	if comp.SubRole, err = slsAttribute(node, "SubRole", "", base.VerifyNormalizeSubRole); err != nil {
+		return comp, err
+	}
+	if comp.Arch, err = slsAttribute(node, "Arch", componentDefaults.Arch, base.VerifyNormalizeArch); err != nil {
+		return comp, err
+	}
+	if comp.NetType, err = slsAttribute(node, "NetType", componentDefaults.NetType, base.VerifyNormalizeNetType); err != nil {
+		return comp, err
+	}
+	if comp.NID, err = slsNID(node); err != nil {
+		return comp, err
+	}
+	comp.Class = componentDefaults.Class
+	if node.Class != "" {
+		if comp.Class = base.VerifyNormalizeClass(node.Class); comp.Class == "" {
+			return comp, fmt.Errorf("invalid Class %q for %s in SLS", node.Class, node.Xname)
+		}
+	}
+	return comp, nil
+}
@@ -0,0 +1,130 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func slsNode(class string, props map[string]interface{}) GenericHardware {
+	return GenericHardware{
+		Parent:             "x3000c0s1b0",
+		Xname:              "x3000c0s1b0n0",
+		Class:              class,
+		ExtraPropertiesRaw: props,
+	}
+}
+
+func Test_componentFromSLS(t *testing.T) {
+	defer SetComponentDefaults(GetComponentDefaults())
+
+	tests := []struct {
+		name        string
+		node        GenericHardware
+		wantArch    string
+		wantNetType string
+		wantClass   string
+		wantNID     string
+		wantErr     bool
+	}{{
+		name: "Defaults",
+		node: slsNode("River", map[string]interface{}{
+			"Role": "Management", "SubRole": "Master", "NID": float64(100001)}),
+		wantArch:    "X86",
+		wantNetType: "Sling",
+		wantClass:   "River",
+		wantNID:     "100001",
+	}, {
+		name: "FromSLS",
+		node: slsNode("hill", map[string]interface{}{
+			"Role": "management", "SubRole": "master", "NID": json.Number("7"),
+			"Arch": "arm", "NetType": "ethernet"}),
+		wantArch:    "ARM",
+		wantNetType: "Ethernet",
+		wantClass:   "Hill",
+		wantNID:     "7",
+	}, {
+		name: "NIDString",
+		node: slsNode("River", map[string]interface{}{
+			"Role": "Management", "SubRole": "Master", "NID": "12"}),
+		wantArch:    "X86",
+		wantNetType: "Sling",
+		wantClass:   "River",
+		wantNID:     "12",
+	}, {
+		name: "BadArch",
+		node: slsNode("River", map[string]interface{}{
+			"Role": "Management", "SubRole": "Master", "Arch": "sparc"}),
+		wantErr: true,
+	}, {
+		name: "BadNetType",
+		node: slsNode("River", map[string]interface{}{
+			"Role": "Management", "SubRole": "Master", "NetType": "token-ring"}),
+		wantErr: true,
+	}, {
+		name:    "BadClass",
+		node:    slsNode("Lake", map[string]interface{}{"Role": "Management", "SubRole": "Master"}),
+		wantErr: true,
+	}, {
+		name: "FractionalNID",
+		node: slsNode("River", map[string]interface{}{
+			"Role": "Management", "SubRole": "Master", "NID": 1.5}),
+		wantErr: true,
+	}, {
+		name: "NIDWrongType",
+		node: slsNode("River", map[string]interface{}{
+			"Role": "Management", "SubRole": "Master", "NID": []interface{}{1}}),
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comp, err := componentFromSLS(tt.node)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("componentFromSLS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if comp.Arch != tt.wantArch || comp.NetType != tt.wantNetType || comp.Class != tt.wantClass ||
+				comp.NID.String() != tt.wantNID || comp.Role != "Management" || comp.SubRole != "Master" ||
+				comp.State != "Populated" {
+				t.Errorf("componentFromSLS() = %+v", comp)
+			}
+		})
+	}
+
+	if err := SetComponentDefaults(ComponentDefaults{Arch: "arm", NetType: "Ethernet", Class: "river"}); err != nil {
+		t.Fatalf("SetComponentDefaults() error = %v", err)
+	}
+	comp, err := componentFromSLS(slsNode("", map[string]interface{}{"Role": "Management", "SubRole": "Master"}))
+	if err != nil || comp.Arch != "ARM" || comp.NetType != "Ethernet" || comp.Class != "River" || comp.NID != "" {
+		t.Errorf("componentFromSLS() with site defaults = %+v, %v", comp, err)
+	}
+	if err := SetComponentDefaults(ComponentDefaults{Arch: "sparc"}); err == nil {
+		t.Errorf("SetComponentDefaults() accepted an invalid arch")
+	}
+	if GetComponentDefaults().Arch != "ARM" {
+		t.Errorf("Invalid defaults replaced valid ones")
+	}
+}
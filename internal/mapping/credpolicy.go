@@ -0,0 +1,186 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"log"
+
+	base "github.com/Cray-HPE/hms-base"
+	"github.com/Cray-HPE/hms-reds/internal/model"
+)
+
+// credentialOrigin says where the default credentials seeded for a BMC came
+// from: a credential policy, or a vendor's defaults.
+type credentialOrigin struct {
+	Policy       string
+	Vendor       string
+	VendorSource string
+}
+
+// PolicyMatch is the credential policy a component resolves to, without
+// the secrets.
+type PolicyMatch struct {
+	Name        string `json:"name"`
+	Specificity int    `json:"specificity"`
+	XnamePrefix string `json:"xnamePrefix,omitempty"`
+	XnameRegex  string `json:"xnameRegex,omitempty"`
+	Role        string `json:"role,omitempty"`
+	SubRole     string `json:"subRole,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Username    string `json:"username,omitempty"`
+}
+
+// PolicyResolution is the policy a component would get its BMC and switch
+// credentials from; nil if no policy matches and the defaults would be used.
+type PolicyResolution struct {
+	Target model.PolicyTarget `json:"target"`
+	BMC    *PolicyMatch       `json:"bmc"`
+	Switch *PolicyMatch       `json:"switch"`
+}
+
+func newPolicyMatch(p model.CredentialPolicy, username string) *PolicyMatch {
+	return &PolicyMatch{
+		Name:        p.Name,
+		Specificity: p.Specificity(),
+		XnamePrefix: p.XnamePrefix,
+		XnameRegex:  p.XnameRegex,
+		Role:        p.Role,
+		SubRole:     p.SubRole,
+		Type:        p.Type,
+		Username:    username,
+	}
+}
+
+// ResolveCredentialPolicies returns the policies target would get its
+// credentials from.  If target has no Type it's worked out from the xname.
+func ResolveCredentialPolicies(target model.PolicyTarget) (PolicyResolution, error) {
+	if target.Type == "" {
+		target.Type = base.GetHMSTypeString(target.Xname)
+	}
+	res := PolicyResolution{Target: target}
+	policies, err := redsCreds.GetCredentialPolicies()
+	if err != nil {
+		return res, err
+	}
+	if p, ok := model.ResolveCredentialPolicy(policies, model.POLICY_KIND_BMC, target); ok {
+		res.BMC = newPolicyMatch(p, p.Credentials.Username)
+	}
+	if p, ok := model.ResolveCredentialPolicy(policies, model.POLICY_KIND_SWITCH, target); ok {
+		res.Switch = newPolicyMatch(p, p.SwitchCredentials.SNMPUsername)
+	}
+	return res, nil
+}
+
+// policyTarget describes an SLS object for matching credential policies.
+// For a node, pass the BMC's xname; the role comes from the node.
+func policyTarget(xname string, gh GenericHardware) model.PolicyTarget {
+	return model.PolicyTarget{
+		Xname:   xname,
+		Role:    extraPropertyString(gh, "Role"),
+		SubRole: extraPropertyString(gh, "SubRole"),
+		Type:    base.GetHMSTypeString(xname),
+	}
+}
+
+// defaultBMCCredentials picks the credentials to seed a node's BMC with:
+// those of the most specific matching credential policy, or else the
+// default credentials of the BMC's vendor.
+func defaultBMCCredentials(ctx context.Context, node GenericHardware) (model.RedsCredentials, credentialOrigin, error) {
+	policies, err := redsCreds.GetCredentialPolicies()
+	if err != nil {
+		log.Printf("ERROR: Unable to get credential policies: %s", err)
+		return model.RedsCredentials{}, credentialOrigin{}, err
+	}
+	p, ok := model.ResolveCredentialPolicy(policies, model.POLICY_KIND_BMC, policyTarget(node.Parent, node))
+	if ok {
+		return *p.Credentials, credentialOrigin{Policy: p.Name}, nil
+	}
+
+	defaultCreds, err := redsCreds.GetDefaultCredentials()
+	if err != nil {
+		log.Printf("ERROR: Unable to get defualt credentials: %s", err)
+		return model.RedsCredentials{}, credentialOrigin{}, err
+	}
+	if ctx.Err() != nil {
+		return model.RedsCredentials{}, credentialOrigin{}, ctx.Err()
+	}
+	creds, vendor, source, err := selectDefaultCredentials(ctx, node, defaultCreds)
+	return creds, credentialOrigin{Vendor: vendor, VendorSource: source}, err
+}
+
+// defaultSwitchCredentials picks the SNMP profile for a switch: that of the
+// most specific matching credential policy, or else the switch defaults,
+// for the switch's model.  It also returns the name of the policy, if any.
+func defaultSwitchCredentials(gh GenericHardware) (model.SwitchCredentials, string, error) {
+	var switchModel string
+	if raw, ok := gh.ExtraPropertiesRaw.(map[string]interface{}); ok {
+		switchModel, _ = raw["Model"].(string)
+	}
+	policies, err := redsCreds.GetCredentialPolicies()
+	if err != nil {
+		return model.SwitchCredentials{}, "", err
+	}
+	if p, ok := model.ResolveCredentialPolicy(policies, model.POLICY_KIND_SWITCH, policyTarget(gh.Xname, gh)); ok {
+		return p.SwitchCredentials.ForModel(switchModel), p.Name, nil
+	}
+	creds, err := redsCreds.GetDefaultSwitchCredentials()
+	return creds.ForModel(switchModel), "", err
+}
+
+// applySNMPProfile fills in the parts of a switch's SNMPv3 settings SLS
+// doesn't give from its profile.  Without a security level in either, the
+// switch gets the highest one its passwords allow, and protocols it needs
+// but has none for default to MD5 and DES.
+func applySNMPProfile(sw *Switch, profile model.SwitchCredentials) {
+	if sw.SnmpSecurityLevel == "" {
+		sw.SnmpSecurityLevel, _ = model.CanonicalSNMPSecurityLevel(profile.SNMPSecurityLevel)
+	}
+	if sw.SnmpSecurityLevel == "" {
+		sw.SnmpSecurityLevel = model.SwitchCredentials{
+			SNMPAuthPassword: sw.SnmpAuthPassword,
+			SNMPPrivPassword: sw.SnmpPrivPassword,
+		}.SecurityLevel()
+	}
+	if sw.SnmpAuthProtocol == "" {
+		sw.SnmpAuthProtocol, _ = model.CanonicalSNMPAuthProtocol(profile.SNMPAuthProtocol)
+	}
+	if sw.SnmpPrivProtocol == "" {
+		sw.SnmpPrivProtocol, _ = model.CanonicalSNMPPrivProtocol(profile.SNMPPrivProtocol)
+	}
+	if sw.SnmpContext == "" {
+		sw.SnmpContext = profile.SNMPContext
+	}
+
+	switch sw.SnmpSecurityLevel {
+	case model.SNMP_LEVEL_AUTH_PRIV:
+		if sw.SnmpPrivProtocol == "" {
+			sw.SnmpPrivProtocol = model.DefaultSNMPPrivProtocol
+		}
+		fallthrough
+	case model.SNMP_LEVEL_AUTH_NO_PRIV:
+		if sw.SnmpAuthProtocol == "" {
+			sw.SnmpAuthProtocol = model.DefaultSNMPAuthProtocol
+		}
+	}
+}
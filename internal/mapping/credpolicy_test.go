@@ -0,0 +1,122 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Cray-HPE/hms-reds/internal/model"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+)
+
+func Test_credentialPolicies(t *testing.T) {
+	saved := redsCreds
+	defer func() { redsCreds = saved }()
+	redsCreds = model.NewRedsCredStore("secret/reds-creds", &vaultMock{data: make(map[string][]byte)})
+	redsCreds.StoreDefaultCredentials(map[string]model.RedsCredentials{
+		"Cray": {Username: "root", Password: "cray"},
+	})
+	redsCreds.StoreDefaultSwitchCredentials(model.SwitchCredentials{SNMPUsername: "default"})
+	err := redsCreds.StoreCredentialPolicies([]model.CredentialPolicy{
+		{Name: "masters", Role: "Management", SubRole: "Master",
+			Credentials: &model.RedsCredentials{Username: "root", Password: "master"}},
+		{Name: "cabinet-3000-switches", XnamePrefix: "x3000", Type: "MgmtSwitch",
+			SwitchCredentials: &model.SwitchCredentials{SNMPUsername: "x3000"}},
+	})
+	if err != nil {
+		t.Fatalf("StoreCredentialPolicies() error = %v", err)
+	}
+
+	master := vendorTestNode("x3000c0s1b0", map[string]interface{}{"Role": "Management", "SubRole": "Master"})
+	creds, origin, err := defaultBMCCredentials(context.Background(), master)
+	if err != nil || creds.Password != "master" || origin != (credentialOrigin{Policy: "masters"}) {
+		t.Errorf("defaultBMCCredentials() of a master = %s, %+v, %v", creds, origin, err)
+	}
+	worker := vendorTestNode("x3000c0s7b0", map[string]interface{}{"Role": "Management", "SubRole": "Worker"})
+	creds, origin, err = defaultBMCCredentials(context.Background(), worker)
+	if err != nil || creds.Password != "cray" ||
+		origin != (credentialOrigin{Vendor: "Cray", VendorSource: VENDOR_SOURCE_FALLBACK}) {
+		t.Errorf("defaultBMCCredentials() of a worker = %s, %+v, %v", creds, origin, err)
+	}
+
+	sw := GenericHardware{Xname: "x3000c0w14"}
+	if swCreds, policy, err := defaultSwitchCredentials(sw); err != nil || swCreds.SNMPUsername != "x3000" ||
+		policy != "cabinet-3000-switches" {
+		t.Errorf("defaultSwitchCredentials() = %s, %q, %v", swCreds, policy, err)
+	}
+	sw.Xname = "x3001c0w14"
+	if swCreds, policy, err := defaultSwitchCredentials(sw); err != nil || swCreds.SNMPUsername != "default" ||
+		policy != "" {
+		t.Errorf("defaultSwitchCredentials() outside the cabinet = %s, %q, %v", swCreds, policy, err)
+	}
+
+	res, err := ResolveCredentialPolicies(model.PolicyTarget{Xname: "x3000c0w14"})
+	if err != nil || res.Target.Type != "MgmtSwitch" || res.BMC != nil || res.Switch == nil ||
+		res.Switch.Name != "cabinet-3000-switches" || res.Switch.Username != "x3000" {
+		t.Errorf("ResolveCredentialPolicies() = %+v, %v", res, err)
+	}
+}
+
+func Test_switchFromSLSReturn_profile(t *testing.T) {
+	savedRedsCreds, savedCompcreds := redsCreds, compcreds
+	defer func() { redsCreds, compcreds = savedRedsCreds, savedCompcreds }()
+	vault := &vaultMock{data: make(map[string][]byte)}
+	redsCreds = model.NewRedsCredStore("secret/reds-creds", vault)
+	compcreds = compcredentials.NewCompCredStore("secret/hms-creds", vault)
+	err := redsCreds.StoreDefaultSwitchCredentials(model.SwitchCredentials{
+		SNMPUsername:     "testuser",
+		SNMPAuthPassword: "authpass",
+		SNMPPrivPassword: "privpass",
+		Models: map[string]model.SwitchCredentials{
+			"Aruba 8325": {SNMPAuthProtocol: "sha256", SNMPPrivProtocol: "aes256", SNMPContext: "default"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreDefaultSwitchCredentials() error = %v", err)
+	}
+
+	aruba := GenericHardware{Xname: "x3000c0w22", ExtraPropertiesRaw: map[string]interface{}{
+		"IP4addr": "10.1.1.22", "Model": "Aruba 8325"}}
+	sw, err := switchFromSLSReturn(aruba)
+	if err != nil {
+		t.Fatalf("switchFromSLSReturn() error = %v", err)
+	}
+	if sw.SnmpUser != "testuser" || sw.SnmpSecurityLevel != model.SNMP_LEVEL_AUTH_PRIV ||
+		sw.SnmpAuthProtocol != "SHA-256" || sw.SnmpPrivProtocol != "AES-256" || sw.SnmpContext != "default" {
+		t.Errorf("switchFromSLSReturn() of a profiled model = %s", sw)
+	}
+
+	// SLS protocols win, and a model without a profile gets the defaults
+	dell := GenericHardware{Xname: "x3000c0w14", ExtraPropertiesRaw: map[string]interface{}{
+		"IP4addr": "10.1.1.14", "Model": "Dell S3048-ON", "SNMPAuthProtocol": "SHA"}}
+	sw, err = switchFromSLSReturn(dell)
+	if err != nil {
+		t.Fatalf("switchFromSLSReturn() error = %v", err)
+	}
+	if sw.SnmpSecurityLevel != model.SNMP_LEVEL_AUTH_PRIV || sw.SnmpAuthProtocol != "SHA" ||
+		sw.SnmpPrivProtocol != model.DefaultSNMPPrivProtocol || sw.SnmpContext != "" {
+		t.Errorf("switchFromSLSReturn() of another model = %s", sw)
+	}
+}
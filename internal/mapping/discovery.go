@@ -0,0 +1,180 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Cray-HPE/hms-reds/internal/smdclient"
+)
+
+// How many times a BMC HSM fails to discover is rediscovered, how long HSM
+// gets for each attempt and whether the BMC's credentials are re-sent from
+// Vault each time
+var discoveryRetries = 3
+var discoveryTimeout = 10 * time.Minute
+var rediscoverRefreshCreds = false
+
+// SetDiscoveryRetry sets how many times REDS has HSM rediscover a BMC whose
+// discovery failed, how long each attempt may take, and whether the BMC's
+// credentials are sent to HSM again from Vault first.
+func SetDiscoveryRetry(retries int, timeout time.Duration, refreshCreds bool) {
+	if retries >= 0 {
+		discoveryRetries = retries
+	}
+	if timeout > 0 {
+		discoveryTimeout = timeout
+	}
+	rediscoverRefreshCreds = refreshCreds
+}
+
+// discovery is a registered BMC whose discovery by HSM hasn't settled yet.
+type discovery struct {
+	node GenericHardware
+	// When HSM was last asked to discover it
+	since         time.Time
+	rediscoveries int
+}
+
+// startDiscovery starts watching HSM discover a BMC that was just
+// registered.  Must be called with o.lock held.
+func (o *onboarder) startDiscovery(node GenericHardware) {
+	o.discovering[node.Parent] = &discovery{node: node, since: time.Now()}
+	setNodeStatus(node, func(status *NodeStatus) {
+		status.State = NODE_STATE_DISCOVERING
+		status.DiscoveryStatus = ""
+		status.Rediscoveries = 0
+		status.Error = ""
+	})
+}
+
+// checkDiscovery looks at how HSM is getting on discovering the BMCs
+// registered on earlier passes.  A BMC whose discovery failed, or is taking
+// too long, is rediscovered up to discoveryRetries times before REDS gives
+// up on it.  A BMC HSM no longer has is forgotten so the next pass
+// registers it again.
+func (o *onboarder) checkDiscovery() {
+	o.lock.Lock()
+	pending := make([]*discovery, 0, len(o.discovering))
+	for _, d := range o.discovering {
+		pending = append(pending, d)
+	}
+	o.lock.Unlock()
+
+	for _, d := range pending {
+		bmc := d.node.Parent
+		ep, err := smdclient.GetRedfishEndpoint(bmc)
+		if smdclient.StatusCode(err) == 404 {
+			log.Printf("WARNING: %s is no longer in HSM, will register it again", bmc)
+			o.lock.Lock()
+			delete(o.discovering, bmc)
+			delete(o.nodes, bmc)
+			o.lock.Unlock()
+			continue
+		} else if err != nil {
+			log.Printf("WARNING: Unable to check discovery of %s: %s", bmc, err)
+			continue
+		}
+
+		status := ep.DiscoveryInfo.LastDiscoveryStatus
+		switch status {
+		case smdclient.DiscoveryStatusOK:
+			log.Printf("INFO: HSM discovered %s", bmc)
+			o.lock.Lock()
+			delete(o.discovering, bmc)
+			o.lock.Unlock()
+			setNodeStatus(d.node, func(s *NodeStatus) {
+				s.State = NODE_STATE_DISCOVERED
+				s.DiscoveryStatus = status
+				s.Error = ""
+			})
+		case smdclient.DiscoveryStatusStarted, smdclient.DiscoveryStatusNotQueried, "":
+			if time.Since(d.since) < discoveryTimeout {
+				setNodeStatus(d.node, func(s *NodeStatus) { s.DiscoveryStatus = status })
+				continue
+			}
+			o.discoveryFailed(d, status, fmt.Sprintf("discovery still %s after %s", status, discoveryTimeout))
+		default:
+			o.discoveryFailed(d, status, "discovery failed: "+status)
+		}
+	}
+}
+
+// discoveryFailed rediscovers a BMC HSM couldn't discover, or gives up on it
+// once it has been rediscovered discoveryRetries times.
+func (o *onboarder) discoveryFailed(d *discovery, status string, reason string) {
+	bmc := d.node.Parent
+	if d.rediscoveries >= discoveryRetries {
+		log.Printf("ERROR: Giving up on discovery of %s after %d rediscoveries: %s",
+			bmc, d.rediscoveries, reason)
+		o.lock.Lock()
+		delete(o.discovering, bmc)
+		o.lock.Unlock()
+		setNodeStatus(d.node, func(s *NodeStatus) {
+			s.State = NODE_STATE_DISCOVERY_FAILED
+			s.DiscoveryStatus = status
+			s.Error = reason
+		})
+		return
+	}
+
+	log.Printf("WARNING: %s: %s, rediscovering (%d of %d)", bmc, reason, d.rediscoveries+1, discoveryRetries)
+	var user, password string
+	if rediscoverRefreshCreds {
+		creds, err := compcreds.GetCompCred(bmc)
+		if err != nil {
+			log.Printf("WARNING: Unable to get credentials for %s from Vault: %s", bmc, err)
+		}
+		user, password = creds.Username, creds.Password
+	}
+	err := smdclient.RediscoverRedfishEndpoint(bmc, user, password)
+	if smdclient.IsLocked(err) {
+		// Doesn't count as a rediscovery; tried again next pass.
+		setNodeStatus(d.node, func(s *NodeStatus) {
+			s.State = NODE_STATE_DEFERRED
+			s.DiscoveryStatus = status
+			s.Error = reason + "; rediscovery deferred: " + err.Error()
+		})
+		return
+	} else if err != nil {
+		// Try again next pass.
+		setNodeStatus(d.node, func(s *NodeStatus) {
+			s.DiscoveryStatus = status
+			s.Error = reason + "; unable to rediscover: " + err.Error()
+		})
+		return
+	}
+
+	o.lock.Lock()
+	d.rediscoveries++
+	d.since = time.Now()
+	o.lock.Unlock()
+	setNodeStatus(d.node, func(s *NodeStatus) {
+		s.State = NODE_STATE_DISCOVERING
+		s.DiscoveryStatus = status
+		s.Rediscoveries = d.rediscoveries
+		s.Error = reason
+	})
+}
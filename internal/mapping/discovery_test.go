@@ -0,0 +1,140 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/smdclient"
+)
+
+func Test_onboarder_checkDiscovery(t *testing.T) {
+	ConfigureSLSMode(SLS_BASE_URL, NewTestClient(BaseRTFunc), &mss, nil, INSTNAME)
+	compcreds.StoreCompCred(compcredentials.CompCredentials{
+		Xname:    "x3000c0s3b0",
+		Username: "root",
+		Password: "from-vault",
+	})
+	fake := smdclient.NewFakeHSM()
+	smdclient.SetClient(fake)
+	defer smdclient.SetClient(nil)
+	defer func() { prepareNodeFunc = prepareNode }()
+	prepareNodeFunc = func(ctx context.Context, node GenericHardware) (*nodeRegistration, error) {
+		return &nodeRegistration{
+			node:     node,
+			endpoint: smdclient.HSMNotification{ID: node.Parent, RediscoverOnUpdate: true},
+		}, nil
+	}
+	defer SetDiscoveryRetry(discoveryRetries, discoveryTimeout, rediscoverRefreshCreds)
+	SetDiscoveryRetry(2, time.Hour, true)
+
+	nodes := []GenericHardware{
+		onboardTestNode("x3000c0s1b0n0", "x3000c0s1b0"),
+		onboardTestNode("x3000c0s3b0n0", "x3000c0s3b0"),
+		onboardTestNode("x3000c0s5b0n0", "x3000c0s5b0"),
+		onboardTestNode("x3000c0s9b0n0", "x3000c0s9b0"),
+	}
+	o := newOnboarder()
+	if summary := o.runCycle(nodes); summary.Added != 4 {
+		t.Fatalf("Summary was %+v", summary)
+	}
+	state := func(bmc string) NodeStatus {
+		status, _ := GetNodeStatus(bmc)
+		return status
+	}
+	if s := state("x3000c0s1b0"); s.State != NODE_STATE_DISCOVERING || s.Node != "x3000c0s1b0n0" {
+		t.Fatalf("Status after registration was %+v", s)
+	}
+
+	fake.SetDiscoveryStatus("x3000c0s1b0", "DiscoverOK")
+	fake.SetDiscoveryStatus("x3000c0s3b0", "HTTPsGetFailed")
+	delete(fake.RedfishEndpoints, "x3000c0s9b0")
+	fake.ClearRequests()
+	o.checkDiscovery()
+
+	if s := state("x3000c0s1b0"); s.State != NODE_STATE_DISCOVERED {
+		t.Errorf("Discovered BMC status was %+v", s)
+	}
+	if s := state("x3000c0s5b0"); s.State != NODE_STATE_DISCOVERING || s.DiscoveryStatus != "NotYetQueried" {
+		t.Errorf("Pending BMC status was %+v", s)
+	}
+	s := state("x3000c0s3b0")
+	if s.State != NODE_STATE_DISCOVERING || s.Rediscoveries != 1 || s.DiscoveryStatus != "HTTPsGetFailed" {
+		t.Errorf("Failed BMC status was %+v", s)
+	}
+	var patch *smdclient.RedfishEndpointPatch
+	for _, req := range fake.GetRequests() {
+		if req.Method == http.MethodPatch {
+			if req.Path != "/Inventory/RedfishEndpoints/x3000c0s3b0" || patch != nil {
+				t.Fatalf("Unexpected PATCH %+v", req)
+			}
+			p := req.Body.(smdclient.RedfishEndpointPatch)
+			patch = &p
+		}
+	}
+	if patch == nil || patch.RediscoverOnUpdate == nil || !*patch.RediscoverOnUpdate ||
+		patch.Password == nil || *patch.Password != "from-vault" {
+		t.Fatalf("Rediscovery PATCH was %+v", patch)
+	}
+
+	// The missing BMC is registered again next pass.
+	fake.ClearRequests()
+	if summary := o.runCycle(nodes); summary.New != 1 || summary.Added != 1 {
+		t.Errorf("Pass after the BMC went missing: %+v", summary)
+	}
+
+	// Discovery keeps failing until REDS gives up.
+	for i := 0; i < 2; i++ {
+		fake.SetDiscoveryStatus("x3000c0s3b0", "HTTPsGetFailed")
+		o.checkDiscovery()
+	}
+	if s := state("x3000c0s3b0"); s.State != NODE_STATE_DISCOVERY_FAILED || s.Rediscoveries != 2 || s.Error == "" {
+		t.Errorf("Status after giving up was %+v", s)
+	}
+	fake.ClearRequests()
+	o.checkDiscovery()
+	for _, req := range fake.GetRequests() {
+		if req.Path == "/Inventory/RedfishEndpoints/x3000c0s3b0" {
+			t.Errorf("Still checking a BMC REDS gave up on")
+		}
+	}
+
+	// Discovery that never finishes counts as failed.
+	SetDiscoveryRetry(2, time.Millisecond, false)
+	time.Sleep(5 * time.Millisecond)
+	o.checkDiscovery()
+	if s := state("x3000c0s5b0"); s.Rediscoveries != 1 || s.Error == "" {
+		t.Errorf("Status after timing out was %+v", s)
+	}
+
+	statuses := GetNodeStatuses()
+	for i := 1; i < len(statuses); i++ {
+		if statuses[i-1].BMC >= statuses[i].BMC {
+			t.Fatalf("Statuses not sorted: %+v", statuses)
+		}
+	}
+}
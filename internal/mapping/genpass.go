@@ -0,0 +1,112 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/passwords"
+)
+
+// Whether new BMCs get a generated password of their own instead of the
+// shared default, the rules those passwords follow and what sets them on
+// the BMC
+var generatePasswords = false
+var passwordRules = passwords.RuleSet{}
+var passwordApplier passwords.Applier
+var passwordLock sync.RWMutex
+
+// SetPasswordGeneration turns generated BMC passwords on or off.  applier
+// sets the generated passwords on the BMCs; it must be set if enabled is.
+func SetPasswordGeneration(enabled bool, rules passwords.RuleSet, applier passwords.Applier) error {
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+	if enabled && applier == nil {
+		return fmt.Errorf("generated passwords need a way to set them on BMCs")
+	}
+	passwordLock.Lock()
+	defer passwordLock.Unlock()
+	generatePasswords = enabled
+	passwordRules = rules
+	passwordApplier = applier
+	return nil
+}
+
+// passwordGeneration returns whether passwords are generated, and the rules
+// and applier to use if they are.
+func passwordGeneration() (bool, passwords.RuleSet, passwords.Applier) {
+	passwordLock.RLock()
+	defer passwordLock.RUnlock()
+	return generatePasswords, passwordRules, passwordApplier
+}
+
+// storeGeneratedPassword gives a BMC that still has the default credentials
+// a generated password, then stores it in Vault.  If it can't be stored the
+// BMC is put back to the default so REDS doesn't lose the only copy.
+func storeGeneratedPassword(ctx context.Context, node GenericHardware,
+	defaults compcredentials.CompCredentials, vendor string) error {
+	_, rules, applier := passwordGeneration()
+	if vendor == "" {
+		vendor, _ = GetVendorPolicy().resolveVendor(ctx, node)
+	}
+	password, err := rules.For(vendor).Generate()
+	if err != nil {
+		return fmt.Errorf("unable to generate a password for %s: %s", node.Parent, err)
+	}
+
+	target := passwords.Target{
+		Xname:  node.Parent,
+		Host:   extraPropertyString(node, SLS_BMC_IP_PROPERTY),
+		Vendor: vendor,
+	}
+	if target.Host == "" {
+		target.Host = node.Parent
+	}
+	err = applier.Apply(ctx, target, defaults.Username, defaults.Password, password)
+	if err != nil {
+		return fmt.Errorf("unable to set a generated password on %s: %s", node.Parent, err)
+	}
+
+	generated := defaults
+	generated.Password = password
+	err = compcreds.StoreCompCred(generated)
+	if err != nil {
+		log.Printf("ERROR: Unable to store the generated password for %s, putting back the default: %s",
+			node.Parent, err)
+		// Not bound by the onboarding timeout; losing the password is worse.
+		revertErr := applier.Apply(context.Background(), target, defaults.Username, password,
+			defaults.Password)
+		if revertErr != nil {
+			log.Printf("ERROR: Unable to put back the default password on %s, its password is lost: %s",
+				node.Parent, revertErr)
+		}
+		return err
+	}
+	log.Printf("INFO: Set a generated password on %s", node.Parent)
+	return nil
+}
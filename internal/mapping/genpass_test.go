@@ -0,0 +1,136 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/passwords"
+)
+
+// vaultMock behaves like Vault: a missing key reads back as nothing.
+type vaultMock struct {
+	data      map[string][]byte
+	failStore bool
+}
+
+func (v *vaultMock) Store(key string, value interface{}) error {
+	if v.failStore {
+		return errors.New("vault is sealed")
+	}
+	data, err := json.Marshal(value)
+	v.data[key] = data
+	return err
+}
+
+func (v *vaultMock) Lookup(key string, output interface{}) error {
+	if data, ok := v.data[key]; ok {
+		return json.Unmarshal(data, output)
+	}
+	return nil
+}
+
+func (v *vaultMock) Delete(key string) error {
+	delete(v.data, key)
+	return nil
+}
+
+func (v *vaultMock) LookupKeys(keyPath string) ([]string, error) { return nil, nil }
+
+// stubApplier records the passwords it's asked to set.
+type stubApplier struct {
+	applied []string
+	err     error
+}
+
+func (a *stubApplier) Apply(ctx context.Context, target passwords.Target, username string, current string,
+	password string) error {
+	a.applied = append(a.applied, target.Xname+" "+target.Host+" "+username+" "+current+"->"+password)
+	return a.err
+}
+
+func Test_storeGeneratedPassword(t *testing.T) {
+	savedCompcreds := compcreds
+	defer func() { compcreds = savedCompcreds }()
+	defer SetPasswordGeneration(false, passwords.RuleSet{}, nil)
+	vault := &vaultMock{data: make(map[string][]byte)}
+	compcreds = compcredentials.NewCompCredStore("secret/hms-creds", vault)
+
+	if err := SetPasswordGeneration(true, passwords.RuleSet{}, nil); err == nil {
+		t.Errorf("SetPasswordGeneration() without an applier should fail")
+	}
+	if err := SetPasswordGeneration(false, passwords.RuleSet{"HPE": {Length: 4}}, nil); err == nil {
+		t.Errorf("SetPasswordGeneration() with bad rules should fail")
+	}
+	applier := &stubApplier{}
+	err := SetPasswordGeneration(true, passwords.RuleSet{"Gigabyte": {Length: 10, MinDigit: 10}}, applier)
+	if err != nil {
+		t.Fatalf("SetPasswordGeneration() error = %v", err)
+	}
+
+	node := vendorTestNode("x3000c0s1b0", map[string]interface{}{"BMCIPAddress": "10.254.1.10"})
+	defaults := compcredentials.CompCredentials{Xname: "x3000c0s1b0", Username: "root", Password: "default"}
+	if err := storeGeneratedPassword(context.Background(), node, defaults, "Gigabyte"); err != nil {
+		t.Fatalf("storeGeneratedPassword() error = %v", err)
+	}
+	stored, _ := compcreds.GetCompCred("x3000c0s1b0")
+	if stored.Username != "root" || len(stored.Password) != 10 || stored.Password == "default" ||
+		len(applier.applied) != 1 ||
+		applier.applied[0] != "x3000c0s1b0 10.254.1.10 root default->"+stored.Password {
+		t.Errorf("Stored %+v after applying %v", stored, applier.applied)
+	}
+	other := vendorTestNode("x3000c0s2b0", nil)
+	defaults.Xname = "x3000c0s2b0"
+	storeGeneratedPassword(context.Background(), other, defaults, "Gigabyte")
+	if again, _ := compcreds.GetCompCred("x3000c0s2b0"); again.Password == stored.Password {
+		t.Errorf("Two BMCs got the same password")
+	}
+
+	// If the BMC can't be changed nothing is stored, so it's tried again.
+	applier.applied = nil
+	applier.err = errors.New("connection refused")
+	defaults.Xname = "x3000c0s3b0"
+	if err := storeGeneratedPassword(context.Background(), vendorTestNode("x3000c0s3b0", nil), defaults, ""); err == nil {
+		t.Errorf("storeGeneratedPassword() should fail when the BMC can't be changed")
+	}
+	if c, _ := compcreds.GetCompCred("x3000c0s3b0"); c.Password != "" || !strings.HasPrefix(applier.applied[0], "x3000c0s3b0 x3000c0s3b0 root default->") {
+		t.Errorf("Stored %+v after applying %v", c, applier.applied)
+	}
+
+	// If Vault fails the BMC is put back to the default.
+	applier.applied = nil
+	applier.err = nil
+	vault.failStore = true
+	defaults.Xname = "x3000c0s4b0"
+	if err := storeGeneratedPassword(context.Background(), vendorTestNode("x3000c0s4b0", nil), defaults, ""); err == nil {
+		t.Errorf("storeGeneratedPassword() should fail when Vault does")
+	}
+	if len(applier.applied) != 2 || !strings.HasSuffix(applier.applied[1], "->default") {
+		t.Errorf("Applied %v, want the generated password then the default back", applier.applied)
+	}
+}
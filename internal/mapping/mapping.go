@@ -31,13 +31,12 @@ import (
 	"log"
 	"net/http"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Cray-HPE/hms-reds/internal/model"
-	"github.com/Cray-HPE/hms-reds/internal/smdclient"
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
 
 	base "github.com/Cray-HPE/hms-base"
 	compcredentials "github.com/Cray-HPE/hms-compcredentials"
@@ -57,7 +56,7 @@ var compcreds *compcredentials.CompCredStore
 var redsCreds *model.RedsCredStore
 var slsSleepPeriod = 30
 
-const VaultURLPrefix = "vault://"
+const VaultURLPrefix = securestore.REFERENCE_PREFIX
 const SLS_SEARCH_HARDWARE_ENDPOINT = "search/hardware"
 
 var MGMTSwitchConnectorRegex = regexp.MustCompile("^x([0-9]{1,4})c([0-7])w([0-9]+)j([1-9][0-9]*)$")
@@ -100,21 +99,24 @@ type SwitchPort struct {
 }
 
 type Switch struct {
-	Id               string `json:"id"`
-	Address          string `json:"address"`
-	SnmpUser         string `json:"snmpUser"`
-	SnmpAuthPassword string `json:"snmpAuthPassword"`
-	SnmpAuthProtocol string `json:"snmpAuthProtocol"`
-	SnmpPrivPassword string `json:"snmpPrivPassword"`
-	SnmpPrivProtocol string `json:"snmpPrivProtocol"`
-	Model            string `json:"model"`
+	Id                string `json:"id"`
+	Address           string `json:"address"`
+	SnmpUser          string `json:"snmpUser"`
+	SnmpAuthPassword  string `json:"snmpAuthPassword"`
+	SnmpAuthProtocol  string `json:"snmpAuthProtocol"`
+	SnmpPrivPassword  string `json:"snmpPrivPassword"`
+	SnmpPrivProtocol  string `json:"snmpPrivProtocol"`
+	SnmpSecurityLevel string `json:"snmpSecurityLevel"`
+	SnmpContext       string `json:"snmpContext"`
+	Model             string `json:"model"`
 }
 
 func (s Switch) String() string {
 	return fmt.Sprintf("{ Xname: %s, Model: %s, Address: %s, SNMP User: %s, "+
-		"SNMP Auth Password: <REDACTED>, SNMP Auth Protocol: %s, "+
-		"SNMP Priv Password: <REDACTED>, SNMP Priv Protocol: %s }",
-		s.Id, s.Model, s.Address, s.SnmpUser, s.SnmpAuthProtocol, s.SnmpPrivProtocol)
+		"SNMP Security Level: %s, SNMP Auth Password: <REDACTED>, SNMP Auth Protocol: %s, "+
+		"SNMP Priv Password: <REDACTED>, SNMP Priv Protocol: %s, SNMP Context: %s }",
+		s.Id, s.Model, s.Address, s.SnmpUser, s.SnmpSecurityLevel, s.SnmpAuthProtocol,
+		s.SnmpPrivProtocol, s.SnmpContext)
 }
 
 type Mapping struct {
@@ -160,7 +162,7 @@ func ConfigureSLSMode(mSlsUrl string, client *http.Client, secStorage *sstorage.
 	var err error
 	var ss sstorage.SecureStorage
 	if secStorage == nil {
-		ss, err = sstorage.NewVaultAdapter("")
+		ss, err = securestore.Connect()
 		if err != nil {
 			log.Printf("Error: %v\n", err)
 			panic(err)
@@ -171,12 +173,20 @@ func ConfigureSLSMode(mSlsUrl string, client *http.Client, secStorage *sstorage.
 	}
 
 	if ccreds == nil {
-		compcreds = compcredentials.NewCompCredStore("secret/hms-creds", ss)
+		compcreds = compcredentials.NewCompCredStore(securestore.GetConfig().HMSCredsPath(), ss)
 	} else {
 		compcreds = ccreds
 	}
 
-	redsCreds = model.NewRedsCredStore("secret/reds-creds", ss)
+	redsCreds = model.NewRedsCredStore(securestore.GetConfig().RedsCredsPath(), ss)
+	references = securestore.NewResolver(ss, securestore.DefaultReferenceTTL)
+	redsCreds.Actor = serviceName
+}
+
+// GetRedsCredStore returns the Vault store default credentials and
+// credential policies are kept in.
+func GetRedsCredStore() *model.RedsCredStore {
+	return redsCreds
 }
 
 /*
@@ -250,7 +260,7 @@ func SetStorage(ss sstorage.SecureStorage) {
 		for {
 			log.Printf("Mapping connecting to secure storage (vault)")
 			var err error
-			ss, err = sstorage.NewVaultAdapter("")
+			ss, err = securestore.Connect()
 			if err != nil {
 				log.Printf("ERROR: Secure Store connection failed - %s", err)
 				time.Sleep(5 * time.Second)
@@ -261,7 +271,8 @@ func SetStorage(ss sstorage.SecureStorage) {
 		}
 	}
 
-	compcreds = compcredentials.NewCompCredStore("secret/hms-creds", ss)
+	compcreds = compcredentials.NewCompCredStore(securestore.GetConfig().HMSCredsPath(), ss)
+	references = securestore.NewResolver(ss, securestore.DefaultReferenceTTL)
 	return
 }
 
@@ -331,96 +342,133 @@ func switchFromSLSReturn(gh GenericHardware) (*Switch, error) {
 		return nil, err
 	}
 
+	// Where each of the switch's credentials came from
+	sources := SwitchCredentialSources{
+		Switch:       gh.Xname,
+		Username:     slsCredentialSource(snmpuser),
+		AuthPassword: slsCredentialSource(snmpauthpw),
+		PrivPassword: slsCredentialSource(snmpprivpw),
+	}
+
+	// The SNMPv3 profile for this switch, from a policy or the defaults
+	defaultsCredentails, policy, profileErr := defaultSwitchCredentials(gh)
+	if profileErr != nil {
+		log.Printf("WARNING: Unable to get the SNMP profile for %s: %s", gh.Xname, profileErr)
+	}
+
 	// If we get nothing back from Vault then we need to push something in.
 	if snmpCred.SNMPAuthPass == "" || snmpCred.SNMPPrivPass == "" || snmpCred.Username == "" {
-		defaultsCredentails, err := redsCreds.GetDefaultSwitchCredentials()
-		if err != nil {
-			log.Printf("ERROR: Unable to get default switch credentials: %s", err)
+		if profileErr != nil {
+			log.Printf("ERROR: Unable to get default switch credentials: %s", profileErr)
 		} else {
+			seeded := CRED_SOURCE_DEFAULTS
+			if policy != "" {
+				log.Printf("INFO: Using credential policy %s for %s", policy, gh.Xname)
+				seeded = CRED_SOURCE_POLICY
+				sources.Policy = policy
+			}
 			snmpCred.Xname = gh.Xname
 
 			// For each of these if we're provided the value we'll trust that's what we should use,
-			// but if not use the default vaule.
-			if snmpauthpw != "" && !strings.HasPrefix(tmpSwitch.SnmpAuthPassword, VaultURLPrefix) {
-				snmpCred.SNMPAuthPass = snmpauthpw
-			} else {
-				snmpCred.SNMPAuthPass = defaultsCredentails.SNMPAuthPassword
+			// but if not use the default vaule.  HSM reads these, so a reference to another
+			// secret is seeded with what it names, as REDS uses that too.
+			snmpCred.SNMPAuthPass = seedSwitchCredential(gh.Xname, "SNMPAuthPassword", snmpauthpw,
+				defaultsCredentails.SNMPAuthPassword)
+			if snmpauthpw == "" {
+				sources.AuthPassword = seeded
 			}
 
-			if snmpprivpw != "" && !strings.HasPrefix(tmpSwitch.SnmpPrivPassword, VaultURLPrefix) {
-				snmpCred.SNMPPrivPass = snmpprivpw
-			} else {
-				snmpCred.SNMPPrivPass = defaultsCredentails.SNMPPrivPassword
+			snmpCred.SNMPPrivPass = seedSwitchCredential(gh.Xname, "SNMPPrivPassword", snmpprivpw,
+				defaultsCredentails.SNMPPrivPassword)
+			if snmpprivpw == "" {
+				sources.PrivPassword = seeded
 			}
 
-			if snmpuser != "" {
-				snmpCred.Username = snmpuser
-			} else {
-				snmpCred.Username = defaultsCredentails.SNMPUsername
-				tmpSwitch.SnmpUser = snmpCred.Username
+			snmpCred.Username = seedSwitchCredential(gh.Xname, "SNMPUsername", snmpuser,
+				defaultsCredentails.SNMPUsername)
+			if snmpuser == "" {
+				sources.Username = seeded
 			}
 
 			err = compcreds.StoreCompCred(snmpCred)
 			if err != nil {
 				log.Printf("ERROR: Unable to store credentials for switch: %s", err)
 			} else {
+				invalidateReferences(snmpCred.Xname)
 				log.Printf("INFO: Stored credential for %s", snmpCred.Xname)
 			}
 		}
 	}
 
-	if strings.HasPrefix(tmpSwitch.SnmpAuthPassword, VaultURLPrefix) {
-		tmpSwitch.SnmpAuthPassword = snmpCred.SNMPAuthPass
-	}
-	if strings.HasPrefix(tmpSwitch.SnmpPrivPassword, VaultURLPrefix) {
-		tmpSwitch.SnmpPrivPassword = snmpCred.SNMPPrivPass
-	}
+	// Resolve vault:// references to the secrets they name.  Vault also has
+	// whatever SLS leaves out.
+	tmpSwitch.SnmpUser = switchCredential(gh.Xname, "SNMPUsername", tmpSwitch.SnmpUser, snmpCred.Username)
+	tmpSwitch.SnmpAuthPassword = switchCredential(gh.Xname, "SNMPAuthPassword", tmpSwitch.SnmpAuthPassword,
+		snmpCred.SNMPAuthPass)
+	tmpSwitch.SnmpPrivPassword = switchCredential(gh.Xname, "SNMPPrivPassword", tmpSwitch.SnmpPrivPassword,
+		snmpCred.SNMPPrivPass)
+	sources.Username = finalCredentialSource(sources.Username, tmpSwitch.SnmpUser)
+	sources.AuthPassword = finalCredentialSource(sources.AuthPassword, tmpSwitch.SnmpAuthPassword)
+	sources.PrivPassword = finalCredentialSource(sources.PrivPassword, tmpSwitch.SnmpPrivPassword)
+	recordSwitchCredentialSources(sources)
+
+	applySNMPProfile(&tmpSwitch, defaultsCredentails)
 
 	return &tmpSwitch, nil
 }
 
-func GetSwitches() (*(map[string](Switch)), error) {
+// SLS types of the switches REDS knows about
+var switchTypes = []string{
+	"comptype_mgmt_switch",
+	"comptype_hl_switch",
+	"comptype_cdu_mgmt_switch",
+}
 
-	ret := make(map[string]Switch)
+// getSLSSwitchHardware returns the SLS hardware of one type of switch.
+func getSLSSwitchHardware(switchType string) ([]GenericHardware, error) {
+	log.Printf("TRACE: GET from http://" + slsURL + "/" + SLS_SEARCH_HARDWARE_ENDPOINT + "?type=" + switchType)
+	url := "http://" + slsURL + "/" + SLS_SEARCH_HARDWARE_ENDPOINT + "?type=" + switchType
+	req, qerr := http.NewRequest("GET", url, nil)
+	if qerr != nil {
+		log.Printf("WARNING: Can't create new HTTP request: %v", qerr)
+		return nil, qerr
+	}
+	base.SetHTTPUserAgent(req, serviceName)
+	resp, err := slsClient.Do(req)
 
-	switchTypes := []string{
-		"comptype_mgmt_switch",
-		"comptype_hl_switch",
-		"comptype_cdu_mgmt_switch",
+	if err != nil {
+		log.Printf("WARNING: Cannot retrieve switch list: %s", err)
+		return nil, err
 	}
 
-	for _, switchType := range switchTypes {
-		log.Printf("TRACE: GET from http://" + slsURL + "/" + SLS_SEARCH_HARDWARE_ENDPOINT + "?type=" + switchType)
-		url := "http://" + slsURL + "/" + SLS_SEARCH_HARDWARE_ENDPOINT + "?type=" + switchType
-		req, qerr := http.NewRequest("GET", url, nil)
-		if qerr != nil {
-			log.Printf("WARNING: Can't create new HTTP request: %v", qerr)
-			return nil, qerr
-		}
-		base.SetHTTPUserAgent(req, serviceName)
-		resp, err := slsClient.Do(req)
+	strbody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("WARNING: Couldn't read response body: %s", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		log.Printf("WARNING: Invalid response from SLS. Code: %d, message: %s", resp.StatusCode, strbody)
+		return nil, errors.New("SLS returned " + resp.Status)
+	}
 
-		if err != nil {
-			log.Printf("WARNING: Cannot retrieve switch list: %s", err)
-			return nil, err
-		}
+	var retGH []GenericHardware
+	// Okay, got body ok
+	err = json.Unmarshal(strbody, &retGH)
+	if err != nil {
+		log.Printf("WARNING: Unable to unmarshall response from SLS: %s", err)
+		return nil, err
+	}
+	return retGH, nil
+}
 
-		strbody, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			log.Printf("WARNING: Couldn't read response body: %s", err)
-			return nil, err
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode != 200 {
-			log.Printf("WARNING: Invalid response from SLS. Code: %d, message: %s", resp.StatusCode, strbody)
-			return nil, errors.New("SLS returned " + resp.Status)
-		}
+func GetSwitches() (*(map[string](Switch)), error) {
 
-		var retGH []GenericHardware
-		// Okay, got body ok
-		err = json.Unmarshal(strbody, &retGH)
+	ret := make(map[string]Switch)
+
+	for _, switchType := range switchTypes {
+		retGH, err := getSLSSwitchHardware(switchType)
 		if err != nil {
-			log.Printf("WARNING: Unable to unmarshall response from SLS: %s", err)
 			return nil, err
 		}
 
@@ -436,6 +484,12 @@ func GetSwitches() (*(map[string](Switch)), error) {
 		}
 	}
 
+	switches := make([]Switch, 0, len(ret))
+	for _, sw := range ret {
+		switches = append(switches, sw)
+	}
+	checkSwitchCredentials(switches)
+
 	return &ret, nil
 }
 
@@ -576,43 +630,66 @@ func OnNewMapping(cb CallbackFunc) {
 	log.Printf("INFO: Done appending %v to mapping callbacks", cb)
 }
 
+// GetManagementNodes returns the SLS nodes selected by the onboarding
+// policy, which are the River management nodes unless it says otherwise.
+//
+// Deprecated: use GetOnboardingNodes.
 func GetManagementNodes() ([]GenericHardware, error) {
-	url := fmt.Sprintf("http://%s/%s?type=comptype_node&class=River&extra_properties.Role=Management",
-		slsURL, SLS_SEARCH_HARDWARE_ENDPOINT)
-	log.Printf("TRACE: GET from %s", url)
-	req, qerr := http.NewRequest("GET", url, nil)
-	if qerr != nil {
-		log.Printf("WARNING: Can't create new HTTP request: %v", qerr)
-		return nil, qerr
-	}
-	base.SetHTTPUserAgent(req, serviceName)
+	return GetOnboardingNodes()
+}
 
-	resp, err := slsClient.Do(req)
+// GetOnboardingNodes returns the SLS nodes selected by the onboarding policy.
+func GetOnboardingNodes() ([]GenericHardware, error) {
+	policy := GetOnboardingPolicy()
 
-	if err != nil {
-		log.Printf("WARNING: Cannot retrieve management node list: %s", err)
-		return nil, err
-	}
+	var ret []GenericHardware
+	seen := make(map[string]bool)
+	for _, query := range policy.slsQueries() {
+		url := fmt.Sprintf("http://%s/%s?%s", slsURL, SLS_SEARCH_HARDWARE_ENDPOINT, query)
+		log.Printf("TRACE: GET from %s", url)
+		req, qerr := http.NewRequest("GET", url, nil)
+		if qerr != nil {
+			log.Printf("WARNING: Can't create new HTTP request: %v", qerr)
+			return nil, qerr
+		}
+		base.SetHTTPUserAgent(req, serviceName)
 
-	strbody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("WARNING: Couldn't read response body: %s", err)
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		log.Printf("WARNING: Invalid response from SLS. Code: %d, message: %s", resp.StatusCode, strbody)
-		return nil, errors.New("SLS returned " + resp.Status)
-	}
+		resp, err := slsClient.Do(req)
 
-	var retGH []GenericHardware
-	err = json.Unmarshal(strbody, &retGH)
-	if err != nil {
-		log.Printf("WARNING: Unable to unmarshall response from SLS: %s", err)
-		return nil, err
+		if err != nil {
+			log.Printf("WARNING: Cannot retrieve node list: %s", err)
+			return nil, err
+		}
+
+		// Closed before the next query rather than when we return.
+		strbody, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			log.Printf("WARNING: Couldn't read response body: %s", err)
+			return nil, err
+		}
+		if resp.StatusCode != 200 {
+			log.Printf("WARNING: Invalid response from SLS. Code: %d, message: %s", resp.StatusCode, strbody)
+			return nil, errors.New("SLS returned " + resp.Status)
+		}
+
+		var retGH []GenericHardware
+		err = json.Unmarshal(strbody, &retGH)
+		if err != nil {
+			log.Printf("WARNING: Unable to unmarshall response from SLS: %s", err)
+			return nil, err
+		}
+
+		for _, gh := range retGH {
+			if seen[gh.Xname] || !policy.Matches(gh) {
+				continue
+			}
+			seen[gh.Xname] = true
+			ret = append(ret, gh)
+		}
 	}
 
-	return retGH, nil
+	return ret, nil
 }
 
 func GetConnectorsByBMC(xname string) ([]GenericHardware, error) {
@@ -655,12 +732,14 @@ func GetConnectorsByBMC(xname string) ([]GenericHardware, error) {
 }
 
 /*
-Look for new management nodes appearing in SLS by periodically querying node list and comparing.
+Look for new nodes appearing in SLS by periodically querying node list and comparing.
+Which nodes are onboarded is decided by the onboarding policy, which defaults to
+River management nodes.  New nodes are onboarded concurrently, see onboard.go.
 */
 func WatchSLSNewManagementNodes(quitChan chan bool) {
 	// In the interest of not hammering HSM with queries to figure out what it currently knows about, just use a
 	// local cache of the nodes that we've told HSM about.
-	managementNodes := make(map[string]GenericHardware)
+	o := newOnboarder()
 
 	ticker := time.NewTicker(time.Duration(slsSleepPeriod) * time.Second)
 	for {
@@ -670,108 +749,18 @@ func WatchSLSNewManagementNodes(quitChan chan bool) {
 			ticker.Stop()
 			return
 		case <-ticker.C:
-			log.Printf("TRACE: Getting list of new management nodes")
-			newNodes, err := GetManagementNodes()
+			o.checkDiscovery()
+
+			log.Printf("TRACE: Getting list of new nodes")
+			newNodes, err := GetOnboardingNodes()
 			if err != nil {
 				log.Printf("WARNING: Unable to get new node list: %s", err)
 				continue
 			}
 
-			for _, node := range newNodes {
-				// The xname field is the node iself, we actually care about the parent which is the BMC.
-				if _, ok := managementNodes[node.Parent]; ok {
-					// Node already exists.
-					continue
-				}
-
-				log.Printf("INFO: Found new management node %+v", node)
-
-				conns, err := GetConnectorsByBMC(node.Parent)
-				if err != nil {
-					log.Printf("ERROR: Unable to get node connector info from SLS, not adding "+
-						"nodes in %s for now.", node.Parent)
-					continue
-				}
-
-				// First check to see if there are credentials in Vault for this xname. If there are we won't
-				// re-set them in case they've been changed from the defaults.
-				credentials, err := compcreds.GetCompCred(node.Parent)
-				if err != nil {
-					log.Printf("ERROR: Unable to check Vault for xname credentials, not adding "+
-						"node %s for now.", node.Parent)
-					continue
-				}
-
-				if credentials.Username == "" || credentials.Password == "" {
-					defaultCreds, err := redsCreds.GetDefaultCredentials()
-					if err != nil {
-						log.Printf("ERROR: Unable to get defualt credentials, not adding node %s for now.",
-							node.Parent)
-						continue
-					}
-
-					credentials := compcredentials.CompCredentials{
-						Xname:    node.Parent,
-						Username: defaultCreds["Cray"].Username,
-						Password: defaultCreds["Cray"].Password,
-					}
-
-					err = compcreds.StoreCompCred(credentials)
-					if err != nil {
-						log.Printf("ERROR: Unable to set credentials, not adding node %s for now.",
-							node.Parent)
-						continue
-					} else {
-						log.Printf("DEBUG: Set credentials for %s", node.Parent)
-					}
-				}
-
-				// Add Master Management nodes to HSM under /State/Components
-				// to account for cases where their BMC is not connected to
-				// the cluster. These nodes will not have MgmtSwitchConnectors
-				if len(conns) == 0 {
-					var (
-						role    string
-						subrole string
-						nid     json.Number
-					)
-					if val, ok := node.ExtraPropertiesRaw.(map[string]interface{})["Role"]; ok {
-						role = base.VerifyNormalizeRole(val.(string))
-					}
-					if val, ok := node.ExtraPropertiesRaw.(map[string]interface{})["SubRole"]; ok {
-						subrole = base.VerifyNormalizeSubRole(val.(string))
-					}
-					if role == base.RoleManagement.String() && subrole == base.SubRoleMaster.String() {
-						if val, ok := node.ExtraPropertiesRaw.(map[string]interface{})["NID"]; ok {
-							nid = json.Number(strconv.FormatFloat(val.(float64), 'f', 0, 64))
-						}
-						hsmCompNotification := smdclient.HSMCompNotification{
-							Components: []base.Component{{
-								ID:      node.Xname,
-								State:   base.StatePopulated.String(),
-								Role:    role,
-								SubRole: subrole,
-								NID:     nid,
-								NetType: base.NetSling.String(),
-								Arch:    base.ArchX86.String(),
-								Class:   node.Class,
-							}},
-						}
-						smdclient.HSMCreateComponent(hsmCompNotification)
-					}
-				}
-
-				// Now build the HSM notification and send it.
-				hsmNotification := smdclient.HSMNotification{
-					ID:                 node.Parent,
-					RediscoverOnUpdate: true,
-				}
-
-				added := smdclient.NotifyHSMDiscoveredWithGeolocation(hsmNotification)
-				if added {
-					// Now add this node to the cache map so we don't send it again.
-					managementNodes[node.Parent] = node
-				}
+			summary := o.runCycle(newNodes)
+			if summary.New > 0 {
+				log.Printf("INFO: Onboarding cycle: %s", summary)
 			}
 		}
 	}
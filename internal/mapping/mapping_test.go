@@ -271,6 +271,7 @@ func BaseRTFunc(r *http.Request) *http.Response {
 			return &http.Response{
 				StatusCode: 200,
 				// Send mock response for rpath
+				Body:   ioutil.NopCloser(bytes.NewBufferString("[]")),
 				Header: make(http.Header),
 			}
 		case "parent=x0c0w0&type=comptype_mgmt_switch_connector":
@@ -625,6 +626,9 @@ func TimedSwitchesRTFunc(r *http.Request) *http.Response {
 	log.Printf("TimedSwitchesRTFunc called, number %d", TimedSwitchHitCount)
 	switch r.URL.Path {
 	case "/" + SLS_BASE_VERSION + "/" + SLS_SEARCH_HARDWARE_ENDPOINT:
+		if r.URL.Query().Get("type") != "comptype_mgmt_switch" {
+			break
+		}
 		TimedSwitchHitCount++
 		if TimedSwitchHitCount == 2 {
 			log.Printf("TimedSwitchesRTFunc returns, number %d", TimedSwitchHitCount)
@@ -0,0 +1,194 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package mapping
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	base "github.com/Cray-HPE/hms-base"
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
+)
+
+// Outcomes of migrating a switch's SNMP passwords out of SLS
+const (
+	MIGRATION_MIGRATED = "Migrated"
+	MIGRATION_PLANNED  = "Planned"
+	MIGRATION_FAILED   = "Failed"
+)
+
+// The SLS ExtraProperties that may hold SNMP passwords
+var slsSNMPPasswordProperties = []string{"SNMPAuthPassword", "SNMPPrivPassword"}
+
+// SwitchMigration is what migrating a switch's plaintext SNMP passwords
+// did, or would do in a dry run.
+type SwitchMigration struct {
+	Switch string `json:"switch"`
+	// The SLS properties moved to Vault
+	Properties []string `json:"properties"`
+	// Whether Vault had other passwords, which SLS's replace.  REDS has
+	// been using the ones in SLS.
+	ReplacedVault bool   `json:"replacedVault"`
+	Result        string `json:"result"`
+	Error         string `json:"error,omitempty"`
+}
+
+// switchVaultReference returns the vault:// reference SLS gets in place of
+// a switch's passwords.
+func switchVaultReference(xname string) string {
+	return VaultURLPrefix + securestore.GetConfig().HMSCreds + "/" + xname
+}
+
+// putSLSHardware replaces a piece of SLS hardware.
+func putSLSHardware(gh GenericHardware) error {
+	body, err := json.Marshal(struct {
+		Parent          string      `json:"Parent"`
+		Xname           string      `json:"Xname"`
+		Class           string      `json:"Class"`
+		ExtraProperties interface{} `json:"ExtraProperties"`
+	}{gh.Parent, gh.Xname, gh.Class, gh.ExtraPropertiesRaw})
+	if err != nil {
+		return err
+	}
+	url := "http://" + slsURL + "/hardware/" + gh.Xname
+	log.Printf("TRACE: PUT to %s", url)
+	req, err := http.NewRequest("PUT", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	base.SetHTTPUserAgent(req, serviceName)
+	resp, err := slsClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		strbody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("SLS returned %s: %s", resp.Status, strbody)
+	}
+	return nil
+}
+
+// migrateSwitch moves the plaintext SNMP passwords of one switch from SLS
+// to Vault, reads them back and then replaces them in SLS with vault://
+// references.  SLS is left alone if Vault doesn't have them.
+func migrateSwitch(gh GenericHardware, dryRun bool) (SwitchMigration, bool) {
+	m := SwitchMigration{Switch: gh.Xname, Properties: []string{}}
+	props, ok := gh.ExtraPropertiesRaw.(map[string]interface{})
+	if !ok {
+		return m, false
+	}
+	plaintext := make(map[string]string)
+	for _, prop := range slsSNMPPasswordProperties {
+		value, _ := props[prop].(string)
+		if slsCredentialSource(value) == CRED_SOURCE_SLS {
+			plaintext[prop] = value
+			m.Properties = append(m.Properties, prop)
+		}
+	}
+	if len(plaintext) == 0 {
+		return m, false
+	}
+
+	fail := func(err error) (SwitchMigration, bool) {
+		m.Result = MIGRATION_FAILED
+		m.Error = err.Error()
+		log.Printf("ERROR: Unable to migrate the SNMP passwords of %s: %s", gh.Xname, err)
+		return m, true
+	}
+
+	cred, err := compcreds.GetCompCred(gh.Xname)
+	if err != nil {
+		return fail(fmt.Errorf("unable to get its credentials from Vault: %s", err))
+	}
+	cred.Xname = gh.Xname
+	if cred.Username == "" {
+		// A reference is resolved like the passwords are when seeding Vault;
+		// the reference itself is no username.
+		username, _ := props["SNMPUsername"].(string)
+		cred.Username = seedSwitchCredential(gh.Xname, "SNMPUsername", username, "")
+		if cred.Username == "" && username != "" {
+			log.Printf("WARNING: The SNMPUsername of %s in SLS doesn't resolve, not storing one in Vault",
+				gh.Xname)
+		}
+	}
+	if value, ok := plaintext["SNMPAuthPassword"]; ok {
+		m.ReplacedVault = m.ReplacedVault || (cred.SNMPAuthPass != "" && cred.SNMPAuthPass != value)
+		cred.SNMPAuthPass = value
+	}
+	if value, ok := plaintext["SNMPPrivPassword"]; ok {
+		m.ReplacedVault = m.ReplacedVault || (cred.SNMPPrivPass != "" && cred.SNMPPrivPass != value)
+		cred.SNMPPrivPass = value
+	}
+	if dryRun {
+		m.Result = MIGRATION_PLANNED
+		return m, true
+	}
+
+	if err := compcreds.StoreCompCred(cred); err != nil {
+		return fail(fmt.Errorf("unable to store its credentials in Vault: %s", err))
+	}
+	invalidateReferences(gh.Xname)
+	stored, err := compcreds.GetCompCred(gh.Xname)
+	if err != nil {
+		return fail(fmt.Errorf("unable to read its credentials back from Vault: %s", err))
+	}
+	if stored.SNMPAuthPass != cred.SNMPAuthPass || stored.SNMPPrivPass != cred.SNMPPrivPass {
+		return fail(errors.New("Vault doesn't have the passwords just stored"))
+	}
+
+	for prop := range plaintext {
+		props[prop] = switchVaultReference(gh.Xname)
+	}
+	if err := putSLSHardware(gh); err != nil {
+		return fail(fmt.Errorf("stored in Vault but SLS wasn't updated: %s", err))
+	}
+	m.Result = MIGRATION_MIGRATED
+	log.Printf("INFO: Moved the SNMP passwords of %s from SLS to Vault", gh.Xname)
+	return m, true
+}
+
+// MigrateSwitchPasswords moves the SNMP passwords SLS has in plaintext to
+// Vault and replaces them in SLS with vault:// references.  With dryRun
+// nothing is changed and the switches that would be are reported as
+// planned.  Only switches with plaintext passwords are returned.
+func MigrateSwitchPasswords(dryRun bool) ([]SwitchMigration, error) {
+	ret := []SwitchMigration{}
+	for _, switchType := range switchTypes {
+		hardware, err := getSLSSwitchHardware(switchType)
+		if err != nil {
+			return ret, err
+		}
+		for _, gh := range hardware {
+			if m, ok := migrateSwitch(gh, dryRun); ok {
+				ret = append(ret, m)
+			}
+		}
+	}
+	return ret, nil
+}
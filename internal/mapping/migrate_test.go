@@ -0,0 +1,205 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package mapping
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/model"
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
+)
+
+// slsMock serves SLS switch hardware and takes updates to it.
+type slsMock struct {
+	sync.Mutex
+	hardware map[string]map[string]interface{}
+	puts     int
+}
+
+func (s *slsMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/"+SLS_SEARCH_HARDWARE_ENDPOINT:
+		ret := []map[string]interface{}{}
+		if r.URL.Query().Get("type") == "comptype_mgmt_switch" {
+			for xname, props := range s.hardware {
+				ret = append(ret, map[string]interface{}{"Parent": "x3000", "Xname": xname,
+					"Class": "River", "ExtraProperties": props})
+			}
+		}
+		json.NewEncoder(w).Encode(ret)
+	case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/hardware/"):
+		s.puts++
+		var gh struct {
+			Xname           string
+			Class           string
+			ExtraProperties map[string]interface{}
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &gh); err != nil || gh.Class == "" ||
+			gh.Xname != strings.TrimPrefix(r.URL.Path, "/hardware/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.hardware[gh.Xname] = gh.ExtraProperties
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newSLSMock(t *testing.T) *slsMock {
+	sls := &slsMock{hardware: map[string]map[string]interface{}{
+		"x3000c0w14": {"IP4addr": "10.1.1.14", "SNMPUsername": "testuser",
+			"SNMPAuthPassword": "plainauth", "SNMPPrivPassword": "vault://hms-creds/x3000c0w14"},
+		"x3000c0w15": {"IP4addr": "10.1.1.15", "SNMPUsername": "testuser",
+			"SNMPAuthPassword": "vault://hms-creds/x3000c0w15", "SNMPPrivPassword": "vault://hms-creds/x3000c0w15"},
+		"x3000c0w16": {"IP4addr": "10.1.1.16"},
+	}}
+	server := httptest.NewServer(sls)
+	t.Cleanup(server.Close)
+
+	savedURL, savedClient, savedCompcreds, savedRedsCreds, savedReferences :=
+		slsURL, slsClient, compcreds, redsCreds, references
+	t.Cleanup(func() {
+		slsURL, slsClient, compcreds, redsCreds, references =
+			savedURL, savedClient, savedCompcreds, savedRedsCreds, savedReferences
+	})
+	slsURL = strings.TrimPrefix(server.URL, "http://")
+	slsClient = *server.Client()
+	vault := &vaultMock{data: make(map[string][]byte)}
+	compcreds = compcredentials.NewCompCredStore("secret/hms-creds", vault)
+	redsCreds = model.NewRedsCredStore("secret/reds-creds", vault)
+	references = securestore.NewResolver(vault, securestore.DefaultReferenceTTL)
+	redsCreds.StoreDefaultSwitchCredentials(model.SwitchCredentials{SNMPUsername: "default",
+		SNMPAuthPassword: "defaultauth", SNMPPrivPassword: "defaultpriv"})
+	compcreds.StoreCompCred(compcredentials.CompCredentials{Xname: "x3000c0w14", Username: "testuser",
+		SNMPAuthPass: "oldauth", SNMPPrivPass: "vaultpriv"})
+	compcreds.StoreCompCred(compcredentials.CompCredentials{Xname: "x3000c0w15", Username: "testuser",
+		SNMPAuthPass: "vaultauth", SNMPPrivPass: "vaultpriv"})
+	return sls
+}
+
+func Test_switchCredentialSources(t *testing.T) {
+	newSLSMock(t)
+	if _, err := GetSwitches(); err != nil {
+		t.Fatalf("GetSwitches() error = %v", err)
+	}
+
+	want := map[string]SwitchCredentialSources{
+		"x3000c0w14": {Username: CRED_SOURCE_SLS, AuthPassword: CRED_SOURCE_SLS,
+			PrivPassword: CRED_SOURCE_VAULT_REFERENCE, Plaintext: true},
+		"x3000c0w15": {Username: CRED_SOURCE_SLS, AuthPassword: CRED_SOURCE_VAULT_REFERENCE,
+			PrivPassword: CRED_SOURCE_VAULT_REFERENCE},
+		"x3000c0w16": {Username: CRED_SOURCE_DEFAULTS, AuthPassword: CRED_SOURCE_DEFAULTS,
+			PrivPassword: CRED_SOURCE_DEFAULTS},
+	}
+	got := make(map[string]SwitchCredentialSources)
+	for _, sources := range GetSwitchCredentialSources(false) {
+		got[sources.Switch] = sources
+	}
+	for xname, w := range want {
+		g := got[xname]
+		if g.Username != w.Username || g.AuthPassword != w.AuthPassword || g.PrivPassword != w.PrivPassword ||
+			g.Plaintext != w.Plaintext {
+			t.Errorf("Sources of %s = %+v, want %+v", xname, g, w)
+		}
+	}
+
+	plaintext := GetSwitchCredentialSources(true)
+	if len(plaintext) != 1 || plaintext[0].Switch != "x3000c0w14" {
+		t.Errorf("GetSwitchCredentialSources(true) = %+v", plaintext)
+	}
+}
+
+func TestMigrateSwitchPasswords(t *testing.T) {
+	sls := newSLSMock(t)
+
+	migrations, err := MigrateSwitchPasswords(true)
+	if err != nil || len(migrations) != 1 || migrations[0].Switch != "x3000c0w14" ||
+		migrations[0].Result != MIGRATION_PLANNED || !migrations[0].ReplacedVault ||
+		strings.Join(migrations[0].Properties, ",") != "SNMPAuthPassword" {
+		t.Fatalf("MigrateSwitchPasswords(true) = %+v, %v", migrations, err)
+	}
+	if cred, _ := compcreds.GetCompCred("x3000c0w14"); sls.puts != 0 || cred.SNMPAuthPass != "oldauth" {
+		t.Fatalf("A dry run changed something: %d SLS updates, Vault has %s", sls.puts, cred.SNMPAuthPass)
+	}
+
+	migrations, err = MigrateSwitchPasswords(false)
+	if err != nil || len(migrations) != 1 || migrations[0].Result != MIGRATION_MIGRATED {
+		t.Fatalf("MigrateSwitchPasswords(false) = %+v, %v", migrations, err)
+	}
+	if cred, _ := compcreds.GetCompCred("x3000c0w14"); cred.SNMPAuthPass != "plainauth" || cred.SNMPPrivPass != "vaultpriv" {
+		t.Errorf("Vault has %+v after migrating", cred)
+	}
+	if props := sls.hardware["x3000c0w14"]; props["SNMPAuthPassword"] != "vault://hms-creds/x3000c0w14" ||
+		props["IP4addr"] != "10.1.1.14" {
+		t.Errorf("SLS has %v after migrating", props)
+	}
+
+	// Nothing left to do
+	if migrations, err = MigrateSwitchPasswords(false); err != nil || len(migrations) != 0 || sls.puts != 1 {
+		t.Errorf("MigrateSwitchPasswords() again = %+v, %v, %d SLS updates", migrations, err, sls.puts)
+	}
+}
+
+func TestMigrateSwitchPasswords_vaultFails(t *testing.T) {
+	sls := newSLSMock(t)
+	compcreds = compcredentials.NewCompCredStore("secret/hms-creds", &vaultMock{data: make(map[string][]byte),
+		failStore: true})
+
+	migrations, err := MigrateSwitchPasswords(false)
+	if err != nil || len(migrations) != 1 || migrations[0].Result != MIGRATION_FAILED || migrations[0].Error == "" {
+		t.Fatalf("MigrateSwitchPasswords() = %+v, %v", migrations, err)
+	}
+	if sls.puts != 0 || sls.hardware["x3000c0w14"]["SNMPAuthPassword"] != "plainauth" {
+		t.Errorf("SLS was changed although Vault failed")
+	}
+}
+
+func TestMigrateSwitchPasswords_usernameReference(t *testing.T) {
+	sls := newSLSMock(t)
+	sls.hardware["x3000c0w17"] = map[string]interface{}{"IP4addr": "10.1.1.17",
+		"SNMPUsername": "vault://hms-creds/x3000c0w15", "SNMPAuthPassword": "plainauth"}
+	sls.hardware["x3000c0w18"] = map[string]interface{}{"IP4addr": "10.1.1.18",
+		"SNMPUsername": "vault://hms-creds/x3000c0w99", "SNMPAuthPassword": "plainauth"}
+
+	if _, err := MigrateSwitchPasswords(false); err != nil {
+		t.Fatalf("MigrateSwitchPasswords() error = %v", err)
+	}
+	if cred, _ := compcreds.GetCompCred("x3000c0w17"); cred.Username != "testuser" || cred.SNMPAuthPass != "plainauth" {
+		t.Errorf("Vault has %+v for a switch whose username is a reference", cred)
+	}
+	if cred, _ := compcreds.GetCompCred("x3000c0w18"); cred.Username != "" || cred.SNMPAuthPass != "plainauth" {
+		t.Errorf("Vault has %+v for a switch whose username reference doesn't resolve", cred)
+	}
+	if props := sls.hardware["x3000c0w17"]; props["SNMPUsername"] != "vault://hms-creds/x3000c0w15" {
+		t.Errorf("SLS has %v after migrating", props)
+	}
+}
@@ -0,0 +1,101 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Node states reported by GetNodeStatuses
+const (
+	NODE_STATE_REGISTRATION_FAILED = "RegistrationFailed"
+	NODE_STATE_DISCOVERING         = "Discovering"
+	NODE_STATE_DISCOVERED          = "Discovered"
+	NODE_STATE_DISCOVERY_FAILED    = "DiscoveryFailed"
+	// Another service has the BMC locked or reserved in HSM
+	NODE_STATE_DEFERRED = "Deferred"
+)
+
+// NodeStatus is what REDS knows about onboarding a node's BMC.
+type NodeStatus struct {
+	BMC  string `json:"bmc"`
+	Node string `json:"node"`
+	// From SLS; HSM components have nowhere to keep them
+	Aliases         []string `json:"aliases,omitempty"`
+	State           string   `json:"state"`
+	DiscoveryStatus string   `json:"discoveryStatus,omitempty"`
+	Rediscoveries   int      `json:"rediscoveries"`
+	// The credential policy or vendor defaults REDS seeded the BMC with, and
+	// how the vendor was chosen; empty if the BMC already had credentials
+	CredentialPolicy string `json:"credentialPolicy,omitempty"`
+	CredentialVendor string `json:"credentialVendor,omitempty"`
+	VendorSource     string `json:"vendorSource,omitempty"`
+	// Whether the BMC was given a generated password of its own
+	PasswordGenerated bool `json:"passwordGenerated,omitempty"`
+	// Whether the BMC took its credentials when last checked
+	CredentialCheck      string `json:"credentialCheck,omitempty"`
+	CredentialCheckError string `json:"credentialCheckError,omitempty"`
+	Error                string `json:"error,omitempty"`
+	Updated              string `json:"updated"`
+}
+
+var nodeStatuses = make(map[string]NodeStatus)
+var nodeStatusLock sync.Mutex
+
+// setNodeStatus changes the status of a node's BMC with update.
+func setNodeStatus(node GenericHardware, update func(status *NodeStatus)) {
+	nodeStatusLock.Lock()
+	defer nodeStatusLock.Unlock()
+	status := nodeStatuses[node.Parent]
+	status.BMC = node.Parent
+	status.Node = node.Xname
+	status.Aliases = slsAliases(node)
+	update(&status)
+	status.Updated = time.Now().Format(time.RFC3339)
+	nodeStatuses[node.Parent] = status
+}
+
+// GetNodeStatuses returns the status of every BMC REDS has tried to onboard,
+// ordered by BMC xname.
+func GetNodeStatuses() []NodeStatus {
+	nodeStatusLock.Lock()
+	defer nodeStatusLock.Unlock()
+	statuses := make([]NodeStatus, 0, len(nodeStatuses))
+	for _, status := range nodeStatuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].BMC < statuses[j].BMC
+	})
+	return statuses
+}
+
+// GetNodeStatus returns the status of one BMC.
+func GetNodeStatus(bmc string) (NodeStatus, bool) {
+	nodeStatusLock.Lock()
+	defer nodeStatusLock.Unlock()
+	status, ok := nodeStatuses[bmc]
+	return status, ok
+}
@@ -0,0 +1,676 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base"
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+
+	"github.com/Cray-HPE/hms-reds/internal/smdclient"
+)
+
+// Number of BMCs onboarded in parallel and how long each node may take
+var onboardWorkers = 10
+var onboardNodeTimeout = 60 * time.Second
+
+// Size of the onboarding job queue.  Jobs that don't fit wait for room.
+const onboardQueueSize = 1024
+
+const JTYPE_ONBOARD base.JobType = 1
+
+var ErrOnboardTimeout = errors.New("timed out onboarding node")
+
+// How often and how patiently transient HSM failures are retried
+var hsmRetries = 3
+var hsmRetryDelay = 2 * time.Second
+
+// SLS node ExtraProperties holding the address of the node's BMC, if known
+const SLS_BMC_MAC_PROPERTY = "BMCMACAddress"
+const SLS_BMC_IP_PROPERTY = "BMCIPAddress"
+
+// Number of endpoints or components sent to HSM in one request
+var hsmBatchSize = smdclient.DefaultBatchSize
+
+// The pool is shared by every run of the node watcher; base.WorkerPool
+// workers can't be stopped, so a pool per run would leak them each time
+// leadership changes hands.
+var onboardPool *base.WorkerPool
+var onboardPoolOnce sync.Once
+
+func getOnboardPool() *base.WorkerPool {
+	onboardPoolOnce.Do(func() {
+		onboardPool = base.NewWorkerPool(onboardWorkers, onboardQueueSize)
+		onboardPool.Run()
+	})
+	return onboardPool
+}
+
+// SetOnboardingConcurrency sets how many BMCs are onboarded in parallel and
+// the deadline for onboarding a single node.  Must be called before the node
+// watcher first starts.
+func SetOnboardingConcurrency(workers int, nodeTimeout time.Duration) {
+	if workers > 0 {
+		onboardWorkers = workers
+	}
+	if nodeTimeout > 0 {
+		onboardNodeTimeout = nodeTimeout
+	}
+}
+
+// SetHSMBatchSize sets how many endpoints or components are sent to HSM in
+// one bulk request.
+func SetHSMBatchSize(size int) {
+	if size > 0 {
+		hsmBatchSize = size
+	}
+}
+
+// OnboardingSummary describes one pass of the node watcher.
+type OnboardingSummary struct {
+	Started  string `json:"started"`
+	Duration string `json:"duration"`
+	Nodes    int    `json:"nodes"`
+	New      int    `json:"new"`
+	Added    int    `json:"added"`
+	Failed   int    `json:"failed"`
+	TimedOut int    `json:"timedOut"`
+	Skipped  int    `json:"skipped"`
+	// Nodes left alone because another service has them locked in HSM
+	Deferred int `json:"deferred"`
+	// Endpoints HSM already had that differed from what REDS knows
+	Updated []smdclient.EndpointUpdate `json:"updated,omitempty"`
+}
+
+func (s OnboardingSummary) String() string {
+	return fmt.Sprintf("%d nodes, %d new: %d added (%d updated), %d failed, %d timed out, %d skipped, %d deferred in %s",
+		s.Nodes, s.New, s.Added, len(s.Updated), s.Failed, s.TimedOut, s.Skipped, s.Deferred, s.Duration)
+}
+
+var lastSummary OnboardingSummary
+var summaryLock sync.Mutex
+
+// GetLastOnboardingSummary returns the summary of the most recent watcher pass.
+func GetLastOnboardingSummary() OnboardingSummary {
+	summaryLock.Lock()
+	defer summaryLock.Unlock()
+	return lastSummary
+}
+
+// onboarder holds the state the node watcher keeps between passes.
+type onboarder struct {
+	pool *base.WorkerPool
+
+	lock sync.Mutex
+	// BMCs we've told HSM about, keyed by BMC xname
+	nodes map[string]GenericHardware
+	// BMCs with a node still being worked on, possibly by an attempt that
+	// already timed out.  Nothing else may touch them until it finishes.
+	inFlight map[string]bool
+	// Registered BMCs HSM hasn't finished discovering
+	discovering map[string]*discovery
+	// Components REDS created, keyed by node xname, as last sent to HSM
+	components map[string]base.Component
+	// BMC MACs still to be recorded in HSM, keyed by BMC xname
+	macs map[string]smdclient.HSMNotification
+}
+
+func newOnboarder() *onboarder {
+	return &onboarder{
+		pool:        getOnboardPool(),
+		nodes:       make(map[string]GenericHardware),
+		inFlight:    make(map[string]bool),
+		discovering: make(map[string]*discovery),
+		components:  make(map[string]base.Component),
+		macs:        make(map[string]smdclient.HSMNotification),
+	}
+}
+
+// Prepares a single node; replaced in tests.
+var prepareNodeFunc = prepareNode
+
+// runCycle onboards every node not yet known to HSM.  Nodes are grouped by
+// BMC; groups are prepared in parallel on the worker pool while the nodes of
+// a group are handled one at a time, in SLS order.  Once every group is done
+// the prepared BMCs are registered with HSM in bulk, followed by the BMC MACs
+// that are new or couldn't be recorded on an earlier pass.
+func (o *onboarder) runCycle(nodes []GenericHardware) OnboardingSummary {
+	start := time.Now()
+	summary := OnboardingSummary{
+		Started: start.Format(time.RFC3339),
+		Nodes:   len(nodes),
+	}
+
+	var order []string
+	var changed []base.Component
+	position := make(map[string]int)
+	groups := make(map[string][]GenericHardware)
+	o.lock.Lock()
+	for _, node := range nodes {
+		// The xname field is the node iself, we actually care about the parent which is the BMC.
+		if cached, ok := o.nodes[node.Parent]; ok {
+			// SLS may have a new MAC for the BMC, e.g. because it was replaced.
+			if cached.Xname == node.Xname && extraPropertyString(cached, SLS_BMC_MAC_PROPERTY) !=
+				extraPropertyString(node, SLS_BMC_MAC_PROPERTY) {
+				o.nodes[node.Parent] = node
+				if mac := bmcMAC(node); mac != "" {
+					log.Printf("INFO: SLS has a new BMC MAC %s for %s", mac, node.Parent)
+					o.macs[node.Parent] = bmcInterface(node)
+				}
+			}
+			// Node already exists, but SLS may have changed its component.
+			if cached, ok := o.components[node.Xname]; ok {
+				comp, err := componentFromSLS(node)
+				if err != nil {
+					log.Printf("ERROR: Not updating component for %s: %s", node.Xname, err)
+				} else if !reflect.DeepEqual(comp, cached) {
+					changed = append(changed, comp)
+				}
+			}
+			continue
+		}
+		summary.New++
+		if o.inFlight[node.Parent] {
+			log.Printf("INFO: Still working on %s from a previous pass, skipping %s",
+				node.Parent, node.Xname)
+			summary.Skipped++
+			continue
+		}
+		if _, ok := groups[node.Parent]; !ok {
+			position[node.Parent] = len(order)
+			order = append(order, node.Parent)
+		}
+		groups[node.Parent] = append(groups[node.Parent], node)
+	}
+	o.lock.Unlock()
+
+	if len(changed) > 0 {
+		o.syncComponents(changed)
+	}
+
+	var wg sync.WaitGroup
+	var summaryMutex sync.Mutex
+	var regs []*nodeRegistration
+	for _, bmc := range order {
+		job := &onboardJob{
+			bmc:   bmc,
+			nodes: groups[bmc],
+		}
+		job.run = func(j *onboardJob) {
+			defer wg.Done()
+			reg, failed, timedOut, skipped := o.onboardBMC(j.bmc, j.nodes)
+			summaryMutex.Lock()
+			if reg != nil {
+				regs = append(regs, reg)
+			}
+			summary.Failed += failed
+			summary.TimedOut += timedOut
+			summary.Skipped += skipped
+			summaryMutex.Unlock()
+		}
+		wg.Add(1)
+		for o.pool.Queue(job) != 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	wg.Wait()
+
+	// Keep the requests in SLS order however the jobs finished.
+	sort.SliceStable(regs, func(i, j int) bool {
+		return position[regs[i].node.Parent] < position[regs[j].node.Parent]
+	})
+	added, failed, deferred, updated := o.register(regs)
+	summary.Added += added
+	summary.Failed += failed
+	summary.Deferred += deferred
+	summary.Updated = updated
+	o.registerMACs()
+
+	summary.Duration = time.Since(start).Round(time.Millisecond).String()
+	summaryLock.Lock()
+	lastSummary = summary
+	summaryLock.Unlock()
+	return summary
+}
+
+// onboardBMC works through the nodes of one BMC in order until one of them
+// is ready to be registered with HSM.  A node that times out leaves the rest
+// of the BMC's nodes for the next pass, so they never overtake it.
+func (o *onboarder) onboardBMC(bmc string, nodes []GenericHardware) (reg *nodeRegistration, failed, timedOut, skipped int) {
+	for i, node := range nodes {
+		o.lock.Lock()
+		o.inFlight[bmc] = true
+		o.lock.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), onboardNodeTimeout)
+		type result struct {
+			reg *nodeRegistration
+			err error
+		}
+		done := make(chan result, 1)
+		go func(node GenericHardware) {
+			reg, err := prepareNodeFunc(ctx, node)
+			o.lock.Lock()
+			delete(o.inFlight, bmc)
+			o.lock.Unlock()
+			done <- result{reg, err}
+		}(node)
+
+		var res result
+		select {
+		case res = <-done:
+		case <-ctx.Done():
+			res.err = ErrOnboardTimeout
+		}
+		cancel()
+
+		switch {
+		case res.err == nil:
+			skipped += len(nodes) - i - 1
+			return res.reg, failed, timedOut, skipped
+		case errors.Is(res.err, ErrOnboardTimeout) || errors.Is(res.err, context.DeadlineExceeded):
+			log.Printf("ERROR: Timed out after %s onboarding %s, will retry next pass",
+				onboardNodeTimeout, node.Xname)
+			timedOut++
+			skipped += len(nodes) - i - 1
+			return
+		default:
+			failed++
+		}
+	}
+	return
+}
+
+// register tells HSM about the prepared nodes in bulk: first the components
+// HSM can't discover itself, then the BMC endpoints.  The MACs of the BMCs
+// registered are left for registerMACs.  A component that can't be
+// registered is logged but doesn't hold back its BMC.  BMCs that couldn't be
+// registered are retried next pass.  Endpoints HSM already had that needed changes, or had
+// protected fields REDS left alone, are returned.
+func (o *onboarder) register(regs []*nodeRegistration) (added, failed, deferred int, updated []smdclient.EndpointUpdate) {
+	if len(regs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), onboardNodeTimeout)
+	defer cancel()
+
+	var comps []base.Component
+	for _, reg := range regs {
+		if reg.component != nil {
+			comps = append(comps, *reg.component)
+		}
+	}
+	if len(comps) > 0 {
+		// Not fatal if these fail, the BMCs can still be registered.
+		o.syncComponents(comps)
+	}
+
+	results := retryBulk(ctx, "registering endpoints", len(regs),
+		func(pending []int) []smdclient.RegistrationResult {
+			batch := make([]smdclient.HSMNotification, len(pending))
+			for i, n := range pending {
+				batch[i] = regs[n].endpoint
+			}
+			return smdclient.RegisterRedfishEndpoints(batch, hsmBatchSize)
+		})
+	o.lock.Lock()
+	for i, res := range results {
+		reg := regs[i]
+		if smdclient.IsLocked(res.Err) {
+			// Not cached, so it's tried again once the lock is released.
+			log.Printf("INFO: Deferring %s: %s", reg.endpoint.ID, res.Err)
+			setNodeStatus(reg.node, func(status *NodeStatus) {
+				status.State = NODE_STATE_DEFERRED
+				status.Error = res.Err.Error()
+			})
+			deferred++
+			continue
+		}
+		if res.Err != nil {
+			if !smdclient.IsRetryable(res.Err) {
+				log.Printf("ERROR: HSM rejected %s: %s", reg.endpoint.ID, res.Err)
+			}
+			setNodeStatus(reg.node, func(status *NodeStatus) {
+				status.State = NODE_STATE_REGISTRATION_FAILED
+				status.Error = res.Err.Error()
+			})
+			failed++
+			continue
+		}
+		if res.Update != nil && (len(res.Update.Changes) > 0 || len(res.Update.Held) > 0) {
+			updated = append(updated, *res.Update)
+		}
+		// Now add this node to the cache map so we don't send it again.
+		o.nodes[reg.node.Parent] = reg.node
+		if reg.endpoint.MACAddr != "" {
+			o.macs[reg.node.Parent] = reg.endpoint
+		}
+		o.startDiscovery(reg.node)
+		added++
+	}
+	o.lock.Unlock()
+	return
+}
+
+// registerMACs tells HSM which MAC belongs to which BMC, and forgets the
+// ones that made it.  Not fatal, HSM will pick a MAC up itself once it
+// discovers the BMC, but the ones that fail are tried again next pass.
+func (o *onboarder) registerMACs() {
+	o.lock.Lock()
+	var bmcs []string
+	pending := make(map[string]smdclient.HSMNotification, len(o.macs))
+	for bmc, ep := range o.macs {
+		bmcs = append(bmcs, bmc)
+		pending[bmc] = ep
+	}
+	o.lock.Unlock()
+	sort.Strings(bmcs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), onboardNodeTimeout)
+	defer cancel()
+	for _, bmc := range bmcs {
+		ep := pending[bmc]
+		err := retryHSM(ctx, "registering the MAC of "+bmc, func() error {
+			return smdclient.UpsertEthernetInterface(bmc, ep.MACAddr, ep.IPAddress)
+		})
+		if err != nil {
+			log.Printf("ERROR: Unable to register MAC %s for %s in HSM, will retry next pass: %s",
+				ep.MACAddr, bmc, err)
+			continue
+		}
+		o.lock.Lock()
+		// SLS may have given it yet another MAC in the meantime.
+		if o.macs[bmc] == ep {
+			delete(o.macs, bmc)
+		}
+		o.lock.Unlock()
+	}
+}
+
+// syncComponents creates or updates the components REDS manages in HSM, and
+// remembers the ones that made it so later changes in SLS can be spotted.
+func (o *onboarder) syncComponents(comps []base.Component) {
+	ctx, cancel := context.WithTimeout(context.Background(), onboardNodeTimeout)
+	defer cancel()
+	results := retryBulk(ctx, "syncing components", len(comps),
+		func(pending []int) []smdclient.RegistrationResult {
+			batch := make([]base.Component, len(pending))
+			for i, n := range pending {
+				batch[i] = comps[n]
+			}
+			return smdclient.SyncComponents(batch, hsmBatchSize)
+		})
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	for i, res := range results {
+		if smdclient.IsLocked(res.Err) {
+			log.Printf("INFO: Deferring update of component %s: %s", comps[i].ID, res.Err)
+			o.components[comps[i].ID] = base.Component{}
+			continue
+		} else if res.Err != nil {
+			log.Printf("ERROR: Unable to create or update component %s in HSM: %s", comps[i].ID, res.Err)
+			// Never matches SLS, so it's tried again next pass.
+			o.components[comps[i].ID] = base.Component{}
+			continue
+		}
+		o.components[comps[i].ID] = comps[i]
+	}
+}
+
+// retryBulk sends n items to HSM through send, which is given the indexes of
+// the items still to go.  Items that fail transiently are sent again, as
+// retryHSM allows.  The last result for each item is returned.
+func retryBulk(ctx context.Context, what string, n int,
+	send func(pending []int) []smdclient.RegistrationResult) []smdclient.RegistrationResult {
+	results := make([]smdclient.RegistrationResult, n)
+	pending := make([]int, n)
+	for i := range pending {
+		pending[i] = i
+	}
+	retryHSM(ctx, what, func() error {
+		var retry []int
+		var lastErr error
+		for i, res := range send(pending) {
+			results[pending[i]] = res
+			if smdclient.IsRetryable(res.Err) {
+				retry = append(retry, pending[i])
+				lastErr = res.Err
+			}
+		}
+		pending = retry
+		return lastErr
+	})
+	return results
+}
+
+// onboardJob is a base.Job onboarding the nodes of one BMC.
+type onboardJob struct {
+	bmc   string
+	nodes []GenericHardware
+	run   func(j *onboardJob)
+
+	lock   sync.Mutex
+	status base.JobStatus
+	err    error
+}
+
+func (j *onboardJob) Log(format string, a ...interface{}) {
+	log.Printf("Onboard %s: "+format, append([]interface{}{j.bmc}, a...)...)
+}
+
+func (j *onboardJob) Type() base.JobType {
+	return JTYPE_ONBOARD
+}
+
+func (j *onboardJob) Run() {
+	j.run(j)
+}
+
+func (j *onboardJob) GetStatus() (base.JobStatus, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.status, j.err
+}
+
+func (j *onboardJob) SetStatus(status base.JobStatus, err error) (base.JobStatus, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	old := j.status
+	j.status = status
+	j.err = err
+	return old, err
+}
+
+func (j *onboardJob) Cancel() base.JobStatus {
+	j.SetStatus(base.JSTAT_CANCELLED, nil)
+	return base.JSTAT_CANCELLED
+}
+
+// nodeRegistration is what HSM needs to be told about a new node.
+type nodeRegistration struct {
+	node     GenericHardware
+	endpoint smdclient.HSMNotification
+	// Only set for nodes HSM can't discover on its own
+	component *base.Component
+}
+
+// prepareNode seeds credentials for a node's BMC and works out what to tell
+// HSM about it.  The context deadline is checked between steps so an attempt
+// that has timed out stops as soon as its current call returns.
+func prepareNode(ctx context.Context, node GenericHardware) (*nodeRegistration, error) {
+	log.Printf("INFO: Found new node %+v", node)
+
+	conns, err := GetConnectorsByBMC(node.Parent)
+	if err != nil {
+		log.Printf("ERROR: Unable to get node connector info from SLS, not adding "+
+			"nodes in %s for now.", node.Parent)
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// First check to see if there are credentials in Vault for this xname. If there are we won't
+	// re-set them in case they've been changed from the defaults.
+	credentials, err := compcreds.GetCompCred(node.Parent)
+	if err != nil {
+		log.Printf("ERROR: Unable to check Vault for xname credentials, not adding "+
+			"node %s for now.", node.Parent)
+		return nil, err
+	}
+
+	generated := false
+	if credentials.Username == "" || credentials.Password == "" {
+		defaults, origin, err := defaultBMCCredentials(ctx, node)
+		if err != nil {
+			log.Printf("ERROR: Unable to pick credentials for %s: %s, not adding it for now.",
+				node.Parent, err)
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if origin.Policy != "" {
+			log.Printf("INFO: Using credential policy %s for %s", origin.Policy, node.Parent)
+		} else {
+			log.Printf("INFO: Using the %s default credentials for %s (vendor from %s)",
+				origin.Vendor, node.Parent, origin.VendorSource)
+		}
+
+		credentials = compcredentials.CompCredentials{
+			Xname:    node.Parent,
+			Username: defaults.Username,
+			Password: defaults.Password,
+		}
+
+		// With generated passwords the BMC keeps its default until the
+		// generated one is set, so a failure is retried next pass.
+		generated, _, _ = passwordGeneration()
+		if generated {
+			err = storeGeneratedPassword(ctx, node, credentials, origin.Vendor)
+		} else {
+			err = compcreds.StoreCompCred(credentials)
+		}
+		if err != nil {
+			log.Printf("ERROR: Unable to set credentials, not adding node %s for now: %s",
+				node.Parent, err)
+			return nil, err
+		} else {
+			log.Printf("DEBUG: Set credentials for %s", node.Parent)
+		}
+		setNodeStatus(node, func(status *NodeStatus) {
+			status.PasswordGenerated = generated
+			status.CredentialPolicy = origin.Policy
+			status.CredentialVendor = origin.Vendor
+			status.VendorSource = origin.VendorSource
+		})
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	err = verifyBMCCredentials(ctx, node, credentials, generated)
+	if err != nil {
+		return nil, err
+	}
+
+	reg := &nodeRegistration{
+		node:     node,
+		endpoint: bmcInterface(node),
+	}
+
+	// Add Master Management nodes to HSM under /State/Components
+	// to account for cases where their BMC is not connected to
+	// the cluster. These nodes will not have MgmtSwitchConnectors
+	if len(conns) == 0 && isMasterNode(node) {
+		comp, err := componentFromSLS(node)
+		if err != nil {
+			// Not fatal, the BMC can still be registered.
+			log.Printf("ERROR: Not creating component for %s: %s", node.Xname, err)
+		} else {
+			reg.component = &comp
+		}
+	}
+	return reg, nil
+}
+
+// isMasterNode reports whether SLS has a node as a Management Master.
+func isMasterNode(node GenericHardware) bool {
+	return base.VerifyNormalizeRole(extraPropertyString(node, "Role")) == base.RoleManagement.String() &&
+		base.VerifyNormalizeSubRole(extraPropertyString(node, "SubRole")) == base.SubRoleMaster.String()
+}
+
+// bmcInterface returns the endpoint HSM is told about for a node's BMC, with
+// its MAC and address if SLS has them.
+func bmcInterface(node GenericHardware) smdclient.HSMNotification {
+	return smdclient.HSMNotification{
+		ID:                 node.Parent,
+		MACAddr:            bmcMAC(node),
+		IPAddress:          extraPropertyString(node, SLS_BMC_IP_PROPERTY),
+		RediscoverOnUpdate: true,
+	}
+}
+
+// bmcMAC returns the MAC of a node's BMC from SLS, or "" if SLS doesn't have
+// a valid one.  SLS is the only place REDS learns BMC MACs from; the boot
+// image's discovery payload and switch MAC tables are no longer read.
+func bmcMAC(node GenericHardware) string {
+	mac := extraPropertyString(node, SLS_BMC_MAC_PROPERTY)
+	if mac == "" {
+		return ""
+	}
+	if _, err := smdclient.NormalizeMAC(mac); err != nil {
+		log.Printf("WARNING: Ignoring BMC MAC for %s from SLS: %s", node.Xname, err)
+		return ""
+	}
+	return mac
+}
+
+// retryHSM calls f until it succeeds, fails permanently, runs out of
+// attempts or ctx expires.  Only failures smdclient marks as retryable (no
+// response, 429 and 5xx) are retried; anything else is returned at once.
+func retryHSM(ctx context.Context, what string, f func() error) error {
+	delay := hsmRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || !smdclient.IsRetryable(err) || attempt >= hsmRetries {
+			return err
+		}
+		log.Printf("WARNING: Transient HSM failure %s (attempt %d of %d), retrying in %s: %s",
+			what, attempt, hsmRetries, delay, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,493 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/model"
+	"github.com/Cray-HPE/hms-reds/internal/plan"
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
+	"github.com/Cray-HPE/hms-reds/internal/smdclient"
+	sstorage "github.com/Cray-HPE/hms-securestorage"
+)
+
+func onboardTestNode(xname, bmc string) GenericHardware {
+	return GenericHardware{
+		Parent:             bmc,
+		Xname:              xname,
+		Class:              "River",
+		ExtraPropertiesRaw: map[string]interface{}{"Role": "Management"},
+	}
+}
+
+func Test_onboarder_runCycle(t *testing.T) {
+	defer func() { prepareNodeFunc = prepareNode }()
+	fake := smdclient.NewFakeHSM()
+	smdclient.SetClient(fake)
+	defer smdclient.SetClient(nil)
+	oldTimeout := onboardNodeTimeout
+	onboardNodeTimeout = 200 * time.Millisecond
+	defer func() { onboardNodeTimeout = oldTimeout }()
+
+	var lock sync.Mutex
+	var order []string
+	running, maxRunning := 0, 0
+	prepareNodeFunc = func(ctx context.Context, node GenericHardware) (*nodeRegistration, error) {
+		lock.Lock()
+		order = append(order, node.Xname)
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+		defer func() {
+			lock.Lock()
+			running--
+			lock.Unlock()
+		}()
+
+		switch node.Xname {
+		case "x3000c0s21b1n0":
+			return nil, errors.New("SLS said no")
+		case "x3000c0s9b0n0":
+			<-ctx.Done()
+			time.Sleep(100 * time.Millisecond)
+			return nil, ctx.Err()
+		}
+		time.Sleep(50 * time.Millisecond)
+		return &nodeRegistration{
+			node:     node,
+			endpoint: smdclient.HSMNotification{ID: node.Parent},
+		}, nil
+	}
+
+	nodes := []GenericHardware{
+		onboardTestNode("x3000c0s1b0n0", "x3000c0s1b0"),
+		onboardTestNode("x3000c0s3b0n0", "x3000c0s3b0"),
+		// First node under this BMC fails, so the second one is tried.
+		onboardTestNode("x3000c0s21b1n0", "x3000c0s21b1"),
+		onboardTestNode("x3000c0s21b1n1", "x3000c0s21b1"),
+		// First node under this BMC times out; the second waits for next pass.
+		onboardTestNode("x3000c0s9b0n0", "x3000c0s9b0"),
+		onboardTestNode("x3000c0s9b0n1", "x3000c0s9b0"),
+	}
+
+	o := newOnboarder()
+	summary := o.runCycle(nodes)
+
+	want := OnboardingSummary{Nodes: 6, New: 6, Added: 3, Failed: 1, TimedOut: 1, Skipped: 1}
+	summary.Started, summary.Duration = "", ""
+	if !reflect.DeepEqual(summary, want) {
+		t.Fatalf("Summary was %+v, want %+v", summary, want)
+	}
+	if maxRunning < 2 {
+		t.Errorf("Nodes under different BMCs were not onboarded concurrently")
+	}
+	reqs := fake.GetRequests()
+	if len(reqs) != 1 {
+		t.Fatalf("Made %d HSM requests, want one bulk request: %+v", len(reqs), reqs)
+	}
+	var registered []string
+	for _, ep := range reqs[0].Body.([]smdclient.HSMNotification) {
+		registered = append(registered, ep.ID)
+	}
+	if want := []string{"x3000c0s1b0", "x3000c0s3b0", "x3000c0s21b1"}; !reflect.DeepEqual(registered, want) {
+		t.Errorf("Registered %v, want %v", registered, want)
+	}
+
+	lock.Lock()
+	firstOrder := append([]string(nil), order...)
+	lock.Unlock()
+	index := make(map[string]int)
+	for i, xname := range firstOrder {
+		index[xname] = i
+	}
+	if index["x3000c0s21b1n0"] > index["x3000c0s21b1n1"] {
+		t.Errorf("Nodes under the same BMC were onboarded out of order: %v", firstOrder)
+	}
+	if _, ok := index["x3000c0s9b0n1"]; ok {
+		t.Errorf("Node overtook a timed out node under the same BMC: %v", firstOrder)
+	}
+
+	// The timed out attempt is still finishing, so its BMC is left alone.
+	summary = o.runCycle(nodes)
+	if summary.New != 2 || summary.Skipped != 2 || summary.Added != 0 {
+		t.Fatalf("Second pass summary was %+v", summary)
+	}
+
+	// Once it's done the BMC is retried from its first node.
+	time.Sleep(200 * time.Millisecond)
+	lock.Lock()
+	order = nil
+	lock.Unlock()
+	summary = o.runCycle(nodes)
+	if summary.New != 2 || summary.TimedOut != 1 || summary.Skipped != 1 {
+		t.Fatalf("Third pass summary was %+v", summary)
+	}
+	lock.Lock()
+	thirdOrder := append([]string(nil), order...)
+	lock.Unlock()
+	if !reflect.DeepEqual(thirdOrder, []string{"x3000c0s9b0n0"}) {
+		t.Errorf("Third pass onboarded %v", thirdOrder)
+	}
+	if got := GetLastOnboardingSummary(); got.TimedOut != 1 || got.Nodes != 6 {
+		t.Errorf("Last summary was %+v", got)
+	}
+}
+
+func Test_retryHSM(t *testing.T) {
+	oldDelay := hsmRetryDelay
+	hsmRetryDelay = time.Millisecond
+	defer func() { hsmRetryDelay = oldDelay }()
+
+	transient := &smdclient.HSMError{Op: "POST /Inventory/RedfishEndpoints", StatusCode: 503, Retryable: true}
+	permanent := &smdclient.HSMError{Op: "POST /Inventory/RedfishEndpoints", StatusCode: 400}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{{
+		name:      "Success",
+		errs:      []error{nil},
+		wantCalls: 1,
+	}, {
+		name:      "TransientThenSuccess",
+		errs:      []error{transient, nil},
+		wantCalls: 2,
+	}, {
+		name:      "PermanentNotRetried",
+		errs:      []error{permanent, nil},
+		wantCalls: 1,
+		wantErr:   permanent,
+	}, {
+		name:      "TransientGivesUp",
+		errs:      []error{transient, transient, transient, nil},
+		wantCalls: 3,
+		wantErr:   transient,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryHSM(context.Background(), "testing", func() error {
+				calls++
+				return tt.errs[calls-1]
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("retryHSM() made %d calls, want %d", calls, tt.wantCalls)
+			}
+			if err != tt.wantErr {
+				t.Errorf("retryHSM() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+var payloadSLSWorkerConnectors = `[
+	{
+		"Parent": "x3000c0w14",
+		"Xname": "x3000c0w14j7",
+		"Type": "comptype_mgmt_switch_connector",
+		"Class": "River",
+		"TypeString": "MgmtSwitchConnector",
+		"ExtraProperties": {"NodeNics": ["x3000c0s7b0"], "VendorName": "ethernet1/1/7"}
+	}
+]`
+
+func ConnectorsRTFunc(r *http.Request) *http.Response {
+	if r.URL.Path == "/"+SLS_BASE_VERSION+"/"+SLS_SEARCH_HARDWARE_ENDPOINT {
+		switch r.URL.Query().Get("node_nics") {
+		case "x3000c0s1b0", "x3000c0s19b0":
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString("[]")),
+				Header:     make(http.Header),
+			}
+		case "x3000c0s7b0":
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(payloadSLSWorkerConnectors)),
+				Header:     make(http.Header),
+			}
+		}
+	}
+	return BaseRTFunc(r)
+}
+
+func Test_onboardNode_FakeHSM(t *testing.T) {
+	ConfigureSLSMode(SLS_BASE_URL, NewTestClient(ConnectorsRTFunc), &mss, nil, INSTNAME)
+	for _, bmc := range []string{"x3000c0s1b0", "x3000c0s7b0", "x3000c0s19b0"} {
+		compcreds.StoreCompCred(compcredentials.CompCredentials{
+			Xname:    bmc,
+			Username: "root",
+			Password: "secret",
+		})
+	}
+	fake := smdclient.NewFakeHSM()
+	smdclient.SetClient(fake)
+	defer smdclient.SetClient(nil)
+
+	nodes := testNodes(t)[:2]
+	o := newOnboarder()
+	summary := o.runCycle(nodes)
+	if summary.Added != 2 || summary.Failed != 0 {
+		t.Fatalf("Summary was %+v", summary)
+	}
+
+	// The master has no switch connectors so it gets a component of its
+	// own; the worker is left for HSM to discover.  Both BMCs go to HSM in
+	// one request.
+	// SLS knows the worker's BMC MAC, so that's registered too.
+	reqs := fake.GetRequests()
+	if len(reqs) != 6 || reqs[1].Path != "/State/Components" || reqs[2].Path != "/Inventory/RedfishEndpoints" ||
+		reqs[4].Path != "/Inventory/EthernetInterfaces" {
+		t.Fatalf("Requests were %+v, want components, endpoints then the worker's MAC", reqs)
+	}
+	eps := reqs[2].Body.([]smdclient.HSMNotification)
+	if len(eps) != 2 || eps[1].MACAddr != "B4:2E:99:3B:70:28" || eps[1].IPAddress != "10.254.1.14" {
+		t.Errorf("Bulk registration carried %+v", eps)
+	}
+	ei, ok := fake.EthernetInterfaces["b42e993b7028"]
+	if !ok || ei.ComponentID != "x3000c0s7b0" || len(ei.IPAddresses) != 1 ||
+		ei.IPAddresses[0].IPAddress != "10.254.1.14" {
+		t.Errorf("EthernetInterface was %+v", ei)
+	}
+	comp, err := fake.GetComponent("x3000c0s1b0n0")
+	if err != nil {
+		t.Fatalf("Master component not created: %s", err)
+	}
+	if comp.Role != "Management" || comp.SubRole != "Master" || comp.NID.String() != "100001" ||
+		comp.State != "Populated" {
+		t.Errorf("Master component was %+v", comp)
+	}
+	if _, err := fake.GetComponent("x3000c0s7b0n0"); err == nil {
+		t.Errorf("Worker component should be left for discovery")
+	}
+	if ep, err := fake.GetRedfishEndpoint("x3000c0s7b0"); err != nil || !ep.RediscoverOnUpdate {
+		t.Errorf("Worker BMC endpoint was %+v, %v", ep, err)
+	}
+
+	// Nothing more is sent for nodes already onboarded.
+	fake.ClearRequests()
+	if summary = o.runCycle(nodes); summary.New != 0 || len(fake.GetRequests()) != 0 {
+		t.Errorf("Second pass summary was %+v, requests %+v", summary, fake.GetRequests())
+	}
+
+	// The master's component follows changes in SLS, keeping its State.
+	comp.State = "Ready"
+	fake.Components[comp.ID] = *comp
+	nodes[0].ExtraPropertiesRaw.(map[string]interface{})["Arch"] = "arm"
+	fake.ClearRequests()
+	o.runCycle(nodes)
+	reqs = fake.GetRequests()
+	if len(reqs) != 3 || reqs[1].Path != "/locks/status" || reqs[2].Body.(smdclient.HSMCompNotification).Force != true {
+		t.Fatalf("Requests after SLS change were %+v, want GET, lock check then forced POST", reqs)
+	}
+	if comp, _ = fake.GetComponent("x3000c0s1b0n0"); comp.Arch != "ARM" || comp.State != "Ready" {
+		t.Errorf("Updated master component was %+v", comp)
+	}
+	if status, _ := GetNodeStatus("x3000c0s1b0"); !reflect.DeepEqual(status.Aliases, []string{"ncn-m001"}) {
+		t.Errorf("Master status was %+v", status)
+	}
+
+	// A new BMC MAC in SLS is recorded and the old one unassigned.
+	// Each pass reads SLS afresh, so the cached node keeps the old one.
+	props := map[string]interface{}{}
+	for k, v := range nodes[1].ExtraPropertiesRaw.(map[string]interface{}) {
+		props[k] = v
+	}
+	props["BMCMACAddress"] = "B4:2E:99:3B:70:29"
+	nodes[1].ExtraPropertiesRaw = props
+	o.runCycle(nodes)
+	if ei := fake.EthernetInterfaces["b42e993b7029"]; ei.ComponentID != "x3000c0s7b0" {
+		t.Errorf("New MAC's EthernetInterface was %+v", ei)
+	}
+	if ei := fake.EthernetInterfaces["b42e993b7028"]; ei.ComponentID != "" {
+		t.Errorf("Old MAC's EthernetInterface was %+v", ei)
+	}
+
+	// An endpoint HSM already has, but disabled, fails the bulk request and
+	// is then re-enabled on its own.
+	disabled := false
+	fake.RedfishEndpoints["x3000c0s19b0"] = smdclient.RedfishEndpoint{ID: "x3000c0s19b0", Enabled: &disabled}
+	fake.ClearRequests()
+	if summary = o.runCycle(append(nodes[:2:2], testNodes(t)[2])); summary.Added != 1 || len(summary.Updated) != 1 ||
+		summary.Updated[0].ID != "x3000c0s19b0" {
+		t.Fatalf("Third pass summary was %+v", summary)
+	}
+	reqs = fake.GetRequests()
+	if len(reqs) != 5 || reqs[0].Method != http.MethodPost || reqs[1].Method != http.MethodPost ||
+		reqs[2].Method != http.MethodGet || reqs[3].Path != "/locks/status" || reqs[4].Method != http.MethodPatch {
+		t.Fatalf("Requests were %+v, want bulk POST, POST, GET, lock check then PATCH", reqs)
+	}
+	patch := reqs[4].Body.(smdclient.RedfishEndpointPatch)
+	if patch.Enabled == nil || !*patch.Enabled || patch.FQDN != nil || patch.User != nil {
+		t.Errorf("PATCH should only enable the endpoint, got %+v", patch)
+	}
+}
+
+func Test_onboarder_lockedBMC(t *testing.T) {
+	ConfigureSLSMode(SLS_BASE_URL, NewTestClient(ConnectorsRTFunc), &mss, nil, INSTNAME)
+	compcreds.StoreCompCred(compcredentials.CompCredentials{
+		Xname:    "x3000c0s7b0",
+		Username: "root",
+		Password: "secret",
+	})
+	fake := smdclient.NewFakeHSM()
+	smdclient.SetClient(fake)
+	defer smdclient.SetClient(nil)
+
+	// HSM has the worker's BMC disabled, and a firmware update holds a lock
+	// on it.
+	disabled := false
+	fake.RedfishEndpoints["x3000c0s7b0"] = smdclient.RedfishEndpoint{ID: "x3000c0s7b0", Enabled: &disabled}
+	fake.SetLock("x3000c0s7b0", true, false)
+
+	nodes := testNodes(t)[1:2]
+	o := newOnboarder()
+	summary := o.runCycle(nodes)
+	if summary.Added != 0 || summary.Failed != 0 || summary.Deferred != 1 {
+		t.Fatalf("Summary was %+v", summary)
+	}
+	for _, req := range fake.GetRequests() {
+		if req.Method == http.MethodPatch {
+			t.Errorf("Locked endpoint was patched: %+v", req)
+		}
+	}
+	if status, _ := GetNodeStatus("x3000c0s7b0"); status.State != NODE_STATE_DEFERRED ||
+		!strings.Contains(status.Error, "locked") {
+		t.Errorf("Status was %+v", status)
+	}
+
+	// Once the lock is released the next pass enables it.
+	fake.SetLock("x3000c0s7b0", false, false)
+	if summary = o.runCycle(nodes); summary.Added != 1 || summary.Deferred != 0 {
+		t.Fatalf("Summary after the lock was released was %+v", summary)
+	}
+	if !*fake.RedfishEndpoints["x3000c0s7b0"].Enabled {
+		t.Errorf("Endpoint was not enabled")
+	}
+	if status, _ := GetNodeStatus("x3000c0s7b0"); status.State != NODE_STATE_DISCOVERING {
+		t.Errorf("Status after the lock was released was %+v", status)
+	}
+}
+
+func Test_onboarder_retryMAC(t *testing.T) {
+	ConfigureSLSMode(SLS_BASE_URL, NewTestClient(ConnectorsRTFunc), &mss, nil, INSTNAME)
+	compcreds.StoreCompCred(compcredentials.CompCredentials{
+		Xname:    "x3000c0s7b0",
+		Username: "root",
+		Password: "secret",
+	})
+	fake := smdclient.NewFakeHSM()
+	smdclient.SetClient(fake)
+	defer smdclient.SetClient(nil)
+	defer func(retries int) { hsmRetries = retries }(hsmRetries)
+	hsmRetries = 1
+
+	// HSM won't take the MAC; the BMC is registered anyway.
+	fake.FailWith = func(method string, path string) int {
+		if strings.HasPrefix(path, "/Inventory/EthernetInterfaces") {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	}
+	nodes := testNodes(t)[1:2]
+	o := newOnboarder()
+	if summary := o.runCycle(nodes); summary.Added != 1 {
+		t.Fatalf("Summary was %+v", summary)
+	}
+	if len(fake.EthernetInterfaces) != 0 {
+		t.Fatalf("EthernetInterfaces were %+v", fake.EthernetInterfaces)
+	}
+
+	// The MAC is tried again on the next pass, and only until it's in.
+	fake.FailWith = nil
+	o.runCycle(nodes)
+	if ei := fake.EthernetInterfaces["b42e993b7028"]; ei.ComponentID != "x3000c0s7b0" {
+		t.Errorf("EthernetInterface after the retry was %+v", ei)
+	}
+	fake.ClearRequests()
+	if o.runCycle(nodes); len(fake.GetRequests()) != 0 {
+		t.Errorf("Requests after the MAC was recorded were %+v", fake.GetRequests())
+	}
+}
+
+// storeCounter counts the writes that reach the storage under a plan.
+type storeCounter struct {
+	*vaultMock
+	stores int
+}
+
+func (s *storeCounter) Store(key string, value interface{}) error {
+	s.stores++
+	return s.vaultMock.Store(key, value)
+}
+
+func Test_onboarder_dryRun(t *testing.T) {
+	savedCompcreds, savedRedsCreds, savedReferences := compcreds, redsCreds, references
+	defer func() { compcreds, redsCreds, references = savedCompcreds, savedRedsCreds, savedReferences }()
+
+	vault := &storeCounter{vaultMock: &vaultMock{data: make(map[string][]byte)}}
+	vault.Store(securestore.GetConfig().RedsCredsPath()+"/defaults",
+		map[string]model.RedsCredentials{"Cray": {Username: "root", Password: "initial0"}})
+	vault.stores = 0
+
+	// Wired up as main does in dry-run mode
+	rec := plan.NewRecorder(plan.DefaultMaxEntries)
+	var planned sstorage.SecureStorage = plan.NewSecureStorage(vault, rec)
+	ConfigureSLSMode(SLS_BASE_URL, NewTestClient(ConnectorsRTFunc), &planned, nil, INSTNAME)
+	SetStorage(planned)
+	smdclient.SetClient(smdclient.NewPlanningClient(smdclient.NewFakeHSM(), rec))
+	defer smdclient.SetClient(nil)
+
+	o := newOnboarder()
+	if summary := o.runCycle(testNodes(t)[:2]); summary.Added != 2 || summary.Failed != 0 {
+		t.Fatalf("Summary was %+v", summary)
+	}
+	if vault.stores != 0 {
+		t.Errorf("A dry-run onboarding pass stored %d values in Vault, want none", vault.stores)
+	}
+	seeded := 0
+	entries, _ := rec.Entries()
+	for _, e := range entries {
+		if e.Service == plan.SERVICE_VAULT {
+			seeded++
+		}
+	}
+	if seeded != 2 {
+		t.Errorf("The plan has %d Vault changes, want the credentials of both BMCs: %+v", seeded, entries)
+	}
+}
@@ -0,0 +1,188 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// OnboardingPolicy chooses which SLS nodes REDS seeds credentials for and
+// registers with HSM.  A node is selected only if it matches every list that
+// is non-empty; an empty list matches anything.  Comparisons ignore case.
+type OnboardingPolicy struct {
+	Roles         []string `json:"roles"`
+	SubRoles      []string `json:"subRoles"`
+	Classes       []string `json:"classes"`
+	XnamePrefixes []string `json:"xnamePrefixes"`
+}
+
+// DefaultOnboardingPolicy selects River management nodes, which is all REDS
+// has ever onboarded.
+var DefaultOnboardingPolicy = OnboardingPolicy{
+	Roles:   []string{"Management"},
+	Classes: []string{"River"},
+}
+
+var onboardingPolicy = DefaultOnboardingPolicy
+var policyLock sync.RWMutex
+
+func (p OnboardingPolicy) String() string {
+	return fmt.Sprintf("{ Roles: %v, SubRoles: %v, Classes: %v, XnamePrefixes: %v }",
+		p.Roles, p.SubRoles, p.Classes, p.XnamePrefixes)
+}
+
+// Validate checks that the policy selects something sensible.
+func (p OnboardingPolicy) Validate() error {
+	lists := map[string][]string{
+		"roles":         p.Roles,
+		"subRoles":      p.SubRoles,
+		"classes":       p.Classes,
+		"xnamePrefixes": p.XnamePrefixes,
+	}
+	empty := true
+	for name, list := range lists {
+		for _, val := range list {
+			if strings.TrimSpace(val) == "" {
+				return fmt.Errorf("onboarding policy has an empty entry in %s", name)
+			}
+			empty = false
+		}
+	}
+	if empty {
+		return errors.New("onboarding policy would select every node in SLS")
+	}
+	return nil
+}
+
+func matchesAny(list []string, val string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, l := range list {
+		if strings.EqualFold(l, val) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(prefixes []string, xname string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(strings.ToLower(xname), strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the policy selects the given SLS node.
+func (p OnboardingPolicy) Matches(node GenericHardware) bool {
+	return matchesAny(p.Classes, node.Class) &&
+		matchesAny(p.Roles, extraPropertyString(node, "Role")) &&
+		matchesAny(p.SubRoles, extraPropertyString(node, "SubRole")) &&
+		hasAnyPrefix(p.XnamePrefixes, node.Xname)
+}
+
+// slsQueries returns the SLS search queries needed to find every node the
+// policy could select.  The search is narrowed as far as SLS allows; the
+// results still have to be filtered with Matches.
+func (p OnboardingPolicy) slsQueries() []string {
+	common := url.Values{}
+	common.Set("type", "comptype_node")
+	if len(p.Roles) == 1 {
+		common.Set("extra_properties.Role", p.Roles[0])
+	}
+	if len(p.SubRoles) == 1 {
+		common.Set("extra_properties.SubRole", p.SubRoles[0])
+	}
+
+	if len(p.Classes) == 0 {
+		return []string{common.Encode()}
+	}
+
+	var queries []string
+	for _, class := range p.Classes {
+		q := url.Values{}
+		for k, v := range common {
+			q[k] = v
+		}
+		q.Set("class", class)
+		queries = append(queries, q.Encode())
+	}
+	return queries
+}
+
+// SetOnboardingPolicy replaces the policy used by the management node watcher.
+func SetOnboardingPolicy(p OnboardingPolicy) error {
+	err := p.Validate()
+	if err != nil {
+		return err
+	}
+	policyLock.Lock()
+	defer policyLock.Unlock()
+	onboardingPolicy = p
+	log.Printf("INFO: Onboarding policy set to %s", p)
+	return nil
+}
+
+// GetOnboardingPolicy returns the policy used by the management node watcher.
+func GetOnboardingPolicy() OnboardingPolicy {
+	policyLock.RLock()
+	defer policyLock.RUnlock()
+	return onboardingPolicy
+}
+
+// LoadOnboardingPolicy reads a JSON onboarding policy from a file.
+func LoadOnboardingPolicy(path string) (OnboardingPolicy, error) {
+	var p OnboardingPolicy
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(data, &p)
+	if err != nil {
+		return p, fmt.Errorf("unable to parse onboarding policy %s: %s", path, err)
+	}
+	return p, p.Validate()
+}
+
+// extraPropertyString returns a string property from an SLS object's
+// ExtraProperties, or "" if it's missing or not a string.
+func extraPropertyString(gh GenericHardware, key string) string {
+	props, ok := gh.ExtraPropertiesRaw.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	val, _ := props[key].(string)
+	return val
+}
@@ -0,0 +1,196 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+var payloadSLSNodes = `[
+	{
+		"Parent": "x3000c0s1b0",
+		"Xname": "x3000c0s1b0n0",
+		"Type": "comptype_node",
+		"Class": "River",
+		"TypeString": "Node",
+		"ExtraProperties": {"NID": 100001, "Role": "Management", "SubRole": "Master", "Aliases": ["ncn-m001"]}
+	},
+	{
+		"Parent": "x3000c0s7b0",
+		"Xname": "x3000c0s7b0n0",
+		"Type": "comptype_node",
+		"Class": "River",
+		"TypeString": "Node",
+		"ExtraProperties": {"NID": 100004, "Role": "Management", "SubRole": "Worker", "Aliases": ["ncn-w001"],
+			"BMCMACAddress": "B4:2E:99:3B:70:28", "BMCIPAddress": "10.254.1.14"}
+	},
+	{
+		"Parent": "x3000c0s19b0",
+		"Xname": "x3000c0s19b0n0",
+		"Type": "comptype_node",
+		"Class": "River",
+		"TypeString": "Node",
+		"ExtraProperties": {"NID": 1, "Role": "Application", "SubRole": "UAN", "Aliases": ["uan01"]}
+	},
+	{
+		"Parent": "x3000c0s21b1",
+		"Xname": "x3000c0s21b1n0",
+		"Type": "comptype_node",
+		"Class": "River",
+		"TypeString": "Node",
+		"ExtraProperties": {"NID": 2, "Role": "Compute", "Aliases": ["nid000002"]}
+	},
+	{
+		"Parent": "x1000c0s0b0",
+		"Xname": "x1000c0s0b0n0",
+		"Type": "comptype_node",
+		"Class": "Mountain",
+		"TypeString": "Node",
+		"ExtraProperties": {"NID": 1000, "Role": "Compute"}
+	}
+]`
+
+func testNodes(t *testing.T) []GenericHardware {
+	var nodes []GenericHardware
+	if err := json.Unmarshal([]byte(payloadSLSNodes), &nodes); err != nil {
+		t.Fatalf("Unable to unmarshal node payload: %s", err)
+	}
+	return nodes
+}
+
+func TestOnboardingPolicy_Matches(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OnboardingPolicy
+		want   []string
+	}{{
+		name:   "Default",
+		policy: DefaultOnboardingPolicy,
+		want:   []string{"x3000c0s1b0n0", "x3000c0s7b0n0"},
+	}, {
+		name:   "UANs",
+		policy: OnboardingPolicy{Roles: []string{"application"}, SubRoles: []string{"uan"}},
+		want:   []string{"x3000c0s19b0n0"},
+	}, {
+		name:   "AllRiver",
+		policy: OnboardingPolicy{Classes: []string{"River"}},
+		want:   []string{"x3000c0s19b0n0", "x3000c0s1b0n0", "x3000c0s21b1n0", "x3000c0s7b0n0"},
+	}, {
+		name:   "ComputeByPrefix",
+		policy: OnboardingPolicy{Roles: []string{"Compute"}, XnamePrefixes: []string{"x3000"}},
+		want:   []string{"x3000c0s21b1n0"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, node := range testNodes(t) {
+				if tt.policy.Matches(node) {
+					got = append(got, node.Xname)
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OnboardingPolicy.Matches() selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOnboardingPolicy_Validate(t *testing.T) {
+	if err := DefaultOnboardingPolicy.Validate(); err != nil {
+		t.Errorf("Default policy failed validation: %s", err)
+	}
+	if err := (OnboardingPolicy{}).Validate(); err == nil {
+		t.Errorf("Empty policy passed validation")
+	}
+	if err := (OnboardingPolicy{Roles: []string{"Management", " "}}).Validate(); err == nil {
+		t.Errorf("Policy with blank role passed validation")
+	}
+}
+
+func TestOnboardingPolicy_slsQueries(t *testing.T) {
+	got := DefaultOnboardingPolicy.slsQueries()
+	want := []string{"class=River&extra_properties.Role=Management&type=comptype_node"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Default policy queries = %v, want %v", got, want)
+	}
+
+	got = OnboardingPolicy{Roles: []string{"Management", "Application"}, Classes: []string{"River", "Hill"}}.slsQueries()
+	want = []string{"class=River&type=comptype_node", "class=Hill&type=comptype_node"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Multi-role policy queries = %v, want %v", got, want)
+	}
+}
+
+func NodesRTFunc(r *http.Request) *http.Response {
+	if r.URL.Path == "/"+SLS_BASE_VERSION+"/"+SLS_SEARCH_HARDWARE_ENDPOINT &&
+		r.URL.Query().Get("type") == "comptype_node" {
+		// Pretend SLS ignores the filters; the policy must still apply them.
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(payloadSLSNodes)),
+			Header:     make(http.Header),
+		}
+	}
+	return BaseRTFunc(r)
+}
+
+func Test_SLS_GetOnboardingNodes(t *testing.T) {
+	ConfigureSLSMode(SLS_BASE_URL, NewTestClient(NodesRTFunc), &mss, compcreds, INSTNAME)
+	defer SetOnboardingPolicy(DefaultOnboardingPolicy)
+
+	err := SetOnboardingPolicy(OnboardingPolicy{
+		Roles:    []string{"Management", "Application"},
+		SubRoles: []string{"Master", "UAN"},
+		Classes:  []string{"River", "Hill"},
+	})
+	if err != nil {
+		t.Fatalf("Unable to set onboarding policy: %s", err)
+	}
+
+	nodes, err := GetOnboardingNodes()
+	if err != nil {
+		t.Fatalf("Unexpected error retrieving nodes: %s", err)
+	}
+	var got []string
+	for _, node := range nodes {
+		got = append(got, node.Xname)
+	}
+	want := []string{"x3000c0s1b0n0", "x3000c0s19b0n0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetOnboardingNodes() returned %v, want %v", got, want)
+	}
+	legacy, err := GetManagementNodes()
+	if err != nil {
+		t.Fatalf("Unexpected error retrieving nodes: %s", err)
+	}
+	if !reflect.DeepEqual(legacy, nodes) {
+		t.Fatalf("GetManagementNodes() returned %v, want %v", legacy, nodes)
+	}
+}
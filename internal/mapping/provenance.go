@@ -0,0 +1,122 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package mapping
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
+)
+
+// Where a switch credential came from
+const (
+	// SLS ExtraProperties, in plaintext
+	CRED_SOURCE_SLS = "SLS"
+	// Vault, through a vault:// reference in SLS
+	CRED_SOURCE_VAULT_REFERENCE = "VaultReference"
+	// Vault, with nothing in SLS
+	CRED_SOURCE_VAULT = "Vault"
+	// A credential policy, stored in Vault on this pass
+	CRED_SOURCE_POLICY = "Policy"
+	// The switch defaults, stored in Vault on this pass
+	CRED_SOURCE_DEFAULTS = "Defaults"
+	// Nowhere; the switch has no such credential
+	CRED_SOURCE_NONE = "None"
+)
+
+// SwitchCredentialSources records where the credentials REDS last used for
+// a switch came from.
+type SwitchCredentialSources struct {
+	Switch       string `json:"switch"`
+	Username     string `json:"username"`
+	AuthPassword string `json:"authPassword"`
+	PrivPassword string `json:"privPassword"`
+	// The credential policy the switch was seeded from, if any
+	Policy string `json:"policy,omitempty"`
+	// Whether a password is kept in plaintext in SLS
+	Plaintext bool   `json:"plaintext"`
+	Updated   string `json:"updated"`
+}
+
+var switchSources = make(map[string]SwitchCredentialSources)
+var switchSourcesLock sync.Mutex
+
+// slsCredentialSource says where a value from SLS ExtraProperties comes
+// from, or "" if SLS has none.
+func slsCredentialSource(value string) string {
+	switch {
+	case value == "":
+		return ""
+	case securestore.IsReference(value):
+		return CRED_SOURCE_VAULT_REFERENCE
+	default:
+		return CRED_SOURCE_SLS
+	}
+}
+
+// finalCredentialSource fills in the source of a credential SLS and the
+// defaults didn't give, once its value is known.
+func finalCredentialSource(source, value string) string {
+	switch {
+	case value == "":
+		return CRED_SOURCE_NONE
+	case source == "":
+		return CRED_SOURCE_VAULT
+	default:
+		return source
+	}
+}
+
+// recordSwitchCredentialSources keeps the sources of a switch's
+// credentials, warning when passwords show up in plaintext in SLS.
+func recordSwitchCredentialSources(sources SwitchCredentialSources) {
+	sources.Plaintext = sources.AuthPassword == CRED_SOURCE_SLS || sources.PrivPassword == CRED_SOURCE_SLS
+	sources.Updated = time.Now().Format(time.RFC3339)
+
+	switchSourcesLock.Lock()
+	defer switchSourcesLock.Unlock()
+	if sources.Plaintext && !switchSources[sources.Switch].Plaintext {
+		log.Printf("WARNING: SLS has the SNMP passwords of %s in plaintext; migrate_switch_creds moves them to Vault",
+			sources.Switch)
+	}
+	switchSources[sources.Switch] = sources
+}
+
+// GetSwitchCredentialSources returns where the credentials of each switch
+// came from, by xname.  With plaintextOnly, only switches with passwords
+// in plaintext in SLS are returned.
+func GetSwitchCredentialSources(plaintextOnly bool) []SwitchCredentialSources {
+	switchSourcesLock.Lock()
+	defer switchSourcesLock.Unlock()
+	ret := []SwitchCredentialSources{}
+	for _, sources := range switchSources {
+		if plaintextOnly && !sources.Plaintext {
+			continue
+		}
+		ret = append(ret, sources)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Switch < ret[j].Switch })
+	return ret
+}
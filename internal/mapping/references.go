@@ -0,0 +1,158 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package mapping
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
+)
+
+// The Vault fields switch properties in SLS stand for, used by references
+// that don't name one
+var switchReferenceFields = map[string]string{
+	"SNMPUsername":     "Username",
+	"SNMPAuthPassword": "SNMPAuthPass",
+	"SNMPPrivPassword": "SNMPPrivPass",
+}
+
+// Resolves vault:// references to secrets other than a switch's own
+var references *securestore.Resolver
+
+// UnresolvedReference is a vault:// reference in SLS that doesn't resolve.
+// The switch's own credentials in Vault are used instead.
+type UnresolvedReference struct {
+	Switch    string `json:"switch"`
+	Property  string `json:"property"`
+	Reference string `json:"reference"`
+	Error     string `json:"error"`
+	Updated   string `json:"updated"`
+}
+
+var unresolvedReferences = make(map[string]UnresolvedReference)
+var unresolvedReferencesLock sync.Mutex
+
+// resolveSwitchReference returns the value a switch property's vault://
+// reference names.  References to the switch's own credentials use own,
+// which may have just been seeded.
+func resolveSwitchReference(xname, property, value, own string) (string, error) {
+	ref, err := securestore.ParseReference(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", securestore.ErrUnresolved, err)
+	}
+	field := switchReferenceFields[property]
+	if ref.Key() == compcreds.CCPath+"/"+xname && (ref.Field == "" || strings.EqualFold(ref.Field, field)) {
+		if own == "" {
+			return "", fmt.Errorf("%w %s: no %s in %s", securestore.ErrUnresolved, ref, field, ref.Key())
+		}
+		return own, nil
+	}
+	if references == nil {
+		return "", errors.New("no secure store to resolve references in")
+	}
+	return references.Resolve(ref, field)
+}
+
+// seedSwitchCredential returns the value to seed a switch's own credential
+// in Vault with: what SLS has, or the secret its vault:// reference names.
+// The default is used if SLS has nothing, or the reference names the
+// switch's own credential or doesn't resolve; switchCredential falls back to
+// the switch's own credential then too, so REDS and HSM agree.
+func seedSwitchCredential(xname, property, value, def string) string {
+	if value == "" {
+		return def
+	}
+	if !securestore.IsReference(value) {
+		return value
+	}
+	resolved, err := resolveSwitchReference(xname, property, value, def)
+	if err != nil || resolved == "" {
+		return def
+	}
+	return resolved
+}
+
+// switchCredential returns the value to use for a switch property: what SLS
+// has, the secret its vault:// reference names, or the switch's own
+// credential in Vault if SLS has nothing or the reference doesn't resolve.
+func switchCredential(xname, property, value, own string) string {
+	if value == "" {
+		return own
+	}
+	if !securestore.IsReference(value) {
+		return value
+	}
+	resolved, err := resolveSwitchReference(xname, property, value, own)
+
+	key := xname + "/" + property
+	unresolvedReferencesLock.Lock()
+	defer unresolvedReferencesLock.Unlock()
+	if err == nil {
+		delete(unresolvedReferences, key)
+		return resolved
+	}
+	if prior, ok := unresolvedReferences[key]; !ok || prior.Reference != value {
+		log.Printf("ERROR: The %s of %s in SLS doesn't resolve, using its own credentials from Vault: %s",
+			property, xname, err)
+	}
+	unresolvedReferences[key] = UnresolvedReference{
+		Switch:    xname,
+		Property:  property,
+		Reference: value,
+		Error:     err.Error(),
+		Updated:   time.Now().Format(time.RFC3339),
+	}
+	return own
+}
+
+// invalidateReferences drops a switch's own secret from the reference cache
+// once its credentials are stored, so other switches referring to it see the
+// new ones.
+func invalidateReferences(xname string) {
+	if references != nil {
+		references.Invalidate(compcreds.CCPath + "/" + xname)
+	}
+}
+
+// GetUnresolvedReferences returns the vault:// references in SLS that
+// didn't resolve when last read, by switch and property.
+func GetUnresolvedReferences() []UnresolvedReference {
+	unresolvedReferencesLock.Lock()
+	defer unresolvedReferencesLock.Unlock()
+	ret := []UnresolvedReference{}
+	for _, ref := range unresolvedReferences {
+		ret = append(ret, ref)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Switch != ret[j].Switch {
+			return ret[i].Switch < ret[j].Switch
+		}
+		return ret[i].Property < ret[j].Property
+	})
+	return ret
+}
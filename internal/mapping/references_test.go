@@ -0,0 +1,110 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package mapping
+
+import (
+	"testing"
+)
+
+func Test_switchCredential_references(t *testing.T) {
+	newSLSMock(t)
+	savedUnresolved := unresolvedReferences
+	defer func() { unresolvedReferences = savedUnresolved }()
+	unresolvedReferences = make(map[string]UnresolvedReference)
+
+	// A secret shared by several switches, under its own field names
+	compcreds.SS.Store("secret/switch-shared", map[string]interface{}{
+		"user": "shareduser", "auth": "sharedauth", "SNMPPrivPass": "sharedpriv"})
+
+	gh := GenericHardware{Xname: "x3000c0w30", ExtraPropertiesRaw: map[string]interface{}{
+		"IP4addr":          "10.1.1.30",
+		"SNMPUsername":     "vault://switch-shared#user",
+		"SNMPAuthPassword": "vault://secret/switch-shared#auth",
+		"SNMPPrivPassword": "vault://switch-shared",
+	}}
+	sw, err := switchFromSLSReturn(gh)
+	if err != nil {
+		t.Fatalf("switchFromSLSReturn() error = %v", err)
+	}
+	if sw.SnmpUser != "shareduser" || sw.SnmpAuthPassword != "sharedauth" || sw.SnmpPrivPassword != "sharedpriv" {
+		t.Errorf("switchFromSLSReturn() with a shared secret = %s", sw)
+	}
+	// HSM reads the switch's own secret, so it's seeded with the shared one.
+	own, _ := compcreds.GetCompCred("x3000c0w30")
+	if own.Username != "shareduser" || own.SNMPAuthPass != "sharedauth" || own.SNMPPrivPass != "sharedpriv" {
+		t.Errorf("Seeded credentials with a shared secret = %s", own)
+	}
+	if got := GetUnresolvedReferences(); len(got) != 0 {
+		t.Errorf("GetUnresolvedReferences() = %v, want none", got)
+	}
+
+	// References to the switch's own secret
+	gh = GenericHardware{Xname: "x3000c0w15", ExtraPropertiesRaw: map[string]interface{}{
+		"IP4addr":          "10.1.1.15",
+		"SNMPAuthPassword": "vault://hms-creds/x3000c0w15",
+		"SNMPPrivPassword": "vault://hms-creds/x3000c0w15#SNMPPrivPass",
+	}}
+	sw, err = switchFromSLSReturn(gh)
+	if err != nil {
+		t.Fatalf("switchFromSLSReturn() error = %v", err)
+	}
+	if sw.SnmpUser != "testuser" || sw.SnmpAuthPassword != "vaultauth" || sw.SnmpPrivPassword != "vaultpriv" {
+		t.Errorf("switchFromSLSReturn() with its own secret = %s", sw)
+	}
+
+	// Missing secrets and fields fall back to the switch's own credentials
+	gh = GenericHardware{Xname: "x3000c0w15", ExtraPropertiesRaw: map[string]interface{}{
+		"IP4addr":          "10.1.1.15",
+		"SNMPAuthPassword": "vault://switch-missing",
+		"SNMPPrivPassword": "vault://switch-shared#nope",
+	}}
+	sw, err = switchFromSLSReturn(gh)
+	if err != nil {
+		t.Fatalf("switchFromSLSReturn() error = %v", err)
+	}
+	if sw.SnmpAuthPassword != "vaultauth" || sw.SnmpPrivPassword != "vaultpriv" {
+		t.Errorf("switchFromSLSReturn() with unresolved references = %s", sw)
+	}
+	got := GetUnresolvedReferences()
+	if len(got) != 2 || got[0].Switch != "x3000c0w15" || got[0].Property != "SNMPAuthPassword" ||
+		got[0].Reference != "vault://switch-missing" || got[1].Property != "SNMPPrivPassword" {
+		t.Errorf("GetUnresolvedReferences() = %v", got)
+	}
+
+	// They're dropped from the report once they resolve
+	compcreds.SS.Store("secret/switch-missing", map[string]interface{}{"SNMPAuthPass": "foundauth"})
+	gh.ExtraPropertiesRaw = map[string]interface{}{
+		"IP4addr":          "10.1.1.15",
+		"SNMPAuthPassword": "vault://switch-missing",
+		"SNMPPrivPassword": "vault://switch-shared",
+	}
+	sw, err = switchFromSLSReturn(gh)
+	if err != nil {
+		t.Fatalf("switchFromSLSReturn() error = %v", err)
+	}
+	if sw.SnmpAuthPassword != "foundauth" || sw.SnmpPrivPassword != "sharedpriv" {
+		t.Errorf("switchFromSLSReturn() after the secret is added = %s", sw)
+	}
+	if got := GetUnresolvedReferences(); len(got) != 0 {
+		t.Errorf("GetUnresolvedReferences() = %v, want none", got)
+	}
+}
@@ -0,0 +1,61 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"log"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/passwords"
+	"github.com/Cray-HPE/hms-reds/internal/smdclient"
+)
+
+// GetCompCredStore returns the Vault store BMC credentials are kept in.
+func GetCompCredStore() *compcredentials.CompCredStore {
+	return compcreds
+}
+
+// BMCTarget returns where an onboarded BMC can be reached, from its
+// endpoint in HSM, and its vendor if the vendor policy can tell.
+func BMCTarget(ctx context.Context, xname string) passwords.Target {
+	target := passwords.Target{Xname: xname, Host: xname}
+	ep, err := smdclient.GetRedfishEndpoint(xname)
+	if err != nil {
+		log.Printf("WARNING: Unable to get the endpoint of %s from HSM, using its xname: %s", xname, err)
+	} else if ep.FQDN != "" {
+		target.Host = ep.FQDN
+	} else if ep.IPAddress != "" {
+		target.Host = ep.IPAddress
+	}
+
+	node := GenericHardware{
+		Xname:  xname,
+		Parent: xname,
+		ExtraPropertiesRaw: map[string]interface{}{
+			SLS_BMC_IP_PROPERTY: target.Host,
+		},
+	}
+	target.Vendor, _ = GetVendorPolicy().resolveVendor(ctx, node)
+	return target
+}
@@ -0,0 +1,288 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Cray-HPE/hms-reds/internal/model"
+)
+
+// SLS node ExtraProperty naming the vendor of the node's BMC
+const SLS_BMC_VENDOR_PROPERTY = "BMCVendor"
+
+// Where the vendor used to pick a BMC's default credentials came from
+const (
+	VENDOR_SOURCE_SLS      = "sls"
+	VENDOR_SOURCE_RULE     = "rule"
+	VENDOR_SOURCE_REDFISH  = "redfish"
+	VENDOR_SOURCE_FALLBACK = "fallback"
+)
+
+// VendorRule gives the vendor of the BMCs whose xname starts with
+// XnamePrefix.
+type VendorRule struct {
+	XnamePrefix string `json:"xnamePrefix"`
+	Vendor      string `json:"vendor"`
+}
+
+// VendorPolicy decides which vendor's default credentials a new BMC gets.
+// The vendor comes from SLS, then the first matching rule, then, if Probe is
+// set, the BMC's Redfish service root.  If there are no defaults for that
+// vendor, or it can't be found, the vendors in Fallback are tried in order.
+type VendorPolicy struct {
+	Rules    []VendorRule `json:"rules"`
+	Fallback []string     `json:"fallback"`
+	Probe    bool         `json:"probe"`
+}
+
+// DefaultVendorPolicy falls back to the Cray defaults, which is all REDS
+// used to use.
+var DefaultVendorPolicy = VendorPolicy{
+	Fallback: []string{"Cray"},
+}
+
+var vendorPolicy = DefaultVendorPolicy
+var vendorPolicyLock sync.RWMutex
+
+// How long the Redfish service root of a BMC is given to answer
+var vendorProbeTimeout = 5 * time.Second
+
+// Shared by all vendor probes so their connections are reused rather than
+// left idle.  BMCs have self-signed certificates until they're onboarded.
+var vendorProbeClient = &http.Client{
+	Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		IdleConnTimeout: 30 * time.Second,
+	},
+}
+
+// probeVendorFunc finds the vendor of a BMC from Redfish.  Replaced in tests.
+var probeVendorFunc = probeVendor
+
+// Vendor names as BMCs and admins spell them, mapped to the names used for
+// the default credentials
+var vendorAliases = map[string]string{
+	"cray":                       "Cray",
+	"cray inc":                   "Cray",
+	"cray inc.":                  "Cray",
+	"hpe":                        "HPE",
+	"hp":                         "HPE",
+	"hewlett packard enterprise": "HPE",
+	"hewlett-packard enterprise": "HPE",
+	"gigabyte":                   "Gigabyte",
+	"giga computing":             "Gigabyte",
+	"giga-byte technology co.":   "Gigabyte",
+	"intel":                      "Intel",
+	"intel corporation":          "Intel",
+	"supermicro":                 "Supermicro",
+	"super micro computer, inc.": "Supermicro",
+	"dell":                       "Dell",
+	"dell inc.":                  "Dell",
+	"lenovo":                     "Lenovo",
+	"american megatrends":        "AMI",
+	"american megatrends inc.":   "AMI",
+	"ami":                        "AMI",
+	"american megatrends, inc.":  "AMI",
+}
+
+func (p VendorPolicy) String() string {
+	return fmt.Sprintf("{ Rules: %v, Fallback: %v, Probe: %t }", p.Rules, p.Fallback, p.Probe)
+}
+
+// Validate checks that every rule and fallback names something.
+func (p VendorPolicy) Validate() error {
+	for _, rule := range p.Rules {
+		if strings.TrimSpace(rule.XnamePrefix) == "" || strings.TrimSpace(rule.Vendor) == "" {
+			return fmt.Errorf("vendor rule %+v needs an xnamePrefix and a vendor", rule)
+		}
+	}
+	for _, vendor := range p.Fallback {
+		if strings.TrimSpace(vendor) == "" {
+			return errors.New("vendor policy has an empty fallback")
+		}
+	}
+	return nil
+}
+
+// SetVendorPolicy replaces the policy used to pick default credentials.
+func SetVendorPolicy(p VendorPolicy) error {
+	err := p.Validate()
+	if err != nil {
+		return err
+	}
+	vendorPolicyLock.Lock()
+	defer vendorPolicyLock.Unlock()
+	vendorPolicy = p
+	log.Printf("INFO: Vendor policy set to %s", p)
+	return nil
+}
+
+// GetVendorPolicy returns the policy used to pick default credentials.
+func GetVendorPolicy() VendorPolicy {
+	vendorPolicyLock.RLock()
+	defer vendorPolicyLock.RUnlock()
+	return vendorPolicy
+}
+
+// LoadVendorPolicy reads a JSON vendor policy from a file.
+func LoadVendorPolicy(path string) (VendorPolicy, error) {
+	var p VendorPolicy
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(data, &p)
+	if err != nil {
+		return p, fmt.Errorf("unable to parse vendor policy %s: %s", path, err)
+	}
+	return p, p.Validate()
+}
+
+// canonicalVendor maps the spellings of a vendor to one name.
+func canonicalVendor(vendor string) string {
+	vendor = strings.TrimSpace(vendor)
+	if canon, ok := vendorAliases[strings.ToLower(vendor)]; ok {
+		return canon
+	}
+	return vendor
+}
+
+// resolveVendor works out the vendor of a node's BMC, and where it came
+// from.  It returns "" if the vendor can't be found.
+func (p VendorPolicy) resolveVendor(ctx context.Context, node GenericHardware) (string, string) {
+	if vendor := extraPropertyString(node, SLS_BMC_VENDOR_PROPERTY); vendor != "" {
+		return canonicalVendor(vendor), VENDOR_SOURCE_SLS
+	}
+	for _, rule := range p.Rules {
+		if strings.HasPrefix(strings.ToLower(node.Parent), strings.ToLower(rule.XnamePrefix)) {
+			return canonicalVendor(rule.Vendor), VENDOR_SOURCE_RULE
+		}
+	}
+	if p.Probe {
+		host := extraPropertyString(node, SLS_BMC_IP_PROPERTY)
+		if host == "" {
+			host = node.Parent
+		}
+		vendor, err := probeVendorFunc(ctx, host)
+		if err != nil {
+			log.Printf("WARNING: Unable to get the vendor of %s from Redfish: %s", node.Parent, err)
+		} else if vendor != "" {
+			return canonicalVendor(vendor), VENDOR_SOURCE_REDFISH
+		}
+	}
+	return "", ""
+}
+
+// lookupVendor finds a vendor's entry in the default credentials, ignoring
+// case and spelling.  Entries without a username and password don't count.
+func lookupVendor(defaults map[string]model.RedsCredentials, vendor string) (string, bool) {
+	vendor = canonicalVendor(vendor)
+	var keys []string
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		creds := defaults[key]
+		if strings.EqualFold(canonicalVendor(key), vendor) && creds.Username != "" && creds.Password != "" {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// selectDefaultCredentials picks the default credentials for a node's BMC.
+// It returns the credentials, the vendor entry they came from and how that
+// vendor was chosen.
+func selectDefaultCredentials(ctx context.Context, node GenericHardware,
+	defaults map[string]model.RedsCredentials) (model.RedsCredentials, string, string, error) {
+	p := GetVendorPolicy()
+	vendor, source := p.resolveVendor(ctx, node)
+	if vendor != "" {
+		if key, ok := lookupVendor(defaults, vendor); ok {
+			return defaults[key], key, source, nil
+		}
+		log.Printf("WARNING: No default credentials for %s vendor %s (from %s), falling back",
+			node.Parent, vendor, source)
+	}
+	for _, fallback := range p.Fallback {
+		if key, ok := lookupVendor(defaults, fallback); ok {
+			return defaults[key], key, VENDOR_SOURCE_FALLBACK, nil
+		}
+	}
+	return model.RedsCredentials{}, "", "", fmt.Errorf("no default credentials for %s: vendor %q, fallbacks %v",
+		node.Parent, vendor, p.Fallback)
+}
+
+// Just the parts of a Redfish service root that say who made the BMC
+type redfishServiceRoot struct {
+	Vendor string                 `json:"Vendor"`
+	Oem    map[string]interface{} `json:"Oem"`
+}
+
+// probeVendor asks a BMC's Redfish service root, which needs no
+// credentials, who made it.  Older BMCs have no Vendor field; the name of
+// their Oem section is used instead.
+func probeVendor(ctx context.Context, host string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, vendorProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, "https://"+host+"/redfish/v1/", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := vendorProbeClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("service root answered %s", resp.Status)
+	}
+	var root redfishServiceRoot
+	if err := json.NewDecoder(resp.Body).Decode(&root); err != nil {
+		return "", err
+	}
+	if root.Vendor != "" {
+		return root.Vendor, nil
+	}
+	var oems []string
+	for oem := range root.Oem {
+		oems = append(oems, oem)
+	}
+	if len(oems) == 1 {
+		return oems[0], nil
+	}
+	return "", nil
+}
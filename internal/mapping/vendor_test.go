@@ -0,0 +1,144 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Cray-HPE/hms-reds/internal/model"
+)
+
+func vendorTestNode(bmc string, props map[string]interface{}) GenericHardware {
+	return GenericHardware{
+		Parent:             bmc,
+		Xname:              bmc + "n0",
+		Class:              "River",
+		ExtraPropertiesRaw: props,
+	}
+}
+
+func Test_selectDefaultCredentials(t *testing.T) {
+	defaults := map[string]model.RedsCredentials{
+		"Cray":     {Username: "root", Password: "cray"},
+		"gigabyte": {Username: "admin", Password: "gigabyte"},
+		"HPE":      {Username: "Administrator", Password: ""},
+		"Intel":    {Username: "intel", Password: "intel"},
+	}
+	defer SetVendorPolicy(DefaultVendorPolicy)
+	defer func() { probeVendorFunc = probeVendor }()
+	probeVendorFunc = func(ctx context.Context, host string) (string, error) {
+		switch host {
+		case "10.254.1.30":
+			return "Intel Corporation", nil
+		case "x3000c0s31b0":
+			return "", errors.New("connection refused")
+		}
+		return "", nil
+	}
+	err := SetVendorPolicy(VendorPolicy{
+		Rules:    []VendorRule{{XnamePrefix: "x3000c0s2", Vendor: "Giga Computing"}},
+		Fallback: []string{"Supermicro", "Cray"},
+		Probe:    true,
+	})
+	if err != nil {
+		t.Fatalf("SetVendorPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		node       GenericHardware
+		wantUser   string
+		wantVendor string
+		wantSource string
+	}{
+		{"SLS", vendorTestNode("x3000c0s1b0", map[string]interface{}{"BMCVendor": "GIGABYTE"}),
+			"admin", "gigabyte", VENDOR_SOURCE_SLS},
+		{"rule", vendorTestNode("x3000c0s21b0", nil), "admin", "gigabyte", VENDOR_SOURCE_RULE},
+		{"SLS over rule", vendorTestNode("x3000c0s22b0", map[string]interface{}{"BMCVendor": "Intel"}),
+			"intel", "Intel", VENDOR_SOURCE_SLS},
+		{"Redfish", vendorTestNode("x3000c0s30b0", map[string]interface{}{"BMCIPAddress": "10.254.1.30"}),
+			"intel", "Intel", VENDOR_SOURCE_REDFISH},
+		{"Redfish failure", vendorTestNode("x3000c0s31b0", nil), "root", "Cray", VENDOR_SOURCE_FALLBACK},
+		{"vendor without a password", vendorTestNode("x3000c0s32b0", map[string]interface{}{"BMCVendor": "HPE"}),
+			"root", "Cray", VENDOR_SOURCE_FALLBACK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, vendor, source, err := selectDefaultCredentials(context.Background(), tt.node, defaults)
+			if err != nil || creds.Username != tt.wantUser || vendor != tt.wantVendor || source != tt.wantSource {
+				t.Errorf("selectDefaultCredentials() = %s, %q, %q, %v, want %s, %q, %q",
+					creds, vendor, source, err, tt.wantUser, tt.wantVendor, tt.wantSource)
+			}
+		})
+	}
+
+	delete(defaults, "Cray")
+	_, _, _, err = selectDefaultCredentials(context.Background(), vendorTestNode("x3000c0s31b0", nil), defaults)
+	if err == nil || !strings.Contains(err.Error(), "x3000c0s31b0") {
+		t.Errorf("selectDefaultCredentials() with no usable defaults error = %v", err)
+	}
+}
+
+func TestVendorPolicy_Validate(t *testing.T) {
+	if err := (VendorPolicy{Rules: []VendorRule{{XnamePrefix: "x3000", Vendor: " "}}}).Validate(); err == nil {
+		t.Errorf("Rule without a vendor should be invalid")
+	}
+	if err := (VendorPolicy{Fallback: []string{"Cray", ""}}).Validate(); err == nil {
+		t.Errorf("Empty fallback should be invalid")
+	}
+	if err := DefaultVendorPolicy.Validate(); err != nil {
+		t.Errorf("DefaultVendorPolicy.Validate() error = %v", err)
+	}
+}
+
+func Test_probeVendor(t *testing.T) {
+	root := `{"RedfishVersion":"1.6.0","Vendor":"Gigabyte"}`
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Probe sent credentials")
+		}
+		switch r.URL.Path {
+		case "/redfish/v1/":
+			w.Write([]byte(root))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "https://")
+
+	if vendor, err := probeVendor(context.Background(), host); err != nil || vendor != "Gigabyte" {
+		t.Errorf("probeVendor() = %q, %v, want Gigabyte", vendor, err)
+	}
+
+	// Older BMCs only have an Oem section.
+	root = `{"RedfishVersion":"1.0.2","Oem":{"Hpe":{}}}`
+	if vendor, err := probeVendor(context.Background(), host); err != nil || canonicalVendor(vendor) != "HPE" {
+		t.Errorf("probeVendor() of an older BMC = %q, %v, want Hpe", vendor, err)
+	}
+}
@@ -0,0 +1,308 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/verify"
+)
+
+// Credential verification modes
+const (
+	// Credentials aren't checked.
+	VERIFY_MODE_OFF = "off"
+	// Credentials are checked and the outcome reported in the status.
+	VERIFY_MODE_REPORT = "report"
+	// As report, and BMCs whose credentials are rejected aren't registered.
+	VERIFY_MODE_BLOCK = "block"
+)
+
+// Time allowed for checking a switch's credentials if none is given
+const DefaultVerifyTimeout = 10 * time.Second
+
+// Number of switches checked at a time
+const switchVerifyWorkers = 10
+
+// How long a switch that failed its check is left before it is checked
+// again with the same credentials
+var switchRecheckInterval = 5 * time.Minute
+
+var verifyMode = VERIFY_MODE_OFF
+var bmcVerifier verify.BMCVerifier
+var switchVerifier verify.SwitchVerifier
+var switchVerifyTimeout = DefaultVerifyTimeout
+var verifyLock sync.RWMutex
+
+// SetCredentialVerification sets whether the credentials of BMCs and
+// switches are checked before use, and what checks them.  Each switch is
+// allowed timeout.
+func SetCredentialVerification(mode string, bmc verify.BMCVerifier, sw verify.SwitchVerifier,
+	timeout time.Duration) error {
+	switch mode {
+	case VERIFY_MODE_OFF:
+	case VERIFY_MODE_REPORT, VERIFY_MODE_BLOCK:
+		if bmc == nil || sw == nil {
+			return fmt.Errorf("credential verification needs BMC and switch verifiers")
+		}
+	default:
+		return fmt.Errorf("unknown credential verification mode %q", mode)
+	}
+	verifyLock.Lock()
+	defer verifyLock.Unlock()
+	verifyMode = mode
+	bmcVerifier = bmc
+	switchVerifier = sw
+	switchVerifyTimeout = timeout
+	if timeout <= 0 {
+		switchVerifyTimeout = DefaultVerifyTimeout
+	}
+	return nil
+}
+
+func credentialVerification() (string, verify.BMCVerifier, verify.SwitchVerifier) {
+	verifyLock.RLock()
+	defer verifyLock.RUnlock()
+	return verifyMode, bmcVerifier, switchVerifier
+}
+
+// verifyBMCCredentials checks that a node's BMC takes the credentials in
+// Vault and records the outcome in its status.  It returns an error only if
+// registration should be blocked.  A BMC that just got a generated password
+// isn't checked again: setting it needed the defaults to log in, and the
+// BMC accepted the change.
+func verifyBMCCredentials(ctx context.Context, node GenericHardware, creds compcredentials.CompCredentials,
+	generated bool) error {
+	mode, verifier, _ := credentialVerification()
+	if mode == VERIFY_MODE_OFF {
+		return nil
+	}
+	if generated {
+		setNodeStatus(node, func(status *NodeStatus) {
+			status.CredentialCheck = verify.CHECK_VERIFIED
+			status.CredentialCheckError = ""
+		})
+		return nil
+	}
+
+	host := extraPropertyString(node, SLS_BMC_IP_PROPERTY)
+	if host == "" {
+		host = node.Parent
+	}
+	err := verifier.VerifyBMC(ctx, host, creds.Username, creds.Password)
+	outcome := verify.Outcome(err)
+	setNodeStatus(node, func(status *NodeStatus) {
+		status.CredentialCheck = outcome
+		status.CredentialCheckError = ""
+		if err != nil {
+			status.CredentialCheckError = err.Error()
+		}
+	})
+	switch outcome {
+	case verify.CHECK_VERIFIED:
+		log.Printf("INFO: Verified the credentials of %s", node.Parent)
+		return nil
+	case verify.CHECK_UNREACHABLE:
+		// HSM keeps trying to discover it; the credentials may yet work.
+		log.Printf("WARNING: Unable to verify the credentials of %s: %s", node.Parent, err)
+		return nil
+	}
+	log.Printf("ERROR: %s rejected its credentials: %s", node.Parent, err)
+	if mode != VERIFY_MODE_BLOCK {
+		return nil
+	}
+	err = fmt.Errorf("not registering %s, it rejected its credentials: %s", node.Parent, err)
+	setNodeStatus(node, func(status *NodeStatus) {
+		status.State = NODE_STATE_REGISTRATION_FAILED
+		status.Error = err.Error()
+	})
+	return err
+}
+
+// SwitchStatus is the outcome of checking a switch's SNMP credentials.
+type SwitchStatus struct {
+	Switch               string `json:"switch"`
+	Address              string `json:"address"`
+	CredentialCheck      string `json:"credentialCheck"`
+	CredentialCheckError string `json:"credentialCheckError,omitempty"`
+	Updated              string `json:"updated"`
+}
+
+var switchStatuses = make(map[string]SwitchStatus)
+
+// The last check of a switch's credentials, so unchanged credentials that
+// worked aren't checked every pass and ones that didn't only now and then
+type switchCheck struct {
+	fingerprint [sha256.Size]byte
+	verified    bool
+	checked     time.Time
+}
+
+var switchChecks = make(map[string]switchCheck)
+
+// Switches being checked in the background
+var switchChecking = make(map[string]bool)
+var switchStatusLock sync.Mutex
+
+func snmpFingerprint(address string, creds verify.SNMPCredentials) [sha256.Size]byte {
+	return sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s", address,
+		creds.Username, creds.SecurityLevel, creds.AuthProtocol, creds.AuthPassword, creds.PrivProtocol,
+		creds.PrivPassword, creds.Context)))
+}
+
+func switchSNMPCredentials(sw Switch) verify.SNMPCredentials {
+	return verify.SNMPCredentials{
+		Username:      sw.SnmpUser,
+		AuthProtocol:  sw.SnmpAuthProtocol,
+		AuthPassword:  sw.SnmpAuthPassword,
+		PrivProtocol:  sw.SnmpPrivProtocol,
+		PrivPassword:  sw.SnmpPrivPassword,
+		SecurityLevel: sw.SnmpSecurityLevel,
+		Context:       sw.SnmpContext,
+	}
+}
+
+// switchNeedsCheck reports whether a switch's credentials are new to us, or
+// failed their last check long enough ago to be tried again.  Callers hold
+// switchStatusLock.
+func switchNeedsCheck(sw Switch, now time.Time) bool {
+	last, ok := switchChecks[sw.Id]
+	if !ok || last.fingerprint != snmpFingerprint(sw.Address, switchSNMPCredentials(sw)) {
+		return true
+	}
+	return !last.verified && now.Sub(last.checked) >= switchRecheckInterval
+}
+
+// checkSwitchCredentials checks the SNMP credentials of the switches that
+// need it in the background, switchVerifyWorkers at a time, so unreachable
+// switches don't hold up the caller.  A switch still being checked from an
+// earlier call is left alone.
+func checkSwitchCredentials(switches []Switch) {
+	mode, _, _ := credentialVerification()
+	if mode == VERIFY_MODE_OFF {
+		return
+	}
+
+	var todo []Switch
+	now := time.Now()
+	switchStatusLock.Lock()
+	for _, sw := range switches {
+		if !switchChecking[sw.Id] && switchNeedsCheck(sw, now) {
+			switchChecking[sw.Id] = true
+			todo = append(todo, sw)
+		}
+	}
+	switchStatusLock.Unlock()
+	if len(todo) == 0 {
+		return
+	}
+
+	work := make(chan Switch, len(todo))
+	for _, sw := range todo {
+		work <- sw
+	}
+	close(work)
+	for i := 0; i < switchVerifyWorkers && i < len(todo); i++ {
+		go func() {
+			for sw := range work {
+				verifySwitchCredentials(sw)
+				switchStatusLock.Lock()
+				delete(switchChecking, sw.Id)
+				switchStatusLock.Unlock()
+			}
+		}()
+	}
+}
+
+// verifySwitchCredentials checks a switch takes its SNMP credentials and
+// records the outcome.  Switches aren't registered anywhere by REDS, so
+// nothing is blocked.
+func verifySwitchCredentials(sw Switch) {
+	mode, _, verifier := credentialVerification()
+	if mode == VERIFY_MODE_OFF {
+		return
+	}
+	verifyLock.RLock()
+	timeout := switchVerifyTimeout
+	verifyLock.RUnlock()
+	creds := switchSNMPCredentials(sw)
+	now := time.Now()
+	switchStatusLock.Lock()
+	needed := switchNeedsCheck(sw, now)
+	switchStatusLock.Unlock()
+	if !needed {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := verifier.VerifySwitch(ctx, sw.Address, creds)
+	status := SwitchStatus{
+		Switch:          sw.Id,
+		Address:         sw.Address,
+		CredentialCheck: verify.Outcome(err),
+		Updated:         time.Now().Format(time.RFC3339),
+	}
+	switch status.CredentialCheck {
+	case verify.CHECK_VERIFIED:
+		log.Printf("INFO: Verified the SNMP credentials of %s", sw.Id)
+	case verify.CHECK_REJECTED:
+		log.Printf("ERROR: %s rejected its SNMP credentials: %s", sw.Id, err)
+	default:
+		log.Printf("WARNING: Unable to verify the SNMP credentials of %s: %s", sw.Id, err)
+	}
+	if err != nil {
+		status.CredentialCheckError = err.Error()
+	}
+
+	switchStatusLock.Lock()
+	defer switchStatusLock.Unlock()
+	switchStatuses[sw.Id] = status
+	switchChecks[sw.Id] = switchCheck{
+		fingerprint: snmpFingerprint(sw.Address, creds),
+		verified:    err == nil,
+		checked:     now,
+	}
+}
+
+// GetSwitchStatuses returns the credential check of every switch REDS has
+// checked, ordered by xname.
+func GetSwitchStatuses() []SwitchStatus {
+	switchStatusLock.Lock()
+	defer switchStatusLock.Unlock()
+	statuses := make([]SwitchStatus, 0, len(switchStatuses))
+	for _, status := range switchStatuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Switch < statuses[j].Switch
+	})
+	return statuses
+}
@@ -0,0 +1,233 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package mapping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/verify"
+)
+
+// stubVerifier takes only the password "good" and records who it was
+// asked about.
+type stubVerifier struct {
+	mu    sync.Mutex
+	asked []string
+	err   error
+	// Closed to let checks through, if set
+	hold chan struct{}
+}
+
+func (v *stubVerifier) check(address string, password string) error {
+	if v.hold != nil {
+		<-v.hold
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.asked = append(v.asked, address)
+	if v.err != nil {
+		return v.err
+	}
+	if password != "good" {
+		return fmt.Errorf("%w: 401 Unauthorized", verify.ErrRejected)
+	}
+	return nil
+}
+
+func (v *stubVerifier) VerifyBMC(ctx context.Context, host string, username string, password string) error {
+	return v.check(host, password)
+}
+
+func (v *stubVerifier) VerifySwitch(ctx context.Context, address string, creds verify.SNMPCredentials) error {
+	return v.check(address, creds.AuthPassword)
+}
+
+func Test_verifyBMCCredentials(t *testing.T) {
+	defer SetCredentialVerification(VERIFY_MODE_OFF, nil, nil, 0)
+	if err := SetCredentialVerification(VERIFY_MODE_BLOCK, nil, nil, 0); err == nil {
+		t.Errorf("SetCredentialVerification() without verifiers should fail")
+	}
+	if err := SetCredentialVerification("sometimes", nil, nil, 0); err == nil {
+		t.Errorf("SetCredentialVerification() with an unknown mode should fail")
+	}
+
+	v := &stubVerifier{}
+	node := vendorTestNode("x3000c0s31b0", map[string]interface{}{"BMCIPAddress": "10.254.1.31"})
+	good := compcredentials.CompCredentials{Xname: "x3000c0s31b0", Username: "root", Password: "good"}
+	bad := compcredentials.CompCredentials{Xname: "x3000c0s31b0", Username: "root", Password: "bad"}
+
+	// Off by default
+	if err := verifyBMCCredentials(context.Background(), node, bad, false); err != nil || len(v.asked) != 0 {
+		t.Errorf("verifyBMCCredentials() when off = %v after asking %v", err, v.asked)
+	}
+
+	SetCredentialVerification(VERIFY_MODE_REPORT, v, v, 0)
+	if err := verifyBMCCredentials(context.Background(), node, good, false); err != nil {
+		t.Errorf("verifyBMCCredentials() error = %v", err)
+	}
+	if st, _ := GetNodeStatus("x3000c0s31b0"); st.CredentialCheck != verify.CHECK_VERIFIED ||
+		len(v.asked) != 1 || v.asked[0] != "10.254.1.31" {
+		t.Errorf("Status after good credentials = %+v, asked %v", st, v.asked)
+	}
+	if err := verifyBMCCredentials(context.Background(), node, bad, false); err != nil {
+		t.Errorf("verifyBMCCredentials() when reporting error = %v", err)
+	}
+	if st, _ := GetNodeStatus("x3000c0s31b0"); st.CredentialCheck != verify.CHECK_REJECTED ||
+		st.CredentialCheckError == "" || st.State == NODE_STATE_REGISTRATION_FAILED {
+		t.Errorf("Status after reporting bad credentials = %+v", st)
+	}
+
+	SetCredentialVerification(VERIFY_MODE_BLOCK, v, v, 0)
+	if err := verifyBMCCredentials(context.Background(), node, bad, false); err == nil {
+		t.Errorf("verifyBMCCredentials() should block bad credentials")
+	}
+	if st, _ := GetNodeStatus("x3000c0s31b0"); st.State != NODE_STATE_REGISTRATION_FAILED {
+		t.Errorf("Status after blocking bad credentials = %+v", st)
+	}
+
+	// An unreachable BMC isn't blocked; HSM keeps trying it.
+	v.err = errors.New("connection refused")
+	if err := verifyBMCCredentials(context.Background(), node, bad, false); err != nil {
+		t.Errorf("verifyBMCCredentials() of an unreachable BMC error = %v", err)
+	}
+	if st, _ := GetNodeStatus("x3000c0s31b0"); st.CredentialCheck != verify.CHECK_UNREACHABLE {
+		t.Errorf("Status of an unreachable BMC = %+v", st)
+	}
+
+	// Generated passwords were checked by setting them.
+	v.asked = nil
+	if err := verifyBMCCredentials(context.Background(), node, bad, true); err != nil || len(v.asked) != 0 {
+		t.Errorf("verifyBMCCredentials() of a generated password = %v after asking %v", err, v.asked)
+	}
+}
+
+func Test_verifySwitchCredentials(t *testing.T) {
+	defer SetCredentialVerification(VERIFY_MODE_OFF, nil, nil, 0)
+	switchStatusLock.Lock()
+	switchChecks = make(map[string]switchCheck)
+	switchStatusLock.Unlock()
+	v := &stubVerifier{}
+	SetCredentialVerification(VERIFY_MODE_REPORT, v, v, 0)
+
+	sw := Switch{Id: "x3000c0w31", Address: "10.254.0.31", SnmpUser: "testuser", SnmpAuthPassword: "bad"}
+	verifySwitchCredentials(sw)
+	verifySwitchCredentials(sw)
+	if len(v.asked) != 1 {
+		t.Errorf("Rejected credentials checked %d times, want once until the recheck interval", len(v.asked))
+	}
+	var found SwitchStatus
+	for _, st := range GetSwitchStatuses() {
+		if st.Switch == sw.Id {
+			found = st
+		}
+	}
+	if found.CredentialCheck != verify.CHECK_REJECTED || found.Address != "10.254.0.31" {
+		t.Errorf("Switch status = %+v", found)
+	}
+
+	defer func(interval time.Duration) { switchRecheckInterval = interval }(switchRecheckInterval)
+	switchRecheckInterval = 0
+	verifySwitchCredentials(sw)
+	if len(v.asked) != 2 {
+		t.Errorf("Rejected credentials weren't checked again after the recheck interval")
+	}
+
+	// Credentials that work aren't checked again until they change.
+	v.asked = nil
+	sw.SnmpAuthPassword = "good"
+	verifySwitchCredentials(sw)
+	verifySwitchCredentials(sw)
+	if len(v.asked) != 1 {
+		t.Errorf("Working credentials checked %d times, want once", len(v.asked))
+	}
+	sw.SnmpPrivProtocol = "AES"
+	verifySwitchCredentials(sw)
+	if len(v.asked) != 2 {
+		t.Errorf("Changed credentials weren't checked again")
+	}
+}
+
+func Test_checkSwitchCredentials(t *testing.T) {
+	defer SetCredentialVerification(VERIFY_MODE_OFF, nil, nil, 0)
+	switchStatusLock.Lock()
+	switchChecks = make(map[string]switchCheck)
+	switchStatusLock.Unlock()
+	v := &stubVerifier{hold: make(chan struct{})}
+	SetCredentialVerification(VERIFY_MODE_REPORT, v, v, 0)
+
+	var switches []Switch
+	for i := 0; i < switchVerifyWorkers+2; i++ {
+		switches = append(switches, Switch{Id: fmt.Sprintf("x3001c0w%d", i),
+			Address: fmt.Sprintf("10.254.1.%d", i), SnmpUser: "testuser", SnmpAuthPassword: "bad"})
+	}
+
+	// Switches that don't answer don't hold up the caller, and aren't
+	// checked twice at once.
+	done := make(chan struct{})
+	go func() {
+		checkSwitchCredentials(switches)
+		checkSwitchCredentials(switches)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("checkSwitchCredentials() waited for the checks")
+	}
+	close(v.hold)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		switchStatusLock.Lock()
+		checking := len(switchChecking)
+		switchStatusLock.Unlock()
+		if checking == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Switch checks didn't finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	v.mu.Lock()
+	asked := len(v.asked)
+	v.mu.Unlock()
+	if asked != len(switches) {
+		t.Errorf("Checked %d times, want once for each of %d switches", asked, len(switches))
+	}
+	rejected := 0
+	for _, st := range GetSwitchStatuses() {
+		if st.CredentialCheck == verify.CHECK_REJECTED && st.Switch[:5] == "x3001" {
+			rejected++
+		}
+	}
+	if rejected != len(switches) {
+		t.Errorf("%d switch statuses rejected, want %d", rejected, len(switches))
+	}
+}
@@ -29,11 +29,11 @@ import (
 	sstorage "github.com/Cray-HPE/hms-securestorage"
 )
 
-const CredentialsKeyPrefix = "secret/reds-cred"
-
 type RedsCredStore struct {
 	CCPath string
 	SS     sstorage.SecureStorage
+	// Who changes to the default credentials are recorded as in their history
+	Actor string
 }
 
 type RedsCredentials struct {
@@ -47,15 +47,40 @@ func (redsCred RedsCredentials) String() string {
 	return fmt.Sprintf("Username: %s, Password: <REDACTED>", redsCred.Username)
 }
 
+// SwitchCredentials is an SNMPv3 profile for switches.  Empty protocols and
+// security level are left to SLS, or worked out from the passwords given.
 type SwitchCredentials struct {
-	SNMPUsername     string
-	SNMPAuthPassword string
-	SNMPPrivPassword string
+	SNMPUsername      string
+	SNMPAuthPassword  string
+	SNMPPrivPassword  string
+	SNMPSecurityLevel string `json:",omitempty"`
+	SNMPAuthProtocol  string `json:",omitempty"`
+	SNMPPrivProtocol  string `json:",omitempty"`
+	SNMPContext       string `json:",omitempty"`
+	// Profiles for particular switch models, keyed by the SLS Model.  Fields
+	// set here override the ones above.
+	Models map[string]SwitchCredentials `json:",omitempty"`
 }
 
 func (switchCredentials SwitchCredentials) String() string {
-	return fmt.Sprintf("SNMPUsername: %s, SNMPAuthPassword: <REDACTED>, SNMPPrivPassword: <REDACTED>",
+	ret := fmt.Sprintf("SNMPUsername: %s, SNMPAuthPassword: <REDACTED>, SNMPPrivPassword: <REDACTED>",
 		switchCredentials.SNMPUsername)
+	if switchCredentials.SNMPSecurityLevel != "" {
+		ret += ", SNMPSecurityLevel: " + switchCredentials.SNMPSecurityLevel
+	}
+	if switchCredentials.SNMPAuthProtocol != "" {
+		ret += ", SNMPAuthProtocol: " + switchCredentials.SNMPAuthProtocol
+	}
+	if switchCredentials.SNMPPrivProtocol != "" {
+		ret += ", SNMPPrivProtocol: " + switchCredentials.SNMPPrivProtocol
+	}
+	if switchCredentials.SNMPContext != "" {
+		ret += ", SNMPContext: " + switchCredentials.SNMPContext
+	}
+	if len(switchCredentials.Models) > 0 {
+		ret += fmt.Sprintf(", Models: %v", switchCredentials.Models)
+	}
+	return ret
 }
 
 // Create a new RedsCredStore struct that uses a SecureStorage backing store.
@@ -77,8 +102,12 @@ func (ccs *RedsCredStore) GetDefaultCredentials() (map[string]RedsCredentials, e
 }
 
 // StoreDefaultCredentials stores a map of default credentials, keyed by vendor.
+// The previous values are kept in the history.
 func (ccs *RedsCredStore) StoreDefaultCredentials(credentials map[string]RedsCredentials) error {
-	err := ccs.SS.Store(ccs.CCPath+"/defaults", credentials)
+	_, err := ccs.storeVersion(DEFAULTS_KIND_BMC, CredentialVersion{
+		Actor:    ccs.actor(),
+		Defaults: credentials,
+	})
 
 	if err != nil {
 		return errors.New("unable to store default credentials: " + err.Error())
@@ -86,9 +115,16 @@ func (ccs *RedsCredStore) StoreDefaultCredentials(credentials map[string]RedsCre
 	return nil
 }
 
-// StoreDefaultCredentials stores a map of default credentials, keyed by vendor.
+// StoreDefaultSwitchCredentials stores the default switch credentials.  The
+// previous values are kept in the history.
 func (ccs *RedsCredStore) StoreDefaultSwitchCredentials(credentials SwitchCredentials) error {
-	err := ccs.SS.Store(ccs.CCPath+"/switch_defaults", credentials)
+	if err := credentials.Validate(); err != nil {
+		return errors.New("invalid default switch credentials: " + err.Error())
+	}
+	_, err := ccs.storeVersion(DEFAULTS_KIND_SWITCH, CredentialVersion{
+		Actor:          ccs.actor(),
+		SwitchDefaults: &credentials,
+	})
 
 	if err != nil {
 		return errors.New("unable to store default switch credentials: " + err.Error())
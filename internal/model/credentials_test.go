@@ -49,9 +49,9 @@ func TestRedsCredentials_String(t *testing.T) {
 func TestRedsCredStore_GetDefaultCredentials(t *testing.T) {
 	// setup Vault mock
 	ss := NewKvMock()
-	credStorage := NewRedsCredStore(CredentialsKeyPrefix, ss)
+	credStorage := NewRedsCredStore(credentialsPath, ss)
 	credDefaults := map[string]RedsCredentials{"Cray": {Username: "groot", Password: "terminal6"}, "Cray ACE": {Username: "aceuser", Password: "acepass"}, "Gigabyte": {Username: "gigabyteuser", Password: "gigabytepass"}}
-	ss.Store(CredentialsKeyPrefix+"/defaults", credDefaults)
+	ss.Store(credentialsPath+"/defaults", credDefaults)
 
 	tests := []struct {
 		name    string
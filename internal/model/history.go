@@ -0,0 +1,334 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Kinds of default credentials with a version history, named after where
+// they are kept under the RedsCredStore path
+const (
+	DEFAULTS_KIND_BMC    = "defaults"
+	DEFAULTS_KIND_SWITCH = "switch_defaults"
+)
+
+// Number of versions kept of each kind of default credentials
+const MaxCredentialVersions = 20
+
+// Shown instead of passwords
+const REDACTED = "<REDACTED>"
+
+// ErrNoSuchVersion is returned for a version that isn't in the history.
+var ErrNoSuchVersion = errors.New("no such version")
+
+// CredentialVersion is one version of the BMC or switch default
+// credentials.  Versions stored before history was kept have no time or
+// actor.
+type CredentialVersion struct {
+	Version int    `json:"version"`
+	Time    string `json:"time,omitempty"`
+	// Who stored this version
+	Actor string `json:"actor,omitempty"`
+	// The version this one restored, if it was made by a rollback
+	RollbackOf int `json:"rollbackOf,omitempty"`
+
+	Defaults       map[string]RedsCredentials `json:"defaults,omitempty"`
+	SwitchDefaults *SwitchCredentials         `json:"switchDefaults,omitempty"`
+}
+
+// Stored in a struct; Vault can't take a list on its own.
+type credentialHistory struct {
+	Versions []CredentialVersion
+}
+
+// CredentialChange is a difference between two versions of default
+// credentials.  Passwords are only shown as REDACTED.
+type CredentialChange struct {
+	// Vendor for BMC defaults, "switch" for switch defaults
+	Key    string `json:"key"`
+	Field  string `json:"field"`
+	Change string `json:"change"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// Kinds of CredentialChange
+const (
+	CHANGE_ADDED   = "added"
+	CHANGE_REMOVED = "removed"
+	CHANGE_CHANGED = "changed"
+)
+
+func checkDefaultsKind(kind string) error {
+	if kind != DEFAULTS_KIND_BMC && kind != DEFAULTS_KIND_SWITCH {
+		return fmt.Errorf("unknown kind of default credentials %q", kind)
+	}
+	return nil
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return REDACTED
+}
+
+// Redacted returns a copy of v with the passwords replaced by REDACTED.
+func (v CredentialVersion) Redacted() CredentialVersion {
+	ret := v
+	if v.Defaults != nil {
+		ret.Defaults = make(map[string]RedsCredentials, len(v.Defaults))
+		for vendor, creds := range v.Defaults {
+			creds.Password = redact(creds.Password)
+			ret.Defaults[vendor] = creds
+		}
+	}
+	if v.SwitchDefaults != nil {
+		creds := redactSwitch(*v.SwitchDefaults)
+		ret.SwitchDefaults = &creds
+	}
+	return ret
+}
+
+func redactSwitch(creds SwitchCredentials) SwitchCredentials {
+	creds.SNMPAuthPassword = redact(creds.SNMPAuthPassword)
+	creds.SNMPPrivPassword = redact(creds.SNMPPrivPassword)
+	if creds.Models != nil {
+		models := make(map[string]SwitchCredentials, len(creds.Models))
+		for name, m := range creds.Models {
+			models[name] = redactSwitch(m)
+		}
+		creds.Models = models
+	}
+	return creds
+}
+
+func (v CredentialVersion) empty() bool {
+	return len(v.Defaults) == 0 && (v.SwitchDefaults == nil || reflect.DeepEqual(*v.SwitchDefaults, SwitchCredentials{}))
+}
+
+// DiffField adds the change to one field, if there is one.  Secret values
+// are redacted.
+func DiffField(changes []CredentialChange, key, field, from, to string, secret bool) []CredentialChange {
+	if from == to {
+		return changes
+	}
+	change := CredentialChange{Key: key, Field: field, Change: CHANGE_CHANGED, From: from, To: to}
+	if secret {
+		change.From = redact(from)
+		change.To = redact(to)
+	}
+	switch {
+	case from == "":
+		change.Change = CHANGE_ADDED
+	case to == "":
+		change.Change = CHANGE_REMOVED
+	}
+	return append(changes, change)
+}
+
+// DiffCredentialVersions lists what changed between two versions, vendors
+// in order.
+func DiffCredentialVersions(from, to CredentialVersion) []CredentialChange {
+	changes := []CredentialChange{}
+
+	vendors := make(map[string]bool)
+	for vendor := range from.Defaults {
+		vendors[vendor] = true
+	}
+	for vendor := range to.Defaults {
+		vendors[vendor] = true
+	}
+	var keys []string
+	for vendor := range vendors {
+		keys = append(keys, vendor)
+	}
+	sort.Strings(keys)
+	for _, vendor := range keys {
+		a, b := from.Defaults[vendor], to.Defaults[vendor]
+		changes = DiffField(changes, vendor, "username", a.Username, b.Username, false)
+		changes = DiffField(changes, vendor, "password", a.Password, b.Password, true)
+	}
+
+	var a, b SwitchCredentials
+	if from.SwitchDefaults != nil {
+		a = *from.SwitchDefaults
+	}
+	if to.SwitchDefaults != nil {
+		b = *to.SwitchDefaults
+	}
+	return diffSwitchModels(changes, "switch", a, b)
+}
+
+// diffSwitchModels adds the changes to an SNMP profile and each of its
+// models, keyed <key>/<model>, models in order.
+func diffSwitchModels(changes []CredentialChange, key string, a, b SwitchCredentials) []CredentialChange {
+	changes = diffSwitch(changes, key, a, b)
+
+	models := make(map[string]bool)
+	for name := range a.Models {
+		models[name] = true
+	}
+	for name := range b.Models {
+		models[name] = true
+	}
+	var keys []string
+	for name := range models {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		changes = diffSwitch(changes, key+"/"+name, a.Models[name], b.Models[name])
+	}
+	return changes
+}
+
+// diffSwitch adds the changes to one SNMP profile, leaving out its models.
+func diffSwitch(changes []CredentialChange, key string, a, b SwitchCredentials) []CredentialChange {
+	changes = DiffField(changes, key, "SNMPUsername", a.SNMPUsername, b.SNMPUsername, false)
+	changes = DiffField(changes, key, "SNMPAuthPassword", a.SNMPAuthPassword, b.SNMPAuthPassword, true)
+	changes = DiffField(changes, key, "SNMPPrivPassword", a.SNMPPrivPassword, b.SNMPPrivPassword, true)
+	changes = DiffField(changes, key, "SNMPSecurityLevel", a.SNMPSecurityLevel, b.SNMPSecurityLevel, false)
+	changes = DiffField(changes, key, "SNMPAuthProtocol", a.SNMPAuthProtocol, b.SNMPAuthProtocol, false)
+	changes = DiffField(changes, key, "SNMPPrivProtocol", a.SNMPPrivProtocol, b.SNMPPrivProtocol, false)
+	changes = DiffField(changes, key, "SNMPContext", a.SNMPContext, b.SNMPContext, false)
+	return changes
+}
+
+func (ccs *RedsCredStore) historyPath(kind string) string {
+	return ccs.CCPath + "/history/" + kind
+}
+
+// actor returns who changes through this store are recorded as.
+func (ccs *RedsCredStore) actor() string {
+	if ccs.Actor == "" {
+		return "unknown"
+	}
+	return ccs.Actor
+}
+
+// GetCredentialHistory returns the kept versions of a kind of default
+// credentials, oldest first.
+func (ccs *RedsCredStore) GetCredentialHistory(kind string) ([]CredentialVersion, error) {
+	if err := checkDefaultsKind(kind); err != nil {
+		return nil, err
+	}
+	var stored credentialHistory
+	err := ccs.SS.Lookup(ccs.historyPath(kind), &stored)
+
+	return stored.Versions, err
+}
+
+// GetCredentialVersion returns one version of a kind of default
+// credentials, or an error wrapping ErrNoSuchVersion.
+func (ccs *RedsCredStore) GetCredentialVersion(kind string, version int) (CredentialVersion, error) {
+	versions, err := ccs.GetCredentialHistory(kind)
+	if err != nil {
+		return CredentialVersion{}, err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return CredentialVersion{}, fmt.Errorf("%w: %s version %d", ErrNoSuchVersion, kind, version)
+}
+
+// currentDefaults returns what is stored now as a CredentialVersion.
+func (ccs *RedsCredStore) currentDefaults(kind string) (CredentialVersion, error) {
+	if kind == DEFAULTS_KIND_BMC {
+		creds, err := ccs.GetDefaultCredentials()
+		return CredentialVersion{Defaults: creds}, err
+	}
+	creds, err := ccs.GetDefaultSwitchCredentials()
+	return CredentialVersion{SwitchDefaults: &creds}, err
+}
+
+// storeVersion replaces the default credentials with v and adds it to the
+// history.  Defaults stored before history was kept are added first, so the
+// first change can be undone too.
+func (ccs *RedsCredStore) storeVersion(kind string, v CredentialVersion) (CredentialVersion, error) {
+	versions, err := ccs.GetCredentialHistory(kind)
+	if err != nil {
+		return CredentialVersion{}, fmt.Errorf("unable to get the history of the %s: %s", kind, err)
+	}
+	if len(versions) == 0 {
+		current, err := ccs.currentDefaults(kind)
+		if err != nil {
+			return CredentialVersion{}, fmt.Errorf("unable to get the current %s: %s", kind, err)
+		}
+		if !current.empty() {
+			current.Version = 1
+			versions = append(versions, current)
+		}
+	}
+
+	if kind == DEFAULTS_KIND_BMC {
+		err = ccs.SS.Store(ccs.CCPath+"/"+kind, v.Defaults)
+	} else {
+		err = ccs.SS.Store(ccs.CCPath+"/"+kind, *v.SwitchDefaults)
+	}
+	if err != nil {
+		return CredentialVersion{}, err
+	}
+
+	v.Version = 1
+	if len(versions) > 0 {
+		v.Version = versions[len(versions)-1].Version + 1
+	}
+	v.Time = time.Now().UTC().Format(time.RFC3339)
+	versions = append(versions, v)
+	if len(versions) > MaxCredentialVersions {
+		versions = versions[len(versions)-MaxCredentialVersions:]
+	}
+	err = ccs.SS.Store(ccs.historyPath(kind), credentialHistory{Versions: versions})
+	if err != nil {
+		return CredentialVersion{}, fmt.Errorf("stored the %s but not their history: %s", kind, err)
+	}
+	return v, nil
+}
+
+// RollbackDefaultCredentials makes a prior version of a kind of default
+// credentials the current one again, recording it as a new version by
+// actor.
+func (ccs *RedsCredStore) RollbackDefaultCredentials(kind string, version int, actor string) (CredentialVersion, error) {
+	prior, err := ccs.GetCredentialVersion(kind, version)
+	if err != nil {
+		return CredentialVersion{}, err
+	}
+	restored := CredentialVersion{
+		Actor:          actor,
+		RollbackOf:     prior.Version,
+		Defaults:       prior.Defaults,
+		SwitchDefaults: prior.SwitchDefaults,
+	}
+	if kind == DEFAULTS_KIND_SWITCH && restored.SwitchDefaults == nil {
+		restored.SwitchDefaults = &SwitchCredentials{}
+	}
+	return ccs.storeVersion(kind, restored)
+}
@@ -0,0 +1,141 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// asVault replaces what was stored under key with what Vault would hand
+// back: decoded JSON rather than the structs that went in.
+func asVault(ss *KvMock, key string) {
+	data, _ := json.Marshal(ss.storage[key])
+	var raw map[string]interface{}
+	json.Unmarshal(data, &raw)
+	ss.storage[key] = raw
+}
+
+func TestRedsCredStore_DefaultsHistory(t *testing.T) {
+	ss := NewKvMock()
+	credStorage := NewRedsCredStore(credentialsPath, ss)
+	credStorage.Actor = "vault_loader on test"
+
+	// Defaults from before history was kept
+	original := map[string]RedsCredentials{"Cray": {Username: "root", Password: "initial0"}}
+	ss.Store(credentialsPath+"/defaults", original)
+
+	bad := map[string]RedsCredentials{
+		"Cray": {Username: "admin", Password: "oops"},
+		"HPE":  {Username: "root", Password: "hpe"},
+	}
+	if err := credStorage.StoreDefaultCredentials(bad); err != nil {
+		t.Fatalf("StoreDefaultCredentials() error = %v", err)
+	}
+	asVault(ss, credentialsPath+"/history/defaults")
+
+	versions, err := credStorage.GetCredentialHistory(DEFAULTS_KIND_BMC)
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("GetCredentialHistory() = %+v, %v, want 2 versions", versions, err)
+	}
+	if versions[0].Version != 1 || versions[0].Actor != "" || !reflect.DeepEqual(versions[0].Defaults, original) {
+		t.Errorf("First version = %+v, want the defaults from before", versions[0])
+	}
+	if versions[1].Version != 2 || versions[1].Actor != "vault_loader on test" || versions[1].Time == "" ||
+		!reflect.DeepEqual(versions[1].Defaults, bad) {
+		t.Errorf("Second version = %+v", versions[1])
+	}
+
+	changes := DiffCredentialVersions(versions[0], versions[1])
+	want := []CredentialChange{
+		{Key: "Cray", Field: "username", Change: CHANGE_CHANGED, From: "root", To: "admin"},
+		{Key: "Cray", Field: "password", Change: CHANGE_CHANGED, From: REDACTED, To: REDACTED},
+		{Key: "HPE", Field: "username", Change: CHANGE_ADDED, To: "root"},
+		{Key: "HPE", Field: "password", Change: CHANGE_ADDED, To: REDACTED},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("DiffCredentialVersions() = %+v, want %+v", changes, want)
+	}
+	if redacted := versions[1].Redacted(); redacted.Defaults["HPE"].Password != REDACTED ||
+		versions[1].Defaults["HPE"].Password != "hpe" {
+		t.Errorf("Redacted() = %+v, original now %+v", redacted, versions[1])
+	}
+
+	restored, err := credStorage.RollbackDefaultCredentials(DEFAULTS_KIND_BMC, 1, "admin")
+	if err != nil || restored.Version != 3 || restored.RollbackOf != 1 || restored.Actor != "admin" {
+		t.Errorf("RollbackDefaultCredentials() = %+v, %v", restored, err)
+	}
+	if current, _ := credStorage.GetDefaultCredentials(); !reflect.DeepEqual(current, original) {
+		t.Errorf("Defaults after rolling back = %v, want %v", current, original)
+	}
+	if _, err := credStorage.RollbackDefaultCredentials(DEFAULTS_KIND_BMC, 42, "admin"); !errors.Is(err, ErrNoSuchVersion) {
+		t.Errorf("RollbackDefaultCredentials() of a missing version error = %v", err)
+	}
+	if _, err := credStorage.GetCredentialHistory("other_defaults"); err == nil {
+		t.Errorf("GetCredentialHistory() of an unknown kind should fail")
+	}
+
+	// Only the latest versions are kept.
+	for i := 0; i < MaxCredentialVersions+5; i++ {
+		credStorage.StoreDefaultCredentials(map[string]RedsCredentials{
+			"Cray": {Username: "root", Password: fmt.Sprintf("pass%d", i)},
+		})
+	}
+	versions, _ = credStorage.GetCredentialHistory(DEFAULTS_KIND_BMC)
+	if len(versions) != MaxCredentialVersions || versions[len(versions)-1].Version != MaxCredentialVersions+8 {
+		t.Errorf("Kept %d versions up to %d", len(versions), versions[len(versions)-1].Version)
+	}
+}
+
+func TestRedsCredStore_SwitchDefaultsHistory(t *testing.T) {
+	ss := NewKvMock()
+	credStorage := NewRedsCredStore(credentialsPath, ss)
+
+	first := SwitchCredentials{SNMPUsername: "testuser", SNMPAuthPassword: "auth1", SNMPPrivPassword: "priv1"}
+	second := SwitchCredentials{SNMPUsername: "testuser", SNMPAuthPassword: "auth2", SNMPPrivPassword: "priv1"}
+	credStorage.StoreDefaultSwitchCredentials(first)
+	credStorage.StoreDefaultSwitchCredentials(second)
+	asVault(ss, credentialsPath+"/history/switch_defaults")
+
+	versions, err := credStorage.GetCredentialHistory(DEFAULTS_KIND_SWITCH)
+	if err != nil || len(versions) != 2 || versions[0].Actor != "unknown" ||
+		!reflect.DeepEqual(*versions[1].SwitchDefaults, second) {
+		t.Fatalf("GetCredentialHistory() = %+v, %v", versions, err)
+	}
+	changes := DiffCredentialVersions(versions[0], versions[1])
+	want := []CredentialChange{{Key: "switch", Field: "SNMPAuthPassword", Change: CHANGE_CHANGED,
+		From: REDACTED, To: REDACTED}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("DiffCredentialVersions() = %+v, want %+v", changes, want)
+	}
+
+	if _, err := credStorage.RollbackDefaultCredentials(DEFAULTS_KIND_SWITCH, 1, "admin"); err != nil {
+		t.Fatalf("RollbackDefaultCredentials() error = %v", err)
+	}
+	if current, _ := credStorage.GetDefaultSwitchCredentials(); !reflect.DeepEqual(current, first) {
+		t.Errorf("Switch defaults after rolling back = %v, want %v", current, first)
+	}
+}
@@ -23,9 +23,13 @@ package model
 // OTHER DEALINGS IN THE SOFTWARE.
 
 import (
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
 	"github.com/mitchellh/mapstructure"
 )
 
+// Where the REDS credentials are kept by default
+var credentialsPath = securestore.DefaultConfig().RedsCredsPath()
+
 type KvMock struct {
 	storage map[string]interface{}
 }
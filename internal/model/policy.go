@@ -0,0 +1,289 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CredentialPolicy gives the credentials for the components it matches.  A
+// policy matches a component if every criterion it sets matches; criteria
+// left empty match anything.  Comparisons other than XnameRegex ignore case.
+type CredentialPolicy struct {
+	Name        string `json:"name"`
+	XnamePrefix string `json:"xnamePrefix,omitempty"`
+	XnameRegex  string `json:"xnameRegex,omitempty"`
+	Role        string `json:"role,omitempty"`
+	SubRole     string `json:"subRole,omitempty"`
+	// HMS type, e.g. NodeBMC or MgmtSwitch
+	Type string `json:"type,omitempty"`
+
+	// BMC credentials, used when seeding node BMCs
+	Credentials *RedsCredentials `json:"credentials,omitempty"`
+	// SNMP credentials, used when seeding switches
+	SwitchCredentials *SwitchCredentials `json:"switchCredentials,omitempty"`
+}
+
+// PolicyTarget is what a policy is matched against.
+type PolicyTarget struct {
+	Xname   string `json:"xname"`
+	Role    string `json:"role,omitempty"`
+	SubRole string `json:"subRole,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// Credential kinds a policy can give
+const (
+	POLICY_KIND_BMC    = "bmc"
+	POLICY_KIND_SWITCH = "switch"
+)
+
+// Due to the sensitive nature of the data in CredentialPolicy, make a custom
+// String function to prevent passwords from being printed.
+func (p CredentialPolicy) String() string {
+	return fmt.Sprintf("%s { XnamePrefix: %q, XnameRegex: %q, Role: %q, SubRole: %q, Type: %q, "+
+		"Credentials: %v, SwitchCredentials: %v }",
+		p.Name, p.XnamePrefix, p.XnameRegex, p.Role, p.SubRole, p.Type,
+		p.Credentials, p.SwitchCredentials)
+}
+
+// Validate checks that the policy has a name, something to match on, a
+// valid regex and some credentials, and that any SNMP profile is valid.
+func (p CredentialPolicy) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("credential policy has no name")
+	}
+	if p.XnamePrefix == "" && p.XnameRegex == "" && p.Role == "" && p.SubRole == "" && p.Type == "" {
+		return fmt.Errorf("credential policy %s would match everything", p.Name)
+	}
+	if p.XnameRegex != "" {
+		if _, err := regexp.Compile(p.XnameRegex); err != nil {
+			return fmt.Errorf("credential policy %s has an invalid xnameRegex: %s", p.Name, err)
+		}
+	}
+	if p.Credentials == nil && p.SwitchCredentials == nil {
+		return fmt.Errorf("credential policy %s has no credentials", p.Name)
+	}
+	if p.SwitchCredentials != nil {
+		if err := p.SwitchCredentials.Validate(); err != nil {
+			return fmt.Errorf("credential policy %s has invalid switch credentials: %s", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// Specificity says how narrowly a policy matches: an xname regex beats an
+// xname prefix, a longer prefix beats a shorter one, and any xname
+// criterion beats SubRole, which beats Role, which beats Type.  Criteria
+// add up.
+func (p CredentialPolicy) Specificity() int {
+	score := 0
+	if p.XnameRegex != "" {
+		score += 4000
+	}
+	if p.XnamePrefix != "" {
+		score += 1000 + len(p.XnamePrefix)
+	}
+	if p.SubRole != "" {
+		score += 200
+	}
+	if p.Role != "" {
+		score += 100
+	}
+	if p.Type != "" {
+		score += 50
+	}
+	return score
+}
+
+// Matches reports whether the policy applies to target.
+func (p CredentialPolicy) Matches(target PolicyTarget) bool {
+	if p.XnamePrefix != "" && !strings.HasPrefix(strings.ToLower(target.Xname), strings.ToLower(p.XnamePrefix)) {
+		return false
+	}
+	if p.XnameRegex != "" {
+		re, err := regexp.Compile(p.XnameRegex)
+		if err != nil || !re.MatchString(target.Xname) {
+			return false
+		}
+	}
+	if p.Role != "" && !strings.EqualFold(p.Role, target.Role) {
+		return false
+	}
+	if p.SubRole != "" && !strings.EqualFold(p.SubRole, target.SubRole) {
+		return false
+	}
+	if p.Type != "" && !strings.EqualFold(p.Type, target.Type) {
+		return false
+	}
+	return true
+}
+
+// hasKind reports whether the policy gives credentials of the given kind.
+func (p CredentialPolicy) hasKind(kind string) bool {
+	switch kind {
+	case POLICY_KIND_BMC:
+		return p.Credentials != nil
+	case POLICY_KIND_SWITCH:
+		return p.SwitchCredentials != nil
+	}
+	return false
+}
+
+// ResolveCredentialPolicy returns the most specific policy giving
+// credentials of the given kind that matches target.  Of equally specific
+// policies the first one wins.
+func ResolveCredentialPolicy(policies []CredentialPolicy, kind string, target PolicyTarget) (CredentialPolicy, bool) {
+	var best CredentialPolicy
+	found := false
+	for _, p := range policies {
+		if !p.hasKind(kind) || !p.Matches(target) {
+			continue
+		}
+		if !found || p.Specificity() > best.Specificity() {
+			best = p
+			found = true
+		}
+	}
+	return best, found
+}
+
+// How the policies are kept in the secure store, which only takes maps and
+// structs
+type credentialPolicies struct {
+	Policies []CredentialPolicy `json:"policies"`
+}
+
+// GetCredentialPolicies retrieves the credential policies from a secure
+// credentials store.
+func (ccs *RedsCredStore) GetCredentialPolicies() ([]CredentialPolicy, error) {
+	var stored credentialPolicies
+	err := ccs.SS.Lookup(ccs.CCPath+"/policies", &stored)
+
+	return stored.Policies, err
+}
+
+// StoreCredentialPolicies validates and stores the credential policies.
+func (ccs *RedsCredStore) StoreCredentialPolicies(policies []CredentialPolicy) error {
+	names := make(map[string]bool)
+	for _, p := range policies {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		if names[p.Name] {
+			return fmt.Errorf("credential policy %s is defined more than once", p.Name)
+		}
+		names[p.Name] = true
+	}
+
+	err := ccs.SS.Store(ccs.CCPath+"/policies", credentialPolicies{Policies: policies})
+	if err != nil {
+		return errors.New("unable to store credential policies: " + err.Error())
+	}
+	return nil
+}
+
+// DiffCredentialPolicies lists what changed between two sets of policies,
+// keyed policy/<name> and by name in order, with passwords redacted.  Since
+// the first of equally specific policies wins, a change in their order is
+// listed too, keyed "policies".
+func DiffCredentialPolicies(from, to []CredentialPolicy) []CredentialChange {
+	changes := []CredentialChange{}
+
+	byName := func(policies []CredentialPolicy) (map[string]CredentialPolicy, []string) {
+		m := make(map[string]CredentialPolicy, len(policies))
+		var names []string
+		for _, p := range policies {
+			m[p.Name] = p
+			names = append(names, p.Name)
+		}
+		return m, names
+	}
+	a, fromOrder := byName(from)
+	b, toOrder := byName(to)
+
+	names := make(map[string]bool)
+	for _, p := range append(append([]CredentialPolicy{}, from...), to...) {
+		names[p.Name] = true
+	}
+	var keys []string
+	for name := range names {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		pa, inA := a[name]
+		pb, inB := b[name]
+		key := "policy/" + name
+		var nameA, nameB string
+		if inA {
+			nameA = name
+		}
+		if inB {
+			nameB = name
+		}
+		changes = DiffField(changes, key, "name", nameA, nameB, false)
+		changes = DiffField(changes, key, "xnamePrefix", pa.XnamePrefix, pb.XnamePrefix, false)
+		changes = DiffField(changes, key, "xnameRegex", pa.XnameRegex, pb.XnameRegex, false)
+		changes = DiffField(changes, key, "role", pa.Role, pb.Role, false)
+		changes = DiffField(changes, key, "subRole", pa.SubRole, pb.SubRole, false)
+		changes = DiffField(changes, key, "type", pa.Type, pb.Type, false)
+
+		var ca, cb RedsCredentials
+		if pa.Credentials != nil {
+			ca = *pa.Credentials
+		}
+		if pb.Credentials != nil {
+			cb = *pb.Credentials
+		}
+		changes = DiffField(changes, key, "credentials.username", ca.Username, cb.Username, false)
+		changes = DiffField(changes, key, "credentials.password", ca.Password, cb.Password, true)
+
+		var sa, sb SwitchCredentials
+		if pa.SwitchCredentials != nil {
+			sa = *pa.SwitchCredentials
+		}
+		if pb.SwitchCredentials != nil {
+			sb = *pb.SwitchCredentials
+		}
+		changes = diffSwitchModels(changes, key+"/switch", sa, sb)
+	}
+
+	// Only the order of the policies in both sets matters
+	var common [2][]string
+	for _, name := range fromOrder {
+		if _, ok := b[name]; ok {
+			common[0] = append(common[0], name)
+		}
+	}
+	for _, name := range toOrder {
+		if _, ok := a[name]; ok {
+			common[1] = append(common[1], name)
+		}
+	}
+	return DiffField(changes, "policies", "order", strings.Join(common[0], ","), strings.Join(common[1], ","), false)
+}
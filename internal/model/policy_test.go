@@ -0,0 +1,152 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var testPolicies = []CredentialPolicy{
+	{Name: "bmcs", Type: "NodeBMC", Credentials: &RedsCredentials{Username: "root", Password: "bmc"}},
+	{Name: "masters", Role: "Management", SubRole: "Master",
+		Credentials: &RedsCredentials{Username: "root", Password: "master"}},
+	{Name: "cabinet-3000", XnamePrefix: "x3000",
+		Credentials: &RedsCredentials{Username: "root", Password: "x3000"}},
+	{Name: "chassis-3000c0", XnamePrefix: "x3000c0",
+		Credentials: &RedsCredentials{Username: "root", Password: "x3000c0"}},
+	{Name: "odd-slots", XnameRegex: `^x3000c0s[0-9]*[13579]b0$`,
+		Credentials: &RedsCredentials{Username: "root", Password: "odd"}},
+	{Name: "switches", Type: "MgmtSwitch",
+		SwitchCredentials: &SwitchCredentials{SNMPUsername: "snmp", SNMPAuthPassword: "a", SNMPPrivPassword: "p"}},
+}
+
+func TestResolveCredentialPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		target PolicyTarget
+		want   string
+	}{
+		{"type only", POLICY_KIND_BMC, PolicyTarget{Xname: "x1000c0s0b0", Type: "NodeBMC"}, "bmcs"},
+		{"role beats type", POLICY_KIND_BMC,
+			PolicyTarget{Xname: "x1000c0s0b0", Role: "management", SubRole: "master", Type: "NodeBMC"}, "masters"},
+		{"cabinet beats role", POLICY_KIND_BMC,
+			PolicyTarget{Xname: "x3000c1s2b0", Role: "Management", SubRole: "Master", Type: "NodeBMC"}, "cabinet-3000"},
+		{"longer prefix", POLICY_KIND_BMC, PolicyTarget{Xname: "x3000c0s2b0", Type: "NodeBMC"}, "chassis-3000c0"},
+		{"regex beats prefix", POLICY_KIND_BMC, PolicyTarget{Xname: "x3000c0s3b0", Type: "NodeBMC"}, "odd-slots"},
+		{"switch credentials only", POLICY_KIND_SWITCH, PolicyTarget{Xname: "x3000c0w14", Type: "MgmtSwitch"}, "switches"},
+		{"no match", POLICY_KIND_SWITCH, PolicyTarget{Xname: "x3000c0w14", Type: "MgmtHLSwitch"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ResolveCredentialPolicy(testPolicies, tt.kind, tt.target)
+			if ok != (tt.want != "") || got.Name != tt.want {
+				t.Errorf("ResolveCredentialPolicy() = %s, %t, want %q", got.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialPolicy_Validate(t *testing.T) {
+	creds := &RedsCredentials{Username: "root", Password: "pw"}
+	tests := []struct {
+		name    string
+		policy  CredentialPolicy
+		wantErr bool
+	}{
+		{"valid", CredentialPolicy{Name: "a", XnamePrefix: "x3000", Credentials: creds}, false},
+		{"no name", CredentialPolicy{XnamePrefix: "x3000", Credentials: creds}, true},
+		{"matches everything", CredentialPolicy{Name: "a", Credentials: creds}, true},
+		{"bad regex", CredentialPolicy{Name: "a", XnameRegex: "x3000(", Credentials: creds}, true},
+		{"no credentials", CredentialPolicy{Name: "a", Type: "NodeBMC"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedsCredStore_CredentialPolicies(t *testing.T) {
+	ss := NewKvMock()
+	credStorage := NewRedsCredStore(credentialsPath, ss)
+
+	if err := credStorage.StoreCredentialPolicies(append(testPolicies, testPolicies[0])); err == nil {
+		t.Errorf("StoreCredentialPolicies() with a duplicate name should fail")
+	}
+	if err := credStorage.StoreCredentialPolicies(testPolicies); err != nil {
+		t.Fatalf("StoreCredentialPolicies() error = %v", err)
+	}
+
+	// Vault hands back JSON, not the structs that went in.
+	data, _ := json.Marshal(ss.storage[credentialsPath+"/policies"])
+	var raw map[string]interface{}
+	json.Unmarshal(data, &raw)
+	ss.storage[credentialsPath+"/policies"] = raw
+
+	got, err := credStorage.GetCredentialPolicies()
+	if err != nil || !reflect.DeepEqual(got, testPolicies) {
+		t.Errorf("GetCredentialPolicies() = %v, %v, want %v", got, err, testPolicies)
+	}
+
+	if got := testPolicies[0].String(); got != `bmcs { XnamePrefix: "", XnameRegex: "", Role: "", SubRole: "", `+
+		`Type: "NodeBMC", Credentials: Username: root, Password: <REDACTED>, SwitchCredentials: <nil> }` {
+		t.Errorf("String() = %s", got)
+	}
+}
+
+func TestDiffCredentialPolicies(t *testing.T) {
+	if got := DiffCredentialPolicies(testPolicies, testPolicies); len(got) != 0 {
+		t.Errorf("DiffCredentialPolicies() of the same policies = %v", got)
+	}
+
+	changed := []CredentialPolicy{
+		{Name: "masters", Role: "Management", SubRole: "Master",
+			Credentials: &RedsCredentials{Username: "root", Password: "changed"}},
+		{Name: "bmcs", Type: "NodeBMC", Credentials: &RedsCredentials{Username: "root", Password: "bmc"}},
+		{Name: "switches", Type: "MgmtSwitch", SwitchCredentials: &SwitchCredentials{SNMPUsername: "snmp",
+			SNMPAuthPassword: "a", SNMPPrivPassword: "p", SNMPAuthProtocol: "SHA"}},
+		{Name: "cabinet-3001", XnamePrefix: "x3001", Credentials: &RedsCredentials{Username: "root"}},
+	}
+	got := DiffCredentialPolicies(testPolicies[:2], changed)
+	want := []CredentialChange{
+		{Key: "policy/cabinet-3001", Field: "name", Change: CHANGE_ADDED, To: "cabinet-3001"},
+		{Key: "policy/cabinet-3001", Field: "xnamePrefix", Change: CHANGE_ADDED, To: "x3001"},
+		{Key: "policy/cabinet-3001", Field: "credentials.username", Change: CHANGE_ADDED, To: "root"},
+		{Key: "policy/masters", Field: "credentials.password", Change: CHANGE_CHANGED, From: REDACTED, To: REDACTED},
+		{Key: "policy/switches", Field: "name", Change: CHANGE_ADDED, To: "switches"},
+		{Key: "policy/switches", Field: "type", Change: CHANGE_ADDED, To: "MgmtSwitch"},
+		{Key: "policy/switches/switch", Field: "SNMPUsername", Change: CHANGE_ADDED, To: "snmp"},
+		{Key: "policy/switches/switch", Field: "SNMPAuthPassword", Change: CHANGE_ADDED, To: REDACTED},
+		{Key: "policy/switches/switch", Field: "SNMPPrivPassword", Change: CHANGE_ADDED, To: REDACTED},
+		{Key: "policy/switches/switch", Field: "SNMPAuthProtocol", Change: CHANGE_ADDED, To: "SHA"},
+		{Key: "policies", Field: "order", Change: CHANGE_CHANGED, From: "bmcs,masters", To: "masters,bmcs"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffCredentialPolicies() = %v, want %v", got, want)
+	}
+}
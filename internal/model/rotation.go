@@ -0,0 +1,60 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package model
+
+import "sort"
+
+// RotationOutcome is the last outcome of rotating a BMC's password that left
+// it needing a person.  It is kept in Vault so it outlives the instance that
+// ran the rotation.
+type RotationOutcome struct {
+	Xname string `json:"xname"`
+	JobID string `json:"jobId"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+	Time  string `json:"time"`
+}
+
+// Stored in a struct; Vault can't take a list on its own.
+type rotationOutcomes struct {
+	Outcomes []RotationOutcome
+}
+
+func (ccs *RedsCredStore) rotationOutcomesPath() string {
+	return ccs.CCPath + "/rotation_outcomes"
+}
+
+// GetRotationOutcomes returns the kept rotation outcomes, in xname order.
+func (ccs *RedsCredStore) GetRotationOutcomes() ([]RotationOutcome, error) {
+	var stored rotationOutcomes
+	err := ccs.SS.Lookup(ccs.rotationOutcomesPath(), &stored)
+
+	return stored.Outcomes, err
+}
+
+// StoreRotationOutcomes replaces the kept rotation outcomes.
+func (ccs *RedsCredStore) StoreRotationOutcomes(outcomes []RotationOutcome) error {
+	sorted := append([]RotationOutcome{}, outcomes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Xname < sorted[j].Xname })
+	return ccs.SS.Store(ccs.rotationOutcomesPath(), rotationOutcomes{Outcomes: sorted})
+}
@@ -0,0 +1,59 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRedsCredStore_RotationOutcomes(t *testing.T) {
+	ss := NewKvMock()
+	credStorage := NewRedsCredStore(credentialsPath, ss)
+
+	if got, err := credStorage.GetRotationOutcomes(); err != nil || len(got) != 0 {
+		t.Errorf("GetRotationOutcomes() before any were stored = %v, %v", got, err)
+	}
+
+	outcomes := []RotationOutcome{
+		{Xname: "x3000c0s2b0", JobID: "rotation-1", State: "Unknown", Error: "no answer",
+			Time: "2026-01-01T00:00:00Z"},
+		{Xname: "x3000c0s1b0", JobID: "rotation-1", State: "RolledBack", Time: "2026-01-01T00:00:01Z"},
+	}
+	if err := credStorage.StoreRotationOutcomes(outcomes); err != nil {
+		t.Fatalf("StoreRotationOutcomes() error = %v", err)
+	}
+
+	// Vault hands back JSON, not the structs that went in.
+	data, _ := json.Marshal(ss.storage[credentialsPath+"/rotation_outcomes"])
+	var raw map[string]interface{}
+	json.Unmarshal(data, &raw)
+	ss.storage[credentialsPath+"/rotation_outcomes"] = raw
+
+	want := []RotationOutcome{outcomes[1], outcomes[0]}
+	got, err := credStorage.GetRotationOutcomes()
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRotationOutcomes() = %v, %v, want %v", got, err, want)
+	}
+}
@@ -0,0 +1,201 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SNMPv3 security levels
+const (
+	SNMP_LEVEL_NO_AUTH_NO_PRIV = "noAuthNoPriv"
+	SNMP_LEVEL_AUTH_NO_PRIV    = "authNoPriv"
+	SNMP_LEVEL_AUTH_PRIV       = "authPriv"
+)
+
+// The protocols switches were set up with before profiles said otherwise
+const (
+	DefaultSNMPAuthProtocol = "MD5"
+	DefaultSNMPPrivProtocol = "DES"
+)
+
+// Known protocols, keyed by their name in upper case without punctuation
+var snmpAuthProtocols = map[string]string{
+	"MD5":    "MD5",
+	"SHA":    "SHA",
+	"SHA1":   "SHA",
+	"SHA224": "SHA-224",
+	"SHA256": "SHA-256",
+	"SHA384": "SHA-384",
+	"SHA512": "SHA-512",
+}
+
+var snmpPrivProtocols = map[string]string{
+	"DES":    "DES",
+	"AES":    "AES",
+	"AES128": "AES",
+	"AES192": "AES-192",
+	"AES256": "AES-256",
+}
+
+func protocolKey(protocol string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToUpper(protocol))
+}
+
+// CanonicalSNMPAuthProtocol returns the usual name of an SNMPv3
+// authentication protocol, e.g. SHA-256 for sha256, and whether it is known.
+func CanonicalSNMPAuthProtocol(protocol string) (string, bool) {
+	name, ok := snmpAuthProtocols[protocolKey(protocol)]
+	return name, ok
+}
+
+// CanonicalSNMPPrivProtocol returns the usual name of an SNMPv3 privacy
+// protocol, e.g. AES-256 for aes256, and whether it is known.
+func CanonicalSNMPPrivProtocol(protocol string) (string, bool) {
+	name, ok := snmpPrivProtocols[protocolKey(protocol)]
+	return name, ok
+}
+
+// CanonicalSNMPSecurityLevel returns the usual spelling of an SNMPv3
+// security level and whether it is known.
+func CanonicalSNMPSecurityLevel(level string) (string, bool) {
+	for _, known := range []string{SNMP_LEVEL_NO_AUTH_NO_PRIV, SNMP_LEVEL_AUTH_NO_PRIV, SNMP_LEVEL_AUTH_PRIV} {
+		if strings.EqualFold(level, known) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// SecurityLevel returns the security level of the profile: the one set, or
+// else the highest one the passwords allow.
+func (c SwitchCredentials) SecurityLevel() string {
+	if level, ok := CanonicalSNMPSecurityLevel(c.SNMPSecurityLevel); ok {
+		return level
+	}
+	switch {
+	case c.SNMPAuthPassword != "" && c.SNMPPrivPassword != "":
+		return SNMP_LEVEL_AUTH_PRIV
+	case c.SNMPAuthPassword != "":
+		return SNMP_LEVEL_AUTH_NO_PRIV
+	default:
+		return SNMP_LEVEL_NO_AUTH_NO_PRIV
+	}
+}
+
+// ForModel returns the profile for a switch model: the model's own
+// settings, if it has any, over the general ones.  Models match ignoring
+// case.
+func (c SwitchCredentials) ForModel(model string) SwitchCredentials {
+	ret := c
+	ret.Models = nil
+	if model == "" {
+		return ret
+	}
+	for name, m := range c.Models {
+		if !strings.EqualFold(name, model) {
+			continue
+		}
+		if m.SNMPUsername != "" {
+			ret.SNMPUsername = m.SNMPUsername
+		}
+		if m.SNMPAuthPassword != "" {
+			ret.SNMPAuthPassword = m.SNMPAuthPassword
+		}
+		if m.SNMPPrivPassword != "" {
+			ret.SNMPPrivPassword = m.SNMPPrivPassword
+		}
+		if m.SNMPSecurityLevel != "" {
+			ret.SNMPSecurityLevel = m.SNMPSecurityLevel
+		}
+		if m.SNMPAuthProtocol != "" {
+			ret.SNMPAuthProtocol = m.SNMPAuthProtocol
+		}
+		if m.SNMPPrivProtocol != "" {
+			ret.SNMPPrivProtocol = m.SNMPPrivProtocol
+		}
+		if m.SNMPContext != "" {
+			ret.SNMPContext = m.SNMPContext
+		}
+		break
+	}
+	return ret
+}
+
+// validateProfile checks one profile, without its models.
+func (c SwitchCredentials) validateProfile() error {
+	if c.SNMPAuthProtocol != "" {
+		if _, ok := CanonicalSNMPAuthProtocol(c.SNMPAuthProtocol); !ok {
+			return fmt.Errorf("unknown SNMP authentication protocol %q", c.SNMPAuthProtocol)
+		}
+	}
+	if c.SNMPPrivProtocol != "" {
+		if _, ok := CanonicalSNMPPrivProtocol(c.SNMPPrivProtocol); !ok {
+			return fmt.Errorf("unknown SNMP privacy protocol %q", c.SNMPPrivProtocol)
+		}
+	}
+	if c.SNMPSecurityLevel != "" {
+		if _, ok := CanonicalSNMPSecurityLevel(c.SNMPSecurityLevel); !ok {
+			return fmt.Errorf("unknown SNMP security level %q", c.SNMPSecurityLevel)
+		}
+	}
+	if c.SNMPPrivPassword != "" && c.SNMPAuthPassword == "" {
+		return fmt.Errorf("SNMP privacy needs authentication, but there is no authentication password")
+	}
+	switch c.SecurityLevel() {
+	case SNMP_LEVEL_AUTH_PRIV:
+		if c.SNMPPrivPassword == "" {
+			return fmt.Errorf("security level %s needs a privacy password", SNMP_LEVEL_AUTH_PRIV)
+		}
+		fallthrough
+	case SNMP_LEVEL_AUTH_NO_PRIV:
+		if c.SNMPAuthPassword == "" {
+			return fmt.Errorf("security level %s needs an authentication password", c.SecurityLevel())
+		}
+	}
+	return nil
+}
+
+// Validate checks the protocols and security level are known and that the
+// security level has the passwords it needs, for the general profile and
+// for each model.
+func (c SwitchCredentials) Validate() error {
+	if err := c.validateProfile(); err != nil {
+		return err
+	}
+	var models []string
+	for name := range c.Models {
+		models = append(models, name)
+	}
+	sort.Strings(models)
+	for _, name := range models {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("switch model profile with no model")
+		}
+		if err := c.ForModel(name).validateProfile(); err != nil {
+			return fmt.Errorf("model %s: %s", name, err)
+		}
+	}
+	return nil
+}
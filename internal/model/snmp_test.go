@@ -0,0 +1,117 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package model
+
+import (
+	"testing"
+)
+
+func TestSwitchCredentials_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		creds   SwitchCredentials
+		wantErr bool
+	}{
+		{"legacy", SwitchCredentials{SNMPUsername: "testuser", SNMPAuthPassword: "a", SNMPPrivPassword: "p"}, false},
+		{"username only", SwitchCredentials{SNMPUsername: "testuser"}, false},
+		{"full profile", SwitchCredentials{SNMPUsername: "testuser", SNMPAuthPassword: "a", SNMPPrivPassword: "p",
+			SNMPSecurityLevel: "authpriv", SNMPAuthProtocol: "sha-512", SNMPPrivProtocol: "AES256", SNMPContext: "vlan-1"}, false},
+		{"authNoPriv", SwitchCredentials{SNMPUsername: "testuser", SNMPAuthPassword: "a",
+			SNMPSecurityLevel: SNMP_LEVEL_AUTH_NO_PRIV, SNMPAuthProtocol: "SHA-224"}, false},
+		{"unknown auth protocol", SwitchCredentials{SNMPAuthPassword: "a", SNMPAuthProtocol: "SHA3"}, true},
+		{"unknown priv protocol", SwitchCredentials{SNMPAuthPassword: "a", SNMPPrivPassword: "p",
+			SNMPPrivProtocol: "3DES"}, true},
+		{"unknown level", SwitchCredentials{SNMPSecurityLevel: "paranoid"}, true},
+		{"authPriv without priv password", SwitchCredentials{SNMPAuthPassword: "a",
+			SNMPSecurityLevel: SNMP_LEVEL_AUTH_PRIV}, true},
+		{"authNoPriv without auth password", SwitchCredentials{SNMPSecurityLevel: SNMP_LEVEL_AUTH_NO_PRIV}, true},
+		{"priv without auth", SwitchCredentials{SNMPPrivPassword: "p"}, true},
+		{"valid model", SwitchCredentials{SNMPUsername: "testuser", SNMPAuthPassword: "a", SNMPPrivPassword: "p",
+			Models: map[string]SwitchCredentials{"Dell S3048-ON": {SNMPAuthProtocol: "SHA", SNMPPrivProtocol: "AES"}}}, false},
+		{"invalid model", SwitchCredentials{SNMPUsername: "testuser", SNMPAuthPassword: "a",
+			Models: map[string]SwitchCredentials{"Dell S3048-ON": {SNMPSecurityLevel: SNMP_LEVEL_AUTH_PRIV}}}, true},
+		{"model with no name", SwitchCredentials{Models: map[string]SwitchCredentials{" ": {}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.creds.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSwitchCredentials_ForModel(t *testing.T) {
+	creds := SwitchCredentials{
+		SNMPUsername:     "testuser",
+		SNMPAuthPassword: "a",
+		SNMPPrivPassword: "p",
+		SNMPAuthProtocol: "MD5",
+		SNMPPrivProtocol: "DES",
+		Models: map[string]SwitchCredentials{
+			"Aruba 8325": {SNMPAuthProtocol: "SHA-256", SNMPPrivProtocol: "AES-256", SNMPContext: "default"},
+		},
+	}
+
+	aruba := creds.ForModel("aruba 8325")
+	if aruba.SNMPUsername != "testuser" || aruba.SNMPAuthProtocol != "SHA-256" || aruba.SNMPPrivProtocol != "AES-256" ||
+		aruba.SNMPContext != "default" || aruba.Models != nil {
+		t.Errorf("ForModel() of a listed model = %+v", aruba)
+	}
+	other := creds.ForModel("Dell S3048-ON")
+	if other.SNMPAuthProtocol != "MD5" || other.SNMPPrivProtocol != "DES" || other.SNMPContext != "" {
+		t.Errorf("ForModel() of another model = %+v", other)
+	}
+	if len(creds.Models) != 1 {
+		t.Errorf("ForModel() changed the models")
+	}
+}
+
+func TestSwitchCredentials_SecurityLevel(t *testing.T) {
+	tests := []struct {
+		creds SwitchCredentials
+		want  string
+	}{
+		{SwitchCredentials{SNMPAuthPassword: "a", SNMPPrivPassword: "p"}, SNMP_LEVEL_AUTH_PRIV},
+		{SwitchCredentials{SNMPAuthPassword: "a"}, SNMP_LEVEL_AUTH_NO_PRIV},
+		{SwitchCredentials{}, SNMP_LEVEL_NO_AUTH_NO_PRIV},
+		{SwitchCredentials{SNMPAuthPassword: "a", SNMPPrivPassword: "p", SNMPSecurityLevel: "AUTHNOPRIV"},
+			SNMP_LEVEL_AUTH_NO_PRIV},
+	}
+	for _, tt := range tests {
+		if got := tt.creds.SecurityLevel(); got != tt.want {
+			t.Errorf("SecurityLevel() of %+v = %s, want %s", tt.creds, got, tt.want)
+		}
+	}
+}
+
+func TestStoreDefaultSwitchCredentials_invalid(t *testing.T) {
+	credStorage := NewRedsCredStore(credentialsPath, NewKvMock())
+	err := credStorage.StoreDefaultSwitchCredentials(SwitchCredentials{SNMPUsername: "testuser",
+		SNMPAuthPassword: "a", SNMPAuthProtocol: "SHA3"})
+	if err == nil {
+		t.Fatalf("StoreDefaultSwitchCredentials() of an invalid profile succeeded")
+	}
+	if versions, _ := credStorage.GetCredentialHistory(DEFAULTS_KIND_SWITCH); len(versions) != 0 {
+		t.Errorf("An invalid profile was added to the history: %+v", versions)
+	}
+}
@@ -0,0 +1,112 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package passwords
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedfishApplier_Apply(t *testing.T) {
+	password := "default"
+	var patched []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pass, ok := r.BasicAuth(); !ok || pass != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /redfish/v1/AccountService/Accounts":
+			w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/AccountService/Accounts/1"},` +
+				`{"@odata.id":"/redfish/v1/AccountService/Accounts/2"}]}`))
+		case "GET /redfish/v1/AccountService/Accounts/1":
+			w.Write([]byte(`{"UserName":"admin"}`))
+		case "GET /redfish/v1/AccountService/Accounts/2":
+			w.Header().Set("ETag", `W/"2"`)
+			w.Write([]byte(`{"UserName":"root"}`))
+		case "PATCH /redfish/v1/AccountService/Accounts/2":
+			if r.Header.Get("If-Match") != `W/"2"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			var body map[string]string
+			data, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(data, &body)
+			patched = append(patched, r.URL.Path)
+			password = body["Password"]
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	a := NewRedfishApplier(5 * time.Second)
+	target := Target{Xname: "x3000c0s1b0", Host: strings.TrimPrefix(ts.URL, "https://")}
+	if err := a.Apply(context.Background(), target, "root", "default", "n3w-Passw0rd"); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if password != "n3w-Passw0rd" || len(patched) != 1 {
+		t.Errorf("Password is %q after %v, want n3w-Passw0rd", password, patched)
+	}
+
+	if err := a.Apply(context.Background(), target, "root", "wrong", "other"); err == nil ||
+		!strings.Contains(err.Error(), "401") {
+		t.Errorf("Apply() with the wrong password error = %v", err)
+	}
+	if err := a.Apply(context.Background(), target, "root", "n3w-Passw0rd", "other"); err != nil {
+		t.Errorf("Apply() a second time error = %v", err)
+	}
+	password = "x"
+	target.Xname = "x3000c0s2b0"
+	if err := a.Apply(context.Background(), target, "nobody", "x", "y"); err == nil ||
+		err.Error() != "x3000c0s2b0 has no account nobody" {
+		t.Errorf("Apply() for a missing account error = %v", err)
+	}
+}
+
+func TestRedfishApplier_Verify(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "root" || pass != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"Members":[]}`))
+	}))
+	defer ts.Close()
+
+	a := NewRedfishApplier(5 * time.Second)
+	target := Target{Xname: "x3000c0s1b0", Host: strings.TrimPrefix(ts.URL, "https://")}
+	if err := a.Verify(context.Background(), target, "root", "s3cret"); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := a.Verify(context.Background(), target, "root", "wrong"); err == nil {
+		t.Errorf("Verify() with the wrong password succeeded")
+	}
+}
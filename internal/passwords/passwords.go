@@ -0,0 +1,223 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package passwords generates BMC passwords and sets them on BMCs.
+package passwords
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+)
+
+// Character classes passwords are made from
+const (
+	lowerChars = "abcdefghijklmnopqrstuvwxyz"
+	upperChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars = "0123456789"
+	// Specials that survive shells, URLs and BMC web UIs
+	DefaultSpecials = "!#%+-.:=@_"
+)
+
+// Longest password any BMC we know of takes
+const maxLength = 127
+
+// Rules are the complexity rules of one vendor's BMCs.
+type Rules struct {
+	Length     int `json:"length"`
+	MinLower   int `json:"minLower"`
+	MinUpper   int `json:"minUpper"`
+	MinDigit   int `json:"minDigit"`
+	MinSpecial int `json:"minSpecial"`
+	// Special characters to use; DefaultSpecials if empty
+	Specials string `json:"specials,omitempty"`
+	// Characters never to use, of any class
+	Forbidden string `json:"forbidden,omitempty"`
+}
+
+// DefaultRules suit the BMCs REDS has met so far.
+var DefaultRules = Rules{
+	Length:     16,
+	MinLower:   1,
+	MinUpper:   1,
+	MinDigit:   1,
+	MinSpecial: 1,
+}
+
+// RuleSet holds the rules of each vendor.  Vendors without rules of their
+// own use the "default" entry, or DefaultRules if there's none.
+type RuleSet map[string]Rules
+
+// Key of the rules for vendors without rules of their own
+const DEFAULT_VENDOR = "default"
+
+// For returns the rules for a vendor, ignoring case.
+func (rs RuleSet) For(vendor string) Rules {
+	for v, rules := range rs {
+		if strings.EqualFold(v, vendor) {
+			return rules
+		}
+	}
+	if rules, ok := rs[DEFAULT_VENDOR]; ok {
+		return rules
+	}
+	return DefaultRules
+}
+
+// Validate checks every vendor's rules.
+func (rs RuleSet) Validate() error {
+	for vendor, rules := range rs {
+		if err := rules.Validate(); err != nil {
+			return fmt.Errorf("password rules for %s: %s", vendor, err)
+		}
+	}
+	return nil
+}
+
+// LoadRuleSet reads a JSON rule set from a file.
+func LoadRuleSet(path string) (RuleSet, error) {
+	var rs RuleSet
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &rs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse password rules %s: %s", path, err)
+	}
+	return rs, rs.Validate()
+}
+
+// without returns chars less the forbidden ones.
+func without(chars string, forbidden string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(forbidden, r) {
+			return -1
+		}
+		return r
+	}, chars)
+}
+
+// classes returns the characters of each class the rules allow, with the
+// minimum number of each.
+func (r Rules) classes() ([]string, []int) {
+	specials := r.Specials
+	if specials == "" {
+		specials = DefaultSpecials
+	}
+	chars := []string{
+		without(lowerChars, r.Forbidden),
+		without(upperChars, r.Forbidden),
+		without(digitChars, r.Forbidden),
+		without(specials, r.Forbidden),
+	}
+	return chars, []int{r.MinLower, r.MinUpper, r.MinDigit, r.MinSpecial}
+}
+
+// Validate checks that passwords can be made that follow the rules.
+func (r Rules) Validate() error {
+	if r.Length < 8 || r.Length > maxLength {
+		return fmt.Errorf("length %d is not between 8 and %d", r.Length, maxLength)
+	}
+	if r.MinLower < 0 || r.MinUpper < 0 || r.MinDigit < 0 || r.MinSpecial < 0 {
+		return errors.New("minimums can't be negative")
+	}
+	if r.MinLower+r.MinUpper+r.MinDigit+r.MinSpecial > r.Length {
+		return fmt.Errorf("minimums add up to more than the length %d", r.Length)
+	}
+	// Passwords are made a byte at a time, and BMCs only take ASCII
+	for _, c := range r.Specials {
+		if c <= ' ' || c > '~' {
+			return fmt.Errorf("special %q is not a printable ASCII character", c)
+		}
+	}
+	chars, mins := r.classes()
+	names := []string{"lower case letters", "upper case letters", "digits", "specials"}
+	all := ""
+	for i := range chars {
+		if mins[i] > 0 && chars[i] == "" {
+			return fmt.Errorf("%s are required but all forbidden", names[i])
+		}
+		all += chars[i]
+	}
+	if all == "" {
+		return errors.New("every character is forbidden")
+	}
+	return nil
+}
+
+// randomIndex returns a random number in [0, n).
+func randomIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
+
+// Generate makes a random password following the rules.
+func (r Rules) Generate() (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", err
+	}
+	chars, mins := r.classes()
+	var all string
+	for _, c := range chars {
+		all += c
+	}
+
+	password := make([]byte, 0, r.Length)
+	pick := func(from string) error {
+		i, err := randomIndex(len(from))
+		if err != nil {
+			return err
+		}
+		password = append(password, from[i])
+		return nil
+	}
+	for i, min := range mins {
+		for n := 0; n < min; n++ {
+			if err := pick(chars[i]); err != nil {
+				return "", err
+			}
+		}
+	}
+	for len(password) < r.Length {
+		if err := pick(all); err != nil {
+			return "", err
+		}
+	}
+
+	// Don't leave the required characters at the front.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
@@ -0,0 +1,103 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package passwords
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRules_Generate(t *testing.T) {
+	rules := Rules{Length: 12, MinLower: 2, MinUpper: 2, MinDigit: 3, MinSpecial: 2, Specials: "!@#",
+		Forbidden: "0OolI1!"}
+	seen := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		pw, err := rules.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if len(pw) != 12 || strings.ContainsAny(pw, rules.Forbidden) {
+			t.Fatalf("Generate() = %q breaks the length or forbidden rules", pw)
+		}
+		count := func(chars string) int {
+			n := 0
+			for _, c := range pw {
+				if strings.ContainsRune(chars, c) {
+					n++
+				}
+			}
+			return n
+		}
+		if count(lowerChars) < 2 || count(upperChars) < 2 || count(digitChars) < 3 || count("@#") < 2 {
+			t.Fatalf("Generate() = %q doesn't have the minimum of each class", pw)
+		}
+		if count(lowerChars+upperChars+digitChars+"@#") != 12 {
+			t.Fatalf("Generate() = %q has characters outside the allowed classes", pw)
+		}
+		seen[pw] = true
+	}
+	if len(seen) < 200 {
+		t.Errorf("Generate() repeated passwords: %d unique of 200", len(seen))
+	}
+}
+
+func TestRules_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   Rules
+		wantErr bool
+	}{
+		{"default", DefaultRules, false},
+		{"too short", Rules{Length: 6}, true},
+		{"too long", Rules{Length: 200}, true},
+		{"minimums too high", Rules{Length: 8, MinLower: 4, MinUpper: 4, MinDigit: 1}, true},
+		{"negative", Rules{Length: 8, MinDigit: -1}, true},
+		{"required class forbidden", Rules{Length: 8, MinDigit: 1, Forbidden: digitChars}, true},
+		{"optional class forbidden", Rules{Length: 8, Forbidden: digitChars + DefaultSpecials}, false},
+		{"ASCII specials", Rules{Length: 8, MinSpecial: 1, Specials: "!$&*~"}, false},
+		{"non-ASCII specials", Rules{Length: 8, MinSpecial: 1, Specials: "!§€"}, true},
+		{"space special", Rules{Length: 8, Specials: "! "}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleSet_For(t *testing.T) {
+	gigabyte := Rules{Length: 10}
+	site := Rules{Length: 20}
+	rs := RuleSet{"Gigabyte": gigabyte, DEFAULT_VENDOR: site}
+	if got := rs.For("GIGABYTE"); got != gigabyte {
+		t.Errorf("For(GIGABYTE) = %+v", got)
+	}
+	if got := rs.For("HPE"); got != site {
+		t.Errorf("For(HPE) = %+v, want the site default", got)
+	}
+	if got := (RuleSet{}).For("HPE"); got != DefaultRules {
+		t.Errorf("For(HPE) with no rules = %+v, want DefaultRules", got)
+	}
+}
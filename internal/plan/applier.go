@@ -0,0 +1,55 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package plan
+
+import (
+	"context"
+
+	"github.com/Cray-HPE/hms-reds/internal/passwords"
+)
+
+// Applier records the BMC passwords REDS would set instead of setting them.
+type Applier struct {
+	rec *Recorder
+}
+
+// NewApplier creates an Applier recording to rec.
+func NewApplier(rec *Recorder) *Applier {
+	return &Applier{rec: rec}
+}
+
+func (a *Applier) Apply(ctx context.Context, target passwords.Target, username string, current string,
+	password string) error {
+	a.rec.Record(SERVICE_BMC, "Set password", []string{target.Xname}, map[string]string{
+		"host":     target.Host,
+		"username": username,
+		"password": password,
+	})
+	return nil
+}
+
+// Verify assumes the BMC takes the password; in a dry run nothing was
+// changed to check.
+func (a *Applier) Verify(ctx context.Context, target passwords.Target, username string, password string) error {
+	return nil
+}
@@ -0,0 +1,149 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package plan records the changes REDS would make when it runs in dry-run
+// mode, so they can be reviewed before REDS is let loose on a system.
+package plan
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Services a planned change would have gone to
+const (
+	SERVICE_HSM   = "hsm"
+	SERVICE_VAULT = "vault"
+	SERVICE_BMC   = "bmc"
+)
+
+// Default number of entries a Recorder keeps
+const DefaultMaxEntries = 1000
+
+// Entry is one change REDS would have made.  Payload has secrets redacted.
+type Entry struct {
+	Time      string      `json:"time"`
+	Service   string      `json:"service"`
+	Operation string      `json:"operation"`
+	Xnames    []string    `json:"xnames,omitempty"`
+	Payload   interface{} `json:"payload,omitempty"`
+}
+
+// Recorder collects planned changes, keeping the most recent maxEntries.
+type Recorder struct {
+	lock       sync.Mutex
+	entries    []Entry
+	maxEntries int
+	dropped    int
+}
+
+// NewRecorder creates a Recorder keeping at most maxEntries entries.
+func NewRecorder(maxEntries int) *Recorder {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Recorder{maxEntries: maxEntries}
+}
+
+// Record adds a planned change.  The payload is redacted before it's kept.
+func (r *Recorder) Record(service string, operation string, xnames []string, payload interface{}) {
+	entry := Entry{
+		Time:      time.Now().Format(time.RFC3339),
+		Service:   service,
+		Operation: operation,
+		Xnames:    xnames,
+		Payload:   Redact(payload),
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.entries) >= r.maxEntries {
+		r.entries = r.entries[1:]
+		r.dropped++
+	}
+	r.entries = append(r.entries, entry)
+}
+
+// Entries returns the planned changes, oldest first, and how many older
+// ones were dropped to stay within the limit.
+func (r *Recorder) Entries() ([]Entry, int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Entry(nil), r.entries...), r.dropped
+}
+
+// Clear forgets every planned change.
+func (r *Recorder) Clear() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.entries = nil
+	r.dropped = 0
+}
+
+// isSecret reports whether a payload field holds a secret.
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range []string{"password", "secret", "token"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns payload as generic JSON with the values of password,
+// secret and token fields replaced by "<REDACTED>".
+func Redact(payload interface{}) interface{} {
+	if payload == nil {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "<unprintable>"
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return "<unprintable>"
+	}
+	return redact(generic)
+}
+
+func redact(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, field := range val {
+			if isSecret(k) {
+				if field != "" && field != nil {
+					val[k] = "<REDACTED>"
+				}
+				continue
+			}
+			val[k] = redact(field)
+		}
+	case []interface{}:
+		for i := range val {
+			val[i] = redact(val[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,149 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// memStorage is a SecureStorage kept in memory.
+type memStorage map[string][]byte
+
+func (m memStorage) Store(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	m[key] = data
+	return err
+}
+
+func (m memStorage) Lookup(key string, output interface{}) error {
+	if data, ok := m[key]; ok {
+		return json.Unmarshal(data, output)
+	}
+	return nil
+}
+
+func (m memStorage) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+func (m memStorage) LookupKeys(keyPath string) ([]string, error) {
+	var keys []string
+	for key := range m {
+		if strings.HasPrefix(key, keyPath+"/") {
+			keys = append(keys, strings.TrimPrefix(key, keyPath+"/"))
+		}
+	}
+	return keys, nil
+}
+
+type cred struct {
+	Xname            string
+	Username         string
+	Password         string
+	SNMPAuthPassword string
+	Nested           []map[string]string
+}
+
+func TestRedact(t *testing.T) {
+	got := Redact(cred{
+		Xname:            "x3000c0s1b0",
+		Username:         "root",
+		Password:         "hunter2",
+		SNMPAuthPassword: "",
+		Nested:           []map[string]string{{"token": "abc", "name": "n"}},
+	})
+	want := map[string]interface{}{
+		"Xname":            "x3000c0s1b0",
+		"Username":         "root",
+		"Password":         "<REDACTED>",
+		"SNMPAuthPassword": "",
+		"Nested":           []interface{}{map[string]interface{}{"token": "<REDACTED>", "name": "n"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Redact() = %#v, want %#v", got, want)
+	}
+	if Redact(nil) != nil {
+		t.Errorf("Redact(nil) should be nil")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder(2)
+	for i := 0; i < 3; i++ {
+		rec.Record(SERVICE_HSM, fmt.Sprintf("op%d", i), []string{"x3000c0s1b0"}, nil)
+	}
+	entries, dropped := rec.Entries()
+	if len(entries) != 2 || dropped != 1 || entries[0].Operation != "op1" || entries[1].Operation != "op2" {
+		t.Errorf("Entries() = %+v, %d, want op1, op2 with 1 dropped", entries, dropped)
+	}
+	rec.Clear()
+	if entries, dropped := rec.Entries(); len(entries) != 0 || dropped != 0 {
+		t.Errorf("Entries() after Clear() = %+v, %d", entries, dropped)
+	}
+}
+
+func TestSecureStorage(t *testing.T) {
+	real := memStorage{}
+	real.Store("secret/hms-creds/x3000c0s2b0", cred{Xname: "x3000c0s2b0", Password: "old"})
+	rec := NewRecorder(0)
+	ss := NewSecureStorage(real, rec)
+
+	if err := ss.Store("secret/hms-creds/x3000c0s1b0", cred{Xname: "x3000c0s1b0", Password: "new"}); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if err := ss.Delete("secret/hms-creds/x3000c0s2b0"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(real) != 1 {
+		t.Fatalf("The real store was changed: %v", real)
+	}
+
+	var c cred
+	if err := ss.Lookup("secret/hms-creds/x3000c0s1b0", &c); err != nil || c.Password != "new" {
+		t.Errorf("Lookup() of a planned key = %+v, %v", c, err)
+	}
+	c = cred{}
+	if err := ss.Lookup("secret/hms-creds/x3000c0s2b0", &c); err != nil || c.Password != "" {
+		t.Errorf("Lookup() of a deleted key = %+v, %v", c, err)
+	}
+	keys, err := ss.LookupKeys("secret/hms-creds")
+	if err != nil || !reflect.DeepEqual(keys, []string{"x3000c0s1b0"}) {
+		t.Errorf("LookupKeys() = %v, %v", keys, err)
+	}
+
+	entries, _ := rec.Entries()
+	if len(entries) != 2 || entries[0].Service != SERVICE_VAULT ||
+		entries[0].Operation != "Store secret/hms-creds/x3000c0s1b0" ||
+		!reflect.DeepEqual(entries[0].Xnames, []string{"x3000c0s1b0"}) ||
+		entries[1].Operation != "Delete secret/hms-creds/x3000c0s2b0" {
+		t.Fatalf("Entries() = %+v", entries)
+	}
+	if payload := entries[0].Payload.(map[string]interface{}); payload["Password"] != "<REDACTED>" {
+		t.Errorf("Password was not redacted: %v", payload)
+	}
+}
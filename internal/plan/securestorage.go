@@ -0,0 +1,128 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package plan
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	sstorage "github.com/Cray-HPE/hms-securestorage"
+)
+
+// SecureStorage records writes to a secure store instead of making them.
+// Reads go to the real store, except for keys written or deleted in the
+// plan, which read back as the plan left them.
+type SecureStorage struct {
+	real sstorage.SecureStorage
+	rec  *Recorder
+
+	lock    sync.Mutex
+	written map[string][]byte
+	deleted map[string]bool
+}
+
+// NewSecureStorage wraps real so writes are recorded to rec.
+func NewSecureStorage(real sstorage.SecureStorage, rec *Recorder) *SecureStorage {
+	return &SecureStorage{
+		real:    real,
+		rec:     rec,
+		written: make(map[string][]byte),
+		deleted: make(map[string]bool),
+	}
+}
+
+// xnameFromKey returns the last element of a key, which is the xname for
+// per-component credentials.
+func xnameFromKey(key string) []string {
+	return []string{key[strings.LastIndex(key, "/")+1:]}
+}
+
+func (s *SecureStorage) Store(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.rec.Record(SERVICE_VAULT, "Store "+key, xnameFromKey(key), value)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.written[key] = data
+	delete(s.deleted, key)
+	return nil
+}
+
+func (s *SecureStorage) Lookup(key string, output interface{}) error {
+	s.lock.Lock()
+	data, written := s.written[key]
+	deleted := s.deleted[key]
+	s.lock.Unlock()
+
+	if written {
+		return json.Unmarshal(data, output)
+	}
+	if deleted {
+		// Like a missing key in Vault: no data, no error.
+		return nil
+	}
+	return s.real.Lookup(key, output)
+}
+
+func (s *SecureStorage) Delete(key string) error {
+	s.rec.Record(SERVICE_VAULT, "Delete "+key, xnameFromKey(key), nil)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.written, key)
+	s.deleted[key] = true
+	return nil
+}
+
+func (s *SecureStorage) LookupKeys(keyPath string) ([]string, error) {
+	keys, err := s.real.LookupKeys(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	seen := make(map[string]bool)
+	var result []string
+	for _, k := range keys {
+		if !s.deleted[strings.TrimSuffix(keyPath, "/")+"/"+k] {
+			result = append(result, k)
+			seen[k] = true
+		}
+	}
+	prefix := strings.TrimSuffix(keyPath, "/") + "/"
+	for key := range s.written {
+		if strings.HasPrefix(key, prefix) {
+			k := strings.TrimPrefix(key, prefix)
+			if !strings.Contains(k, "/") && !seen[k] {
+				result = append(result, k)
+				seen[k] = true
+			}
+		}
+	}
+	return result, nil
+}
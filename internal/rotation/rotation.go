@@ -0,0 +1,469 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package rotation changes the passwords of BMCs REDS has onboarded.  Each
+// BMC's password is changed through its Redfish AccountService and only
+// written to Vault once the BMC is seen to take it; if anything goes wrong
+// the BMC is put back to the password Vault has.
+package rotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base"
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/model"
+	"github.com/Cray-HPE/hms-reds/internal/passwords"
+)
+
+// States of a rotation job
+const (
+	JOB_STATE_PENDING   = "Pending"
+	JOB_STATE_RUNNING   = "Running"
+	JOB_STATE_COMPLETED = "Completed"
+)
+
+// Outcomes of rotating a single BMC
+const (
+	// The BMC and Vault have the new password.
+	RESULT_ROTATED = "Rotated"
+	// Nothing was changed.
+	RESULT_FAILED = "Failed"
+	// The BMC took the new password but was put back to the old one.
+	RESULT_ROLLED_BACK = "RolledBack"
+	// The BMC may not have the password Vault has.  Needs a person.
+	RESULT_UNKNOWN = "Unknown"
+)
+
+// Number of finished jobs kept for the API
+const DefaultMaxJobs = 100
+
+// Time allowed for rotating a single BMC if none is given
+const DefaultTimeout = 60 * time.Second
+
+// CredStore is the part of the Vault credential store rotation needs.
+type CredStore interface {
+	GetCompCred(xname string) (compcredentials.CompCredentials, error)
+	StoreCompCred(compcred compcredentials.CompCredentials) error
+}
+
+// OutcomeStore keeps the outcomes that need a person, so they aren't lost
+// when the instance that ran the job goes away.
+type OutcomeStore interface {
+	GetRotationOutcomes() ([]model.RotationOutcome, error)
+	StoreRotationOutcomes(outcomes []model.RotationOutcome) error
+}
+
+// BMC sets and checks passwords on BMCs.
+type BMC interface {
+	passwords.Applier
+	passwords.Verifier
+}
+
+// TargetFunc returns where the BMC with the given xname can be reached.
+type TargetFunc func(ctx context.Context, xname string) passwords.Target
+
+// Result is the outcome of rotating one BMC's password.
+type Result struct {
+	Xname    string    `json:"xname"`
+	State    string    `json:"state"`
+	Error    string    `json:"error,omitempty"`
+	Finished time.Time `json:"finished"`
+}
+
+// Job rotates the passwords of a set of BMCs.
+type Job struct {
+	ID       string     `json:"id"`
+	Xnames   []string   `json:"xnames"`
+	State    string     `json:"state"`
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Rotated  int        `json:"rotated"`
+	Failed   int        `json:"failed"`
+	Results  []Result   `json:"results"`
+}
+
+// BusyError is returned when asked to rotate a BMC another job is already
+// rotating.
+type BusyError struct {
+	Xnames []string
+	JobID  string
+}
+
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("%s already being rotated by job %s", strings.Join(e.Xnames, ","), e.JobID)
+}
+
+// ErrNotLeader is returned when asked to start a job on an instance that
+// isn't the leader.  Only the leader rotates, so two instances never change
+// the same BMC at once.
+var ErrNotLeader = errors.New("this instance isn't the leader")
+
+// Manager runs rotation jobs and keeps the recent ones.
+type Manager struct {
+	store   CredStore
+	bmc     BMC
+	rules   passwords.RuleSet
+	targets TargetFunc
+	workers int
+	timeout time.Duration
+	maxJobs int
+
+	isLeader    func() bool
+	outcomes    OutcomeStore
+	outcomeLock sync.Mutex
+
+	lock   sync.Mutex
+	jobs   []*Job
+	nextID int
+}
+
+// NewManager creates a Manager.  workers BMCs of a job are rotated at a
+// time, each allowed timeout.  If targets is nil BMCs are reached by xname.
+func NewManager(store CredStore, bmc BMC, rules passwords.RuleSet, targets TargetFunc,
+	workers int, timeout time.Duration) *Manager {
+	if workers < 1 {
+		workers = 1
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if targets == nil {
+		targets = func(ctx context.Context, xname string) passwords.Target {
+			return passwords.Target{Xname: xname, Host: xname}
+		}
+	}
+	return &Manager{
+		store:   store,
+		bmc:     bmc,
+		rules:   rules,
+		targets: targets,
+		workers: workers,
+		timeout: timeout,
+		maxJobs: DefaultMaxJobs,
+	}
+}
+
+// SetLeaderCheck makes jobs only start, and BMCs only be rotated, while
+// isLeader says this instance is the leader.
+func (m *Manager) SetLeaderCheck(isLeader func() bool) {
+	m.isLeader = isLeader
+}
+
+// SetOutcomeStore keeps the Unknown and RolledBack outcomes in store.  A
+// BMC's outcome is dropped once it is rotated.
+func (m *Manager) SetOutcomeStore(store OutcomeStore) {
+	m.outcomes = store
+}
+
+func (m *Manager) leader() bool {
+	return m.isLeader == nil || m.isLeader()
+}
+
+// Start starts a job rotating the passwords of the given BMCs and returns
+// it as it was when started.
+func (m *Manager) Start(xnames []string) (Job, error) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, xname := range xnames {
+		id := base.NormalizeHMSCompID(xname)
+		if base.GetHMSType(id) == base.HMSTypeInvalid {
+			return Job{}, fmt.Errorf("invalid xname %q", xname)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return Job{}, fmt.Errorf("no xnames to rotate")
+	}
+	sort.Strings(ids)
+	if !m.leader() {
+		return Job{}, ErrNotLeader
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, job := range m.jobs {
+		if job.State == JOB_STATE_COMPLETED {
+			continue
+		}
+		var busy []string
+		for _, xname := range job.Xnames {
+			if seen[xname] {
+				busy = append(busy, xname)
+			}
+		}
+		if len(busy) > 0 {
+			return Job{}, &BusyError{Xnames: busy, JobID: job.ID}
+		}
+	}
+
+	m.nextID++
+	job := &Job{
+		ID:      fmt.Sprintf("rotation-%d", m.nextID),
+		Xnames:  ids,
+		State:   JOB_STATE_PENDING,
+		Created: time.Now(),
+		Results: []Result{},
+	}
+	m.jobs = append(m.jobs, job)
+	m.prune()
+	go m.run(job)
+
+	log.Printf("INFO: Started password rotation job %s for %d BMCs", job.ID, len(ids))
+	return copyJob(job), nil
+}
+
+// prune drops the oldest finished jobs beyond maxJobs.  Callers hold lock.
+func (m *Manager) prune() {
+	extra := len(m.jobs) - m.maxJobs
+	if extra <= 0 {
+		return
+	}
+	kept := m.jobs[:0]
+	for _, job := range m.jobs {
+		if extra > 0 && job.State == JOB_STATE_COMPLETED {
+			extra--
+			continue
+		}
+		kept = append(kept, job)
+	}
+	m.jobs = kept
+}
+
+// Jobs returns the recent jobs, oldest first.
+func (m *Manager) Jobs() []Job {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ret := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		ret = append(ret, copyJob(job))
+	}
+	return ret
+}
+
+// Job returns the job with the given ID, if it is recent enough to be kept.
+func (m *Manager) Job(id string) (Job, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return copyJob(job), true
+		}
+	}
+	return Job{}, false
+}
+
+// Outcomes returns the kept outcomes that need a person, in xname order.
+func (m *Manager) Outcomes() ([]model.RotationOutcome, error) {
+	if m.outcomes == nil {
+		return []model.RotationOutcome{}, nil
+	}
+	outcomes, err := m.outcomes.GetRotationOutcomes()
+	if outcomes == nil {
+		outcomes = []model.RotationOutcome{}
+	}
+	return outcomes, err
+}
+
+// recordOutcome keeps res if it needs a person, or drops the BMC's kept
+// outcome if it was rotated.  A Failed rotation changed nothing, so an
+// earlier outcome still stands.
+func (m *Manager) recordOutcome(jobID string, res Result) {
+	if m.outcomes == nil || res.State == RESULT_FAILED {
+		return
+	}
+	m.outcomeLock.Lock()
+	defer m.outcomeLock.Unlock()
+
+	outcomes, err := m.outcomes.GetRotationOutcomes()
+	if err != nil {
+		log.Printf("ERROR: Unable to keep the %s rotation outcome of %s, can't read the kept ones: %s",
+			res.State, res.Xname, err)
+		return
+	}
+	kept := make([]model.RotationOutcome, 0, len(outcomes)+1)
+	for _, outcome := range outcomes {
+		if outcome.Xname != res.Xname {
+			kept = append(kept, outcome)
+		}
+	}
+	if res.State == RESULT_ROTATED {
+		if len(kept) == len(outcomes) {
+			return
+		}
+	} else {
+		kept = append(kept, model.RotationOutcome{
+			Xname: res.Xname,
+			JobID: jobID,
+			State: res.State,
+			Error: res.Error,
+			Time:  res.Finished.UTC().Format(time.RFC3339),
+		})
+	}
+	err = m.outcomes.StoreRotationOutcomes(kept)
+	if err != nil {
+		log.Printf("ERROR: Unable to keep the %s rotation outcome of %s: %s", res.State, res.Xname, err)
+	}
+}
+
+func copyJob(job *Job) Job {
+	ret := *job
+	ret.Xnames = append([]string{}, job.Xnames...)
+	ret.Results = append([]Result{}, job.Results...)
+	return ret
+}
+
+func (m *Manager) run(job *Job) {
+	m.lock.Lock()
+	now := time.Now()
+	job.State = JOB_STATE_RUNNING
+	job.Started = &now
+	m.lock.Unlock()
+
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < m.workers && i < len(job.Xnames); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for xname := range work {
+				var res Result
+				if m.leader() {
+					res = m.rotate(xname)
+					m.recordOutcome(job.ID, res)
+				} else {
+					res = failed(RESULT_FAILED, "%s", ErrNotLeader)
+					res.Xname = xname
+					res.Finished = time.Now()
+				}
+				m.lock.Lock()
+				job.Results = append(job.Results, res)
+				if res.State == RESULT_ROTATED {
+					job.Rotated++
+				} else {
+					job.Failed++
+				}
+				m.lock.Unlock()
+			}
+		}()
+	}
+	for _, xname := range job.Xnames {
+		work <- xname
+	}
+	close(work)
+	wg.Wait()
+
+	m.lock.Lock()
+	now = time.Now()
+	job.State = JOB_STATE_COMPLETED
+	job.Finished = &now
+	log.Printf("INFO: Password rotation job %s finished: %d rotated, %d failed",
+		job.ID, job.Rotated, job.Failed)
+	m.lock.Unlock()
+}
+
+// rotate changes the password of one BMC.
+func (m *Manager) rotate(xname string) Result {
+	res := m.rotateBMC(xname)
+	res.Xname = xname
+	res.Finished = time.Now()
+	switch res.State {
+	case RESULT_ROTATED:
+		log.Printf("INFO: Rotated the password of %s", xname)
+	case RESULT_UNKNOWN:
+		log.Printf("ERROR: The password of %s may not match Vault: %s", xname, res.Error)
+	default:
+		log.Printf("WARNING: Unable to rotate the password of %s (%s): %s", xname, res.State, res.Error)
+	}
+	return res
+}
+
+func (m *Manager) rotateBMC(xname string) Result {
+	creds, err := m.store.GetCompCred(xname)
+	if err != nil {
+		return failed(RESULT_FAILED, "unable to get credentials from Vault: %s", err)
+	}
+	if creds.Username == "" || creds.Password == "" {
+		return failed(RESULT_FAILED, "no credentials in Vault")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+	target := m.targets(ctx, xname)
+	password, err := m.rules.For(target.Vendor).Generate()
+	if err != nil {
+		return failed(RESULT_FAILED, "unable to generate a password: %s", err)
+	}
+
+	err = m.bmc.Apply(ctx, target, creds.Username, creds.Password, password)
+	if err != nil {
+		// The change may have been made even though the reply was lost.
+		if m.bmc.Verify(ctx, target, creds.Username, creds.Password) == nil {
+			return failed(RESULT_FAILED, "unable to set the password: %s", err)
+		}
+		if m.bmc.Verify(ctx, target, creds.Username, password) != nil {
+			return failed(RESULT_UNKNOWN, "unable to set the password, and the BMC takes neither the old nor the new one: %s", err)
+		}
+		return m.rollback(target, creds, password, fmt.Sprintf("unable to set the password: %s", err))
+	}
+
+	err = m.bmc.Verify(ctx, target, creds.Username, password)
+	if err != nil {
+		return m.rollback(target, creds, password, fmt.Sprintf("the BMC doesn't take the new password: %s", err))
+	}
+
+	rotated := creds
+	rotated.Password = password
+	err = m.store.StoreCompCred(rotated)
+	if err != nil {
+		return m.rollback(target, creds, password, fmt.Sprintf("unable to store the new password in Vault: %s", err))
+	}
+	return Result{State: RESULT_ROTATED}
+}
+
+// rollback puts the BMC back to the password Vault has.  It gets a timeout
+// of its own so it still runs when the rotation ran out of time.
+func (m *Manager) rollback(target passwords.Target, creds compcredentials.CompCredentials,
+	password string, cause string) Result {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+	err := m.bmc.Apply(ctx, target, creds.Username, password, creds.Password)
+	if err != nil {
+		return failed(RESULT_UNKNOWN, "%s; unable to put back the old password: %s", cause, err)
+	}
+	return Result{State: RESULT_ROLLED_BACK, Error: cause}
+}
+
+func failed(state string, format string, args ...interface{}) Result {
+	return Result{State: state, Error: fmt.Sprintf(format, args...)}
+}
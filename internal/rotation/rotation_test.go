@@ -0,0 +1,423 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rotation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-reds/internal/model"
+	"github.com/Cray-HPE/hms-reds/internal/passwords"
+)
+
+// mockBMC is a Redfish service with a single root account.
+type mockBMC struct {
+	mu       sync.Mutex
+	password string
+	// Change the password but answer 500 as if the reply were lost, once
+	loseReply bool
+	// Answer PATCH with 400 without changing anything
+	rejectPatch bool
+	// Refuse every change after the first
+	rejectRevert bool
+	// Closed to let requests through, if set
+	hold    chan struct{}
+	patches int
+	srv     *httptest.Server
+}
+
+func newMockBMC(password string) *mockBMC {
+	b := &mockBMC{password: password}
+	b.srv = httptest.NewTLSServer(http.HandlerFunc(b.serve))
+	return b
+}
+
+func (b *mockBMC) serve(w http.ResponseWriter, r *http.Request) {
+	if b.hold != nil {
+		<-b.hold
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if user, pass, ok := r.BasicAuth(); !ok || user != "root" || pass != b.password {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.Method + " " + r.URL.Path {
+	case "GET /redfish/v1/AccountService/Accounts":
+		w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/AccountService/Accounts/1"}]}`))
+	case "GET /redfish/v1/AccountService/Accounts/1":
+		w.Write([]byte(`{"UserName":"root"}`))
+	case "PATCH /redfish/v1/AccountService/Accounts/1":
+		b.patches++
+		if b.rejectPatch || (b.rejectRevert && b.patches > 1) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		b.password = body["Password"]
+		if b.loseReply {
+			b.loseReply = false
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (b *mockBMC) currentPassword() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.password
+}
+
+// memCreds is a credential store in memory.
+type memCreds struct {
+	mu        sync.Mutex
+	creds     map[string]compcredentials.CompCredentials
+	failStore bool
+}
+
+func (m *memCreds) GetCompCred(xname string) (compcredentials.CompCredentials, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.creds[xname], nil
+}
+
+func (m *memCreds) StoreCompCred(cred compcredentials.CompCredentials) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failStore {
+		return fmt.Errorf("vault is sealed")
+	}
+	m.creds[cred.Xname] = cred
+	return nil
+}
+
+func (m *memCreds) password(xname string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.creds[xname].Password
+}
+
+// memOutcomes is an outcome store in memory.
+type memOutcomes struct {
+	mu       sync.Mutex
+	outcomes []model.RotationOutcome
+}
+
+func (m *memOutcomes) GetRotationOutcomes() ([]model.RotationOutcome, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.RotationOutcome{}, m.outcomes...), nil
+}
+
+func (m *memOutcomes) StoreRotationOutcomes(outcomes []model.RotationOutcome) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes = append([]model.RotationOutcome{}, outcomes...)
+	return nil
+}
+
+// newTestManager returns a Manager rotating the given BMCs, each of which
+// starts out with the password "initial" in Vault.
+func newTestManager(bmcs map[string]*mockBMC) (*Manager, *memCreds) {
+	store := &memCreds{creds: make(map[string]compcredentials.CompCredentials)}
+	for xname := range bmcs {
+		store.creds[xname] = compcredentials.CompCredentials{Xname: xname, Username: "root", Password: "initial"}
+	}
+	targets := func(ctx context.Context, xname string) passwords.Target {
+		target := passwords.Target{Xname: xname, Host: xname}
+		if b, ok := bmcs[xname]; ok {
+			target.Host = strings.TrimPrefix(b.srv.URL, "https://")
+		}
+		return target
+	}
+	m := NewManager(store, passwords.NewRedfishApplier(5*time.Second), passwords.RuleSet{}, targets,
+		2, 10*time.Second)
+	return m, store
+}
+
+func waitForJob(t *testing.T, m *Manager, id string) Job {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := m.Job(id)
+		if !ok {
+			t.Fatalf("Job %s not found", id)
+		}
+		if job.State == JOB_STATE_COMPLETED {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %s didn't finish", id)
+	return Job{}
+}
+
+func resultFor(job Job, xname string) Result {
+	for _, res := range job.Results {
+		if res.Xname == xname {
+			return res
+		}
+	}
+	return Result{}
+}
+
+func TestManager_rotate(t *testing.T) {
+	ok := newMockBMC("initial")
+	defer ok.srv.Close()
+	lost := newMockBMC("initial")
+	lost.loseReply = true
+	defer lost.srv.Close()
+	rejected := newMockBMC("initial")
+	rejected.rejectPatch = true
+	defer rejected.srv.Close()
+	wrong := newMockBMC("not-in-vault")
+	defer wrong.srv.Close()
+
+	bmcs := map[string]*mockBMC{
+		"x3000c0s1b0": ok,
+		"x3000c0s2b0": lost,
+		"x3000c0s3b0": rejected,
+		"x3000c0s4b0": wrong,
+	}
+	m, store := newTestManager(bmcs)
+	job, err := m.Start([]string{"x3000c0s4b0", "x3000c0s1b0", "x3000c0s2b0", "x3000c0s3b0", "x3000c0s1b0"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if job.ID != "rotation-1" || len(job.Xnames) != 4 || job.Xnames[0] != "x3000c0s1b0" {
+		t.Errorf("Start() = %+v", job)
+	}
+	job = waitForJob(t, m, job.ID)
+	if job.Rotated != 1 || job.Failed != 3 || len(job.Results) != 4 || job.Finished == nil {
+		t.Errorf("Finished job = %+v", job)
+	}
+
+	if res := resultFor(job, "x3000c0s1b0"); res.State != RESULT_ROTATED {
+		t.Errorf("Result for a working BMC = %+v", res)
+	}
+	if ok.currentPassword() == "initial" || store.password("x3000c0s1b0") != ok.currentPassword() {
+		t.Errorf("Rotated BMC has %q, Vault has %q", ok.currentPassword(), store.password("x3000c0s1b0"))
+	}
+	if len(ok.currentPassword()) != passwords.DefaultRules.Length {
+		t.Errorf("Rotated password %q doesn't follow the default rules", ok.currentPassword())
+	}
+
+	// The change was made but the reply lost, so it's put back.
+	if res := resultFor(job, "x3000c0s2b0"); res.State != RESULT_ROLLED_BACK {
+		t.Errorf("Result for a BMC losing the reply = %+v", res)
+	}
+	if lost.currentPassword() != "initial" || store.password("x3000c0s2b0") != "initial" {
+		t.Errorf("Rolled back BMC has %q, Vault has %q", lost.currentPassword(), store.password("x3000c0s2b0"))
+	}
+
+	if res := resultFor(job, "x3000c0s3b0"); res.State != RESULT_FAILED || !strings.Contains(res.Error, "400") {
+		t.Errorf("Result for a BMC rejecting the change = %+v", res)
+	}
+	if rejected.currentPassword() != "initial" || store.password("x3000c0s3b0") != "initial" {
+		t.Errorf("Unchanged BMC has %q, Vault has %q", rejected.currentPassword(), store.password("x3000c0s3b0"))
+	}
+
+	// Vault was already wrong; nothing can be done about it.
+	if res := resultFor(job, "x3000c0s4b0"); res.State != RESULT_UNKNOWN {
+		t.Errorf("Result for a BMC not matching Vault = %+v", res)
+	}
+	if wrong.currentPassword() != "not-in-vault" || store.password("x3000c0s4b0") != "initial" {
+		t.Errorf("BMC not matching Vault has %q, Vault has %q", wrong.currentPassword(), store.password("x3000c0s4b0"))
+	}
+}
+
+func TestManager_rollbackOnStoreFailure(t *testing.T) {
+	b := newMockBMC("initial")
+	defer b.srv.Close()
+	m, store := newTestManager(map[string]*mockBMC{"x3000c0s1b0": b})
+	store.failStore = true
+
+	job, err := m.Start([]string{"x3000c0s1b0"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	job = waitForJob(t, m, job.ID)
+	res := resultFor(job, "x3000c0s1b0")
+	if res.State != RESULT_ROLLED_BACK || !strings.Contains(res.Error, "vault is sealed") {
+		t.Errorf("Result = %+v", res)
+	}
+	if b.currentPassword() != "initial" || b.patches != 2 {
+		t.Errorf("BMC has %q after %d changes, want it put back", b.currentPassword(), b.patches)
+	}
+
+	// If it can't be put back either the BMC needs a person.
+	b.rejectRevert = true
+	b.patches = 0
+	job, _ = m.Start([]string{"x3000c0s1b0"})
+	job = waitForJob(t, m, job.ID)
+	if res := resultFor(job, "x3000c0s1b0"); res.State != RESULT_UNKNOWN {
+		t.Errorf("Result when the rollback fails = %+v", res)
+	}
+}
+
+func TestManager_Start(t *testing.T) {
+	b := newMockBMC("initial")
+	b.hold = make(chan struct{})
+	defer b.srv.Close()
+	m, _ := newTestManager(map[string]*mockBMC{"x3000c0s1b0": b})
+
+	if _, err := m.Start(nil); err == nil {
+		t.Errorf("Start() with no xnames succeeded")
+	}
+	if _, err := m.Start([]string{"x3000c0s1b0", "bogus"}); err == nil {
+		t.Errorf("Start() with an invalid xname succeeded")
+	}
+	if jobs := m.Jobs(); len(jobs) != 0 {
+		t.Errorf("Jobs() after failed starts = %+v", jobs)
+	}
+
+	first, err := m.Start([]string{"X3000C0S1B0"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	_, err = m.Start([]string{"x3000c0s2b0", "x3000c0s1b0"})
+	if busy, ok := err.(*BusyError); !ok || busy.JobID != first.ID || len(busy.Xnames) != 1 {
+		t.Errorf("Start() of a BMC being rotated error = %v", err)
+	}
+	close(b.hold)
+	waitForJob(t, m, first.ID)
+
+	second, err := m.Start([]string{"x3000c0s1b0"})
+	if err != nil {
+		t.Fatalf("Start() after the first job finished error = %v", err)
+	}
+	waitForJob(t, m, second.ID)
+	if jobs := m.Jobs(); len(jobs) != 2 || jobs[0].ID != first.ID || jobs[1].ID != second.ID {
+		t.Errorf("Jobs() = %+v", jobs)
+	}
+	if _, ok := m.Job("rotation-99"); ok {
+		t.Errorf("Job() found a job that was never started")
+	}
+}
+
+func TestManager_outcomes(t *testing.T) {
+	b := newMockBMC("initial")
+	defer b.srv.Close()
+	m, store := newTestManager(map[string]*mockBMC{"x3000c0s1b0": b})
+	kept := &memOutcomes{}
+	m.SetOutcomeStore(kept)
+
+	// Rolled back, then unknown, then rotated; only the last word counts.
+	store.failStore = true
+	job, _ := m.Start([]string{"x3000c0s1b0"})
+	waitForJob(t, m, job.ID)
+	outcomes, err := m.Outcomes()
+	if err != nil || len(outcomes) != 1 || outcomes[0].State != RESULT_ROLLED_BACK || outcomes[0].JobID != job.ID {
+		t.Errorf("Outcomes() after a rollback = %+v, %v", outcomes, err)
+	}
+
+	b.rejectRevert = true
+	b.patches = 0
+	job, _ = m.Start([]string{"x3000c0s1b0"})
+	waitForJob(t, m, job.ID)
+
+	// A new instance sees what the old one left.
+	m2, _ := newTestManager(map[string]*mockBMC{"x3000c0s1b0": b})
+	m2.SetOutcomeStore(kept)
+	outcomes, err = m2.Outcomes()
+	if err != nil || len(outcomes) != 1 || outcomes[0].State != RESULT_UNKNOWN || outcomes[0].JobID != job.ID {
+		t.Errorf("Outcomes() after the rollback failed = %+v, %v", outcomes, err)
+	}
+
+	// A failure changes nothing, so the unknown outcome stands.
+	b.rejectRevert = false
+	b.rejectPatch = true
+	job, _ = m.Start([]string{"x3000c0s1b0"})
+	waitForJob(t, m, job.ID)
+	if outcomes, _ = m.Outcomes(); len(outcomes) != 1 || outcomes[0].State != RESULT_UNKNOWN {
+		t.Errorf("Outcomes() after a failure = %+v", outcomes)
+	}
+
+	b.rejectPatch = false
+	b.password = "initial"
+	store.failStore = false
+	job, _ = m.Start([]string{"x3000c0s1b0"})
+	job = waitForJob(t, m, job.ID)
+	if job.Rotated != 1 {
+		t.Fatalf("Rotation job = %+v", job)
+	}
+	if outcomes, _ = m.Outcomes(); len(outcomes) != 0 {
+		t.Errorf("Outcomes() after rotating = %+v", outcomes)
+	}
+}
+
+func TestManager_notLeader(t *testing.T) {
+	b := newMockBMC("initial")
+	b.hold = make(chan struct{})
+	defer b.srv.Close()
+	m, _ := newTestManager(map[string]*mockBMC{"x3000c0s1b0": b, "x3000c0s2b0": b, "x3000c0s3b0": b})
+	m.workers = 1
+	var mu sync.Mutex
+	leading := false
+	m.SetLeaderCheck(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return leading
+	})
+
+	if _, err := m.Start([]string{"x3000c0s1b0"}); err != ErrNotLeader {
+		t.Errorf("Start() when not the leader error = %v", err)
+	}
+	if jobs := m.Jobs(); len(jobs) != 0 {
+		t.Errorf("Jobs() after refusing to start = %+v", jobs)
+	}
+
+	mu.Lock()
+	leading = true
+	mu.Unlock()
+	job, err := m.Start([]string{"x3000c0s1b0", "x3000c0s2b0", "x3000c0s3b0"})
+	if err != nil {
+		t.Fatalf("Start() as the leader error = %v", err)
+	}
+
+	// Losing leadership stops the BMCs not yet started.
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	leading = false
+	mu.Unlock()
+	close(b.hold)
+	job = waitForJob(t, m, job.ID)
+	if job.Rotated != 1 || job.Failed != 2 {
+		t.Errorf("Job after losing leadership = %+v", job)
+	}
+	if res := resultFor(job, "x3000c0s3b0"); res.State != RESULT_FAILED || res.Error != ErrNotLeader.Error() {
+		t.Errorf("Result for a BMC after losing leadership = %+v", res)
+	}
+}
@@ -0,0 +1,94 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package securestore
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	sstorage "github.com/Cray-HPE/hms-securestorage"
+)
+
+// Key written under each path by the startup check, and removed again
+const CHECK_KEY = "reds-storage-check"
+
+type checkValue struct {
+	Host string
+	Time string
+}
+
+// Check makes sure each path can be read and, if write is set, written:
+// a value is stored under CHECK_KEY, read back and deleted.  A failed
+// delete is only logged.  On a KV version 2 mount the value must also show
+// up in the metadata, which it doesn't on a version 1 mount.
+func Check(ss sstorage.SecureStorage, paths []string, write bool) error {
+	var failed []string
+	for _, path := range paths {
+		if err := checkPath(ss, path, write); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", path, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("secure store check failed for %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func checkPath(ss sstorage.SecureStorage, path string, write bool) error {
+	key := path + "/" + CHECK_KEY
+	if !write {
+		var ignored map[string]interface{}
+		if err := ss.Lookup(key, &ignored); err != nil {
+			return fmt.Errorf("not readable: %s", err)
+		}
+		return nil
+	}
+
+	want := checkValue{Time: time.Now().UTC().Format(time.RFC3339Nano)}
+	want.Host, _ = os.Hostname()
+	if err := ss.Store(key, want); err != nil {
+		return fmt.Errorf("not writable: %s", err)
+	}
+	var got checkValue
+	if err := ss.Lookup(key, &got); err != nil {
+		return fmt.Errorf("not readable: %s", err)
+	}
+	if got != want {
+		return fmt.Errorf("read back %+v after storing %+v", got, want)
+	}
+	if kv, ok := ss.(*KV2); ok {
+		md, err := kv.LookupMetadata(key)
+		if err != nil {
+			return fmt.Errorf("metadata not readable: %s", err)
+		}
+		if md.CurrentVersion == 0 {
+			return fmt.Errorf("no KV version 2 metadata, is %s a version 1 mount?", kv.Mount)
+		}
+	}
+	if err := ss.Delete(key); err != nil {
+		log.Printf("WARNING: Unable to delete %s after checking it: %s", key, err)
+	}
+	return nil
+}
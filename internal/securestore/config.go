@@ -0,0 +1,200 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package securestore
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	sstorage "github.com/Cray-HPE/hms-securestorage"
+)
+
+// Environment variables the secure store paths are read from, by both REDS
+// and vault_loader
+const (
+	ENV_MOUNT            = "REDS_VAULT_MOUNT"
+	ENV_KV_VERSION       = "REDS_VAULT_KV_VERSION"
+	ENV_REDS_CREDS_PATH  = "REDS_VAULT_REDS_CREDS_PATH"
+	ENV_HMS_CREDS_PATH   = "REDS_VAULT_HMS_CREDS_PATH"
+	ENV_LEADER_PATH      = "REDS_VAULT_LEADER_PATH"
+	DefaultMount         = sstorage.DefaultBasePath
+	DefaultRedsCredsPath = "reds-creds"
+	DefaultHMSCredsPath  = compcredentials.DefaultCompCredPath
+	DefaultLeaderPath    = "reds-leader"
+)
+
+// Config says where in the secure store REDS keeps things.  Paths are
+// relative to the mount of the KV secrets engine.
+type Config struct {
+	Mount string
+	// Version of the KV secrets engine, 1 or 2
+	KVVersion int
+	// Default credentials, their history and credential policies
+	RedsCreds string
+	// Credentials of each BMC and switch, shared with HSM
+	HMSCreds string
+	// Leader election lease
+	Leader string
+}
+
+// DefaultConfig returns the paths REDS has always used, on a KV version 1
+// mount.
+func DefaultConfig() Config {
+	return Config{
+		Mount:     DefaultMount,
+		KVVersion: 1,
+		RedsCreds: DefaultRedsCredsPath,
+		HMSCreds:  DefaultHMSCredsPath,
+		Leader:    DefaultLeaderPath,
+	}
+}
+
+// ConfigFromEnvironment returns the default configuration with whatever the
+// environment overrides.
+func ConfigFromEnvironment() (Config, error) {
+	c := DefaultConfig()
+	if v, ok := os.LookupEnv(ENV_MOUNT); ok {
+		c.Mount = v
+	}
+	if v, ok := os.LookupEnv(ENV_KV_VERSION); ok {
+		version, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(v), "v"))
+		if err != nil {
+			return c, fmt.Errorf("invalid %s %q", ENV_KV_VERSION, v)
+		}
+		c.KVVersion = version
+	}
+	if v, ok := os.LookupEnv(ENV_REDS_CREDS_PATH); ok {
+		c.RedsCreds = v
+	}
+	if v, ok := os.LookupEnv(ENV_HMS_CREDS_PATH); ok {
+		c.HMSCreds = v
+	}
+	if v, ok := os.LookupEnv(ENV_LEADER_PATH); ok {
+		c.Leader = v
+	}
+	c.Mount = strings.Trim(c.Mount, "/")
+	c.RedsCreds = strings.Trim(c.RedsCreds, "/")
+	c.HMSCreds = strings.Trim(c.HMSCreds, "/")
+	c.Leader = strings.Trim(c.Leader, "/")
+	return c, c.Validate()
+}
+
+// Validate checks the KV version and that every path is set and stays
+// inside the mount.
+func (c Config) Validate() error {
+	if c.KVVersion != 1 && c.KVVersion != 2 {
+		return fmt.Errorf("unsupported KV secrets engine version %d", c.KVVersion)
+	}
+	for _, p := range []struct{ name, path string }{
+		{"mount", c.Mount}, {"REDS credentials path", c.RedsCreds},
+		{"HMS credentials path", c.HMSCreds}, {"leader path", c.Leader},
+	} {
+		if p.path == "" {
+			return fmt.Errorf("no %s", p.name)
+		}
+		for _, part := range strings.Split(p.path, "/") {
+			if part == "" || part == "." || part == ".." {
+				return fmt.Errorf("invalid %s %q", p.name, p.path)
+			}
+		}
+	}
+	if c.KVVersion == 2 {
+		for _, p := range []string{c.RedsCreds, c.HMSCreds, c.Leader} {
+			first := strings.SplitN(p, "/", 2)[0]
+			if first == "data" || first == "metadata" {
+				return fmt.Errorf("path %q clashes with the KV version 2 layout", p)
+			}
+		}
+	}
+	return nil
+}
+
+// RedsCredsPath returns the key the REDS credentials are kept under.
+func (c Config) RedsCredsPath() string {
+	return c.Mount + "/" + c.RedsCreds
+}
+
+// HMSCredsPath returns the key the BMC and switch credentials are kept
+// under.
+func (c Config) HMSCredsPath() string {
+	return c.Mount + "/" + c.HMSCreds
+}
+
+// LeaderPath returns the key of the leader election lease.
+func (c Config) LeaderPath() string {
+	return c.Mount + "/" + c.Leader
+}
+
+// Paths returns the paths REDS reads and writes credentials under.
+func (c Config) Paths() []string {
+	return []string{c.RedsCredsPath(), c.HMSCredsPath()}
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("mount: %s (KV v%d), reds-creds: %s, hms-creds: %s, leader: %s",
+		c.Mount, c.KVVersion, c.RedsCredsPath(), c.HMSCredsPath(), c.LeaderPath())
+}
+
+var config = DefaultConfig()
+var configLock sync.Mutex
+
+// SetConfig sets the secure store configuration.  Call it before anything
+// connects to the secure store.
+func SetConfig(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	configLock.Lock()
+	defer configLock.Unlock()
+	config = c
+	return nil
+}
+
+// GetConfig returns the secure store configuration in use.
+func GetConfig() Config {
+	configLock.Lock()
+	defer configLock.Unlock()
+	return config
+}
+
+// Wrap adapts ss, which takes keys as paths in Vault, to the layout of the
+// configured KV secrets engine.
+func Wrap(ss sstorage.SecureStorage) sstorage.SecureStorage {
+	c := GetConfig()
+	if c.KVVersion == 2 {
+		return NewKV2(ss, c.Mount)
+	}
+	return ss
+}
+
+// Connect connects to Vault for the configured KV secrets engine.
+func Connect() (sstorage.SecureStorage, error) {
+	ss, err := sstorage.NewVaultAdapter("")
+	if err != nil {
+		return nil, err
+	}
+	return Wrap(ss), nil
+}
@@ -0,0 +1,125 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package securestore
+
+import (
+	"fmt"
+	"strings"
+
+	sstorage "github.com/Cray-HPE/hms-securestorage"
+	"github.com/mitchellh/mapstructure"
+)
+
+// KV2 is a SecureStorage for a KV version 2 secrets engine.  Keys are given
+// as for version 1, <mount>/<path>.  The values are kept under
+// <mount>/data/<path>, and keys are listed and deleted, with all their
+// versions, through <mount>/metadata/<path>.
+type KV2 struct {
+	SS    sstorage.SecureStorage
+	Mount string
+}
+
+// NewKV2 creates a KV2 on top of ss, which takes keys as paths in Vault.
+func NewKV2(ss sstorage.SecureStorage, mount string) *KV2 {
+	return &KV2{SS: ss, Mount: strings.Trim(mount, "/")}
+}
+
+// Metadata is what a KV version 2 secrets engine keeps about a key.
+type Metadata struct {
+	CurrentVersion int    `mapstructure:"current_version" json:"currentVersion"`
+	OldestVersion  int    `mapstructure:"oldest_version" json:"oldestVersion"`
+	CreatedTime    string `mapstructure:"created_time" json:"createdTime"`
+	UpdatedTime    string `mapstructure:"updated_time" json:"updatedTime"`
+}
+
+// kv2Secret is how a version of a key is read back.
+type kv2Secret struct {
+	Data     map[string]interface{}
+	Metadata map[string]interface{}
+}
+
+// path returns where key is kept under section, data or metadata.
+func (kv *KV2) path(section, key string) (string, error) {
+	key = strings.Trim(key, "/")
+	if !strings.HasPrefix(key, kv.Mount+"/") {
+		return "", fmt.Errorf("key %s is outside mount %s", key, kv.Mount)
+	}
+	return kv.Mount + "/" + section + "/" + strings.TrimPrefix(key, kv.Mount+"/"), nil
+}
+
+func (kv *KV2) Store(key string, value interface{}) error {
+	path, err := kv.path("data", key)
+	if err != nil {
+		return err
+	}
+	var data map[string]interface{}
+	if err := mapstructure.Decode(value, &data); err != nil {
+		return err
+	}
+	return kv.SS.Store(path, map[string]interface{}{"data": data})
+}
+
+// Lookup reads the current version of key.  Like Vault's version 1 engine,
+// a missing or deleted key leaves output alone.
+func (kv *KV2) Lookup(key string, output interface{}) error {
+	path, err := kv.path("data", key)
+	if err != nil {
+		return err
+	}
+	var secret kv2Secret
+	if err := kv.SS.Lookup(path, &secret); err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		return nil
+	}
+	return mapstructure.Decode(secret.Data, output)
+}
+
+// Delete removes key with all its versions and metadata.
+func (kv *KV2) Delete(key string) error {
+	path, err := kv.path("metadata", key)
+	if err != nil {
+		return err
+	}
+	return kv.SS.Delete(path)
+}
+
+func (kv *KV2) LookupKeys(keyPath string) ([]string, error) {
+	path, err := kv.path("metadata", keyPath)
+	if err != nil {
+		return nil, err
+	}
+	return kv.SS.LookupKeys(path)
+}
+
+// LookupMetadata returns the metadata of key.  A key that was never
+// stored has a CurrentVersion of 0.
+func (kv *KV2) LookupMetadata(key string) (Metadata, error) {
+	var md Metadata
+	path, err := kv.path("metadata", key)
+	if err != nil {
+		return md, err
+	}
+	err = kv.SS.Lookup(path, &md)
+	return md, err
+}
@@ -0,0 +1,176 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package securestore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sstorage "github.com/Cray-HPE/hms-securestorage"
+)
+
+// Prefix of references to secrets, e.g. in SLS ExtraProperties
+const REFERENCE_PREFIX = "vault://"
+
+// How long a resolved secret is kept
+const DefaultReferenceTTL = 30 * time.Second
+
+// ErrUnresolved is wrapped by the errors of references that don't resolve.
+var ErrUnresolved = errors.New("unresolved reference")
+
+// Reference is a parsed vault:// URL:
+//
+//	vault://[<mount>/]<path>[#<field>]
+//
+// The path is relative to the mount of the KV secrets engine, which may be
+// given first.  Without a field, the one the reference stands in for is
+// used, e.g. SNMPAuthPass for an SNMP authentication password.
+type Reference struct {
+	Mount string
+	Path  string
+	Field string
+}
+
+// IsReference reports whether value is a vault:// URL.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, REFERENCE_PREFIX)
+}
+
+// ParseReference parses a vault:// URL against the configured mount.
+func ParseReference(value string) (Reference, error) {
+	if !IsReference(value) {
+		return Reference{}, fmt.Errorf("%q isn't a %s reference", value, REFERENCE_PREFIX)
+	}
+	ref := Reference{Mount: GetConfig().Mount}
+	rest := strings.TrimPrefix(value, REFERENCE_PREFIX)
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		ref.Field = rest[i+1:]
+		rest = rest[:i]
+		if ref.Field == "" {
+			return Reference{}, fmt.Errorf("reference %s has an empty field", value)
+		}
+	}
+	rest = strings.Trim(rest, "/")
+	if strings.HasPrefix(rest, ref.Mount+"/") {
+		rest = strings.TrimPrefix(rest, ref.Mount+"/")
+	}
+	if rest == "" || rest == ref.Mount || strings.ContainsAny(rest, "?") {
+		return Reference{}, fmt.Errorf("reference %s has no valid path", value)
+	}
+	for _, part := range strings.Split(rest, "/") {
+		if part == "" || part == "." || part == ".." {
+			return Reference{}, fmt.Errorf("reference %s has no valid path", value)
+		}
+	}
+	ref.Path = rest
+	return ref, nil
+}
+
+// Key returns the secure store key of the secret referred to.
+func (r Reference) Key() string {
+	return r.Mount + "/" + r.Path
+}
+
+func (r Reference) String() string {
+	if r.Field == "" {
+		return REFERENCE_PREFIX + r.Key()
+	}
+	return REFERENCE_PREFIX + r.Key() + "#" + r.Field
+}
+
+type cachedSecret struct {
+	data    map[string]interface{}
+	expires time.Time
+}
+
+// Resolver looks up references in a secure store, keeping each secret for
+// TTL so references to the same secret read it once.
+type Resolver struct {
+	SS  sstorage.SecureStorage
+	TTL time.Duration
+
+	lock  sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// NewResolver creates a Resolver for ss.
+func NewResolver(ss sstorage.SecureStorage, ttl time.Duration) *Resolver {
+	return &Resolver{SS: ss, TTL: ttl, cache: make(map[string]cachedSecret)}
+}
+
+// secret returns the secret at key, from the cache if it's there.
+func (r *Resolver) secret(key string) (map[string]interface{}, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if cached, ok := r.cache[key]; ok && time.Now().Before(cached.expires) {
+		return cached.data, nil
+	}
+	data := make(map[string]interface{})
+	if err := r.SS.Lookup(key, &data); err != nil {
+		return nil, err
+	}
+	// Missing secrets aren't kept, so they resolve as soon as they're added
+	if len(data) != 0 {
+		r.cache[key] = cachedSecret{data: data, expires: time.Now().Add(r.TTL)}
+	}
+	return data, nil
+}
+
+// Resolve returns the value of the field of ref, or of field if ref doesn't
+// name one.  Field names match ignoring case.  Errors other than failing to
+// read the secure store wrap ErrUnresolved.
+func (r *Resolver) Resolve(ref Reference, field string) (string, error) {
+	if ref.Field != "" {
+		field = ref.Field
+	}
+	data, err := r.secret(ref.Key())
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %s", ref.Key(), err)
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("%w %s: no secret at %s", ErrUnresolved, ref, ref.Key())
+	}
+	for name, value := range data {
+		if !strings.EqualFold(name, field) {
+			continue
+		}
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w %s: %s isn't a string", ErrUnresolved, ref, field)
+		}
+		if s == "" {
+			return "", fmt.Errorf("%w %s: %s is empty", ErrUnresolved, ref, field)
+		}
+		return s, nil
+	}
+	return "", fmt.Errorf("%w %s: no %s in %s", ErrUnresolved, ref, field, ref.Key())
+}
+
+// Invalidate drops the cached secret at key, e.g. after storing it.
+func (r *Resolver) Invalidate(key string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.cache, key)
+}
@@ -0,0 +1,347 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package securestore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// vaultMock stands in for Vault with a KV secrets engine mounted at
+// "secret", of version 1 or 2.  Values are kept as Vault hands them back,
+// decoded JSON.
+type vaultMock struct {
+	kv2      bool
+	data     map[string]interface{}
+	versions map[string]int
+	readOnly bool
+}
+
+func newVaultMock(kv2 bool) *vaultMock {
+	return &vaultMock{kv2: kv2, data: make(map[string]interface{}), versions: make(map[string]int)}
+}
+
+// split returns the section, data or metadata, and the key of a KV v2 path.
+func (v *vaultMock) split(path string) (string, string, error) {
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) != 3 || parts[0] != "secret" || (parts[1] != "data" && parts[1] != "metadata") {
+		return "", "", errors.New("Code: 404. Invalid path for a versioned K/V secrets engine")
+	}
+	return parts[1], parts[2], nil
+}
+
+func (v *vaultMock) Store(path string, value interface{}) error {
+	if v.readOnly {
+		return errors.New("Code: 403. permission denied")
+	}
+	var data map[string]interface{}
+	if err := mapstructure.Decode(value, &data); err != nil {
+		return err
+	}
+	raw, _ := json.Marshal(data)
+	var stored map[string]interface{}
+	json.Unmarshal(raw, &stored)
+	key := path
+	if v.kv2 {
+		section, k, err := v.split(path)
+		if err != nil {
+			return err
+		}
+		if section != "data" {
+			return errors.New("Code: 405. metadata is not writable here")
+		}
+		if _, ok := stored["data"]; !ok {
+			return errors.New("Code: 400. no data provided")
+		}
+		key = k
+		stored = stored["data"].(map[string]interface{})
+	}
+	v.data[key] = stored
+	v.versions[key]++
+	return nil
+}
+
+func (v *vaultMock) Lookup(path string, output interface{}) error {
+	if !v.kv2 {
+		if stored, ok := v.data[path]; ok {
+			return mapstructure.Decode(stored, output)
+		}
+		return nil
+	}
+	section, key, err := v.split(path)
+	if err != nil {
+		return err
+	}
+	stored, ok := v.data[key]
+	if !ok {
+		return nil
+	}
+	metadata := map[string]interface{}{"current_version": json.Number(strconv.Itoa(v.versions[key])),
+		"oldest_version": json.Number("1"), "created_time": "2026-10-18T00:00:00Z",
+		"updated_time": "2026-10-18T00:00:00Z"}
+	if section == "metadata" {
+		return mapstructure.Decode(metadata, output)
+	}
+	return mapstructure.Decode(map[string]interface{}{"data": stored, "metadata": metadata}, output)
+}
+
+func (v *vaultMock) Delete(path string) error {
+	key := path
+	if v.kv2 {
+		section, k, err := v.split(path)
+		if err != nil {
+			return err
+		}
+		if section != "metadata" {
+			return errors.New("Code: 405. only soft deletes through data")
+		}
+		key = k
+	}
+	delete(v.data, key)
+	delete(v.versions, key)
+	return nil
+}
+
+func (v *vaultMock) LookupKeys(path string) ([]string, error) {
+	prefix := path + "/"
+	if v.kv2 {
+		_, k, err := v.split(path)
+		if err != nil {
+			return nil, err
+		}
+		prefix = k + "/"
+	}
+	var keys []string
+	for key := range v.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, strings.TrimPrefix(key, prefix))
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+type testCreds struct {
+	Username string
+	Password string
+	Nested   map[string]string
+}
+
+func TestKV2(t *testing.T) {
+	vault := newVaultMock(true)
+	kv := NewKV2(vault, "secret/")
+
+	want := testCreds{Username: "root", Password: "initial0", Nested: map[string]string{"a": "b"}}
+	if err := kv.Store("secret/reds-creds/defaults", want); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if err := kv.Store("secret/reds-creds/defaults", want); err != nil {
+		t.Fatalf("Store() again error = %v", err)
+	}
+	var got testCreds
+	if err := kv.Lookup("secret/reds-creds/defaults", &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookup() = %+v, %v, want %+v", got, err, want)
+	}
+	md, err := kv.LookupMetadata("secret/reds-creds/defaults")
+	if err != nil || md.CurrentVersion != 2 || md.OldestVersion != 1 || md.CreatedTime == "" {
+		t.Errorf("LookupMetadata() = %+v, %v", md, err)
+	}
+	if keys, err := kv.LookupKeys("secret/reds-creds"); err != nil || !reflect.DeepEqual(keys, []string{"defaults"}) {
+		t.Errorf("LookupKeys() = %v, %v", keys, err)
+	}
+
+	if err := kv.Delete("secret/reds-creds/defaults"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	var missing testCreds
+	if err := kv.Lookup("secret/reds-creds/defaults", &missing); err != nil || missing.Username != "" {
+		t.Errorf("Lookup() of a deleted key = %+v, %v", missing, err)
+	}
+	if md, err := kv.LookupMetadata("secret/reds-creds/defaults"); err != nil || md.CurrentVersion != 0 {
+		t.Errorf("LookupMetadata() of a deleted key = %+v, %v", md, err)
+	}
+
+	if err := kv.Store("kv/reds-creds/defaults", want); err == nil {
+		t.Errorf("Store() outside the mount succeeded")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	paths := []string{"secret/reds-creds", "secret/hms-creds"}
+
+	v1 := newVaultMock(false)
+	if err := Check(v1, paths, true); err != nil {
+		t.Errorf("Check() of a KV v1 mount error = %v", err)
+	}
+	if len(v1.data) != 0 {
+		t.Errorf("Check() left %v behind", v1.data)
+	}
+
+	v2 := newVaultMock(true)
+	if err := Check(NewKV2(v2, "secret"), paths, true); err != nil {
+		t.Errorf("Check() of a KV v2 mount error = %v", err)
+	}
+	if len(v2.data) != 0 {
+		t.Errorf("Check() left %v behind", v2.data)
+	}
+
+	// Versions that don't match the mount
+	if err := Check(v2, paths, true); err == nil {
+		t.Errorf("Check() of a KV v2 mount as version 1 succeeded")
+	}
+	if err := Check(NewKV2(v1, "secret"), paths, true); err == nil {
+		t.Errorf("Check() of a KV v1 mount as version 2 succeeded")
+	}
+
+	v1.readOnly = true
+	err := Check(v1, paths, true)
+	if err == nil || !strings.Contains(err.Error(), "secret/hms-creds") {
+		t.Errorf("Check() of a read only store error = %v", err)
+	}
+	if err := Check(v1, paths, false); err != nil {
+		t.Errorf("Check() of a read only store without writing error = %v", err)
+	}
+}
+
+// setenv sets an environment variable for the rest of a test.
+func setenv(t *testing.T, key, value string) {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestConfigFromEnvironment(t *testing.T) {
+	c, err := ConfigFromEnvironment()
+	if err != nil || c != DefaultConfig() {
+		t.Errorf("ConfigFromEnvironment() with nothing set = %+v, %v", c, err)
+	}
+	if c.RedsCredsPath() != "secret/reds-creds" || c.HMSCredsPath() != "secret/hms-creds" ||
+		c.LeaderPath() != "secret/reds-leader" {
+		t.Errorf("Default paths = %s", c)
+	}
+
+	setenv(t, ENV_MOUNT, "/kv/")
+	setenv(t, ENV_KV_VERSION, "v2")
+	setenv(t, ENV_REDS_CREDS_PATH, "csm/reds-creds")
+	c, err = ConfigFromEnvironment()
+	if err != nil || c.KVVersion != 2 || c.RedsCredsPath() != "kv/csm/reds-creds" || c.HMSCredsPath() != "kv/hms-creds" {
+		t.Errorf("ConfigFromEnvironment() = %s, %v", c, err)
+	}
+
+	for env, value := range map[string]string{
+		ENV_KV_VERSION:      "3",
+		ENV_HMS_CREDS_PATH:  "",
+		ENV_LEADER_PATH:     "../leader",
+		ENV_REDS_CREDS_PATH: "data/reds-creds",
+	} {
+		t.Run(env, func(t *testing.T) {
+			setenv(t, env, value)
+			if _, err := ConfigFromEnvironment(); err == nil {
+				t.Errorf("ConfigFromEnvironment() with %s=%q succeeded", env, value)
+			}
+		})
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Reference
+		err   bool
+	}{
+		{"vault://hms-creds/x3000c0r24b0", Reference{Mount: "secret", Path: "hms-creds/x3000c0r24b0"}, false},
+		{"vault://secret/hms-creds/x3000c0r24b0", Reference{Mount: "secret", Path: "hms-creds/x3000c0r24b0"}, false},
+		{"vault://switches/shared#auth", Reference{Mount: "secret", Path: "switches/shared", Field: "auth"}, false},
+		{"vault://switches/shared/", Reference{Mount: "secret", Path: "switches/shared"}, false},
+		{"hms-creds/x3000c0r24b0", Reference{}, true},
+		{"vault://", Reference{}, true},
+		{"vault://secret/", Reference{}, true},
+		{"vault://hms-creds/x3000c0r24b0#", Reference{}, true},
+		{"vault://hms-creds/../reds-creds", Reference{}, true},
+		{"vault://hms-creds//x3000c0r24b0", Reference{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseReference(tt.value)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseReference(%q) = %+v, %v, want %+v, error %v", tt.value, got, err, tt.want, tt.err)
+		}
+	}
+	if ref, _ := ParseReference("vault://switches/shared#auth"); ref.Key() != "secret/switches/shared" ||
+		ref.String() != "vault://secret/switches/shared#auth" {
+		t.Errorf("Reference.Key() = %s, Reference.String() = %s", ref.Key(), ref)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	vault := newVaultMock(false)
+	vault.Store("secret/switches/shared", map[string]interface{}{"SNMPAuthPass": "authpass", "user": "snmpuser",
+		"count": 3, "empty": ""})
+	resolver := NewResolver(vault, DefaultReferenceTTL)
+
+	ref, _ := ParseReference("vault://switches/shared")
+	if got, err := resolver.Resolve(ref, "snmpauthpass"); err != nil || got != "authpass" {
+		t.Errorf("Resolve() = %q, %v, want authpass", got, err)
+	}
+	ref, _ = ParseReference("vault://switches/shared#user")
+	if got, err := resolver.Resolve(ref, "SNMPAuthPass"); err != nil || got != "snmpuser" {
+		t.Errorf("Resolve() of a field = %q, %v, want snmpuser", got, err)
+	}
+
+	for _, value := range []string{"vault://switches/shared#count", "vault://switches/shared#empty",
+		"vault://switches/shared#missing", "vault://switches/other"} {
+		ref, _ = ParseReference(value)
+		if _, err := resolver.Resolve(ref, "SNMPAuthPass"); !errors.Is(err, ErrUnresolved) {
+			t.Errorf("Resolve(%s) error = %v, want %v", value, err, ErrUnresolved)
+		}
+	}
+
+	// Secrets are cached until they're invalidated
+	vault.Store("secret/switches/shared", map[string]interface{}{"SNMPAuthPass": "newpass"})
+	ref, _ = ParseReference("vault://switches/shared")
+	if got, _ := resolver.Resolve(ref, "SNMPAuthPass"); got != "authpass" {
+		t.Errorf("Resolve() before Invalidate() = %q, want authpass", got)
+	}
+	resolver.Invalidate(ref.Key())
+	if got, _ := resolver.Resolve(ref, "SNMPAuthPass"); got != "newpass" {
+		t.Errorf("Resolve() after Invalidate() = %q, want newpass", got)
+	}
+
+	// Failing to read isn't an unresolved reference
+	resolver = NewResolver(newVaultMock(true), DefaultReferenceTTL)
+	if _, err := resolver.Resolve(ref, "SNMPAuthPass"); err == nil || errors.Is(err, ErrUnresolved) {
+		t.Errorf("Resolve() of an unreadable secret error = %v", err)
+	}
+}
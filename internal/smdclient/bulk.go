@@ -0,0 +1,206 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base"
+)
+
+// Default number of items sent to HSM in one bulk request
+const DefaultBatchSize = 100
+
+// RegistrationResult is the outcome of registering one item in bulk.  Err is
+// nil on success and an *HSMError otherwise.  Update is set for an endpoint
+// HSM already had, Changes for a component HSM already had that was changed.
+type RegistrationResult struct {
+	ID      string
+	Err     error
+	Update  *EndpointUpdate
+	Changes []FieldChange
+}
+
+// batches splits n items into [start, end) ranges of at most size items.
+func batches(n int, size int) [][2]int {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	var ranges [][2]int
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// RegisterRedfishEndpoints adds endpoints to HSM in batches of batchSize.
+// HSM rejects a whole batch if any endpoint in it is bad or already exists,
+// so when a batch fails for any reason other than HSM being unavailable its
+// endpoints are sent one by one through NotifyHSMDiscoveredWithGeolocation,
+// which updates the ones HSM already has.  If HSM is unavailable every
+// endpoint in the batch gets the batch's error.  Results are returned in the
+// order of eps.
+func RegisterRedfishEndpoints(eps []HSMNotification, batchSize int) []RegistrationResult {
+	results := make([]RegistrationResult, len(eps))
+	for _, r := range batches(len(eps), batchSize) {
+		batch := eps[r[0]:r[1]]
+		log.Printf("INFO: Adding %d endpoints to HSM, %s through %s",
+			len(batch), batch[0].ID, batch[len(batch)-1].ID)
+
+		err := hsmClient.CreateRedfishEndpoints(batch)
+		if err != nil && !IsRetryable(err) {
+			log.Printf("WARNING: Bulk add of %d endpoints failed, adding them one at a time: %s",
+				len(batch), err)
+			for i, ep := range batch {
+				update, err := registerEndpoint(ep)
+				results[r[0]+i] = RegistrationResult{ID: ep.ID, Err: err, Update: update}
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("WARNING: Bulk add of %d endpoints failed: %s", len(batch), err)
+		}
+		for i, ep := range batch {
+			results[r[0]+i] = RegistrationResult{ID: ep.ID, Err: err}
+		}
+	}
+	return results
+}
+
+// CreateComponents adds components to HSM under /State/Components in
+// batches of batchSize.  Existing components are left alone.  A batch that
+// fails for any reason other than HSM being unavailable is retried one
+// component at a time so one bad component doesn't hold back the rest.
+// Results are returned in the order of comps.
+func CreateComponents(comps []base.Component, batchSize int) []RegistrationResult {
+	return postComponents(comps, batchSize, false)
+}
+
+// postComponents does the work of CreateComponents.  With force set,
+// existing components are replaced.
+func postComponents(comps []base.Component, batchSize int, force bool) []RegistrationResult {
+	results := make([]RegistrationResult, len(comps))
+	for _, r := range batches(len(comps), batchSize) {
+		batch := comps[r[0]:r[1]]
+		log.Printf("INFO: Creating %d components in HSM, %s through %s",
+			len(batch), batch[0].ID, batch[len(batch)-1].ID)
+
+		err := hsmClient.CreateComponents(HSMCompNotification{Components: batch, Force: force})
+		if err != nil && !IsRetryable(err) {
+			log.Printf("WARNING: Bulk create of %d components failed, creating them one at a time: %s",
+				len(batch), err)
+			for i, comp := range batch {
+				results[r[0]+i] = RegistrationResult{
+					ID: comp.ID,
+					Err: HSMCreateComponent(HSMCompNotification{
+						Components: []base.Component{comp},
+						Force:      force,
+					}),
+				}
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("WARNING: Bulk create of %d components failed: %s", len(batch), err)
+		}
+		for i, comp := range batch {
+			results[r[0]+i] = RegistrationResult{ID: comp.ID, Err: err}
+		}
+	}
+	return results
+}
+
+// diffComponent lists the attributes REDS manages that differ between a
+// component in HSM and what REDS wants.
+func diffComponent(existing base.Component, want base.Component) []FieldChange {
+	var changes []FieldChange
+	compare := func(field string, old string, new string) {
+		if !strings.EqualFold(old, new) {
+			changes = append(changes, FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	compare("Role", existing.Role, want.Role)
+	compare("SubRole", existing.SubRole, want.SubRole)
+	compare("NID", existing.NID.String(), want.NID.String())
+	compare("NetType", existing.NetType, want.NetType)
+	compare("Arch", existing.Arch, want.Arch)
+	compare("Class", existing.Class, want.Class)
+	return changes
+}
+
+// SyncComponents makes HSM's components match comps.  Missing components
+// are created in bulk.  Existing ones whose Role, SubRole, NID, NetType, Arch
+// or Class differ are replaced, keeping the State, Flag, Enabled and
+// SoftwareStatus HSM has for them.  Results are returned in the order of
+// comps, with the changes made to existing components.
+func SyncComponents(comps []base.Component, batchSize int) []RegistrationResult {
+	results := make([]RegistrationResult, len(comps))
+	var create, replace []base.Component
+	var createIdx, replaceIdx []int
+	for i, comp := range comps {
+		results[i].ID = comp.ID
+		existing, err := hsmClient.GetComponent(comp.ID)
+		if StatusCode(err) == http.StatusNotFound {
+			create = append(create, comp)
+			createIdx = append(createIdx, i)
+			continue
+		} else if err != nil {
+			results[i].Err = err
+			continue
+		}
+		changes := diffComponent(*existing, comp)
+		if len(changes) == 0 {
+			continue
+		}
+		if err := checkUnlocked(comp.ID); err != nil {
+			results[i].Err = err
+			continue
+		}
+		log.Printf("INFO: Updating component %s", EndpointUpdate{ID: comp.ID, Changes: changes})
+		comp.State = existing.State
+		comp.Flag = existing.Flag
+		comp.Enabled = existing.Enabled
+		comp.SwStatus = existing.SwStatus
+		results[i].Changes = changes
+		replace = append(replace, comp)
+		replaceIdx = append(replaceIdx, i)
+	}
+
+	if len(create) > 0 {
+		for i, res := range postComponents(create, batchSize, false) {
+			results[createIdx[i]].Err = res.Err
+		}
+	}
+	if len(replace) > 0 {
+		for i, res := range postComponents(replace, batchSize, true) {
+			results[replaceIdx[i]].Err = res.Err
+		}
+	}
+	return results
+}
@@ -0,0 +1,174 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"net/http"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base"
+)
+
+func TestRegisterRedfishEndpoints(t *testing.T) {
+	fake := NewFakeHSM()
+	SetClient(fake)
+	defer SetClient(nil)
+
+	disabled := false
+	fake.RedfishEndpoints["x3000c0s5b0"] = RedfishEndpoint{ID: "x3000c0s5b0", Enabled: &disabled}
+
+	var eps []HSMNotification
+	for _, id := range []string{"x3000c0s1b0", "x3000c0s3b0", "x3000c0s5b0", "x3000c0s7b0", "", "x3000c0s9b0"} {
+		eps = append(eps, HSMNotification{ID: id, RediscoverOnUpdate: true})
+	}
+
+	// The first batch goes in whole.  The second has an existing endpoint and
+	// the third a bad one, so both are sent again one at a time.
+	results := RegisterRedfishEndpoints(eps, 2)
+	if len(results) != len(eps) {
+		t.Fatalf("Got %d results, want %d", len(results), len(eps))
+	}
+	for i, res := range results {
+		if res.ID != eps[i].ID {
+			t.Errorf("Result %d is for %q, want %q", i, res.ID, eps[i].ID)
+		}
+		if wantErr := eps[i].ID == ""; (res.Err != nil) != wantErr {
+			t.Errorf("Result for %q: error = %v", res.ID, res.Err)
+		}
+	}
+	if StatusCode(results[4].Err) != http.StatusBadRequest {
+		t.Errorf("Bad endpoint gave %v, want 400", results[4].Err)
+	}
+	if !*fake.RedfishEndpoints["x3000c0s5b0"].Enabled {
+		t.Errorf("Existing endpoint was not re-enabled")
+	}
+	if u := results[2].Update; u == nil || len(u.Changes) != 1 || u.Changes[0].Field != "Enabled" {
+		t.Errorf("Existing endpoint update was %+v", u)
+	}
+
+	var got []string
+	for _, req := range fake.GetRequests() {
+		n := 1
+		if batch, ok := req.Body.([]HSMNotification); ok {
+			n = len(batch)
+		}
+		got = append(got, req.Method+" "+string(rune('0'+n)))
+	}
+	want := []string{"POST 2", "POST 2", "POST 1", "GET 1", "POST 1", "PATCH 1", "POST 1", "POST 2", "POST 1", "POST 1"}
+	if len(got) != len(want) {
+		t.Fatalf("Requests were %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Requests were %v, want %v", got, want)
+		}
+	}
+
+	// When HSM is down every endpoint in the batch gets the error and
+	// nothing is tried one at a time.
+	fake.ClearRequests()
+	fake.FailWith = func(method string, path string) int { return http.StatusServiceUnavailable }
+	results = RegisterRedfishEndpoints(eps[:2], 10)
+	for _, res := range results {
+		if !IsRetryable(res.Err) {
+			t.Errorf("Result for %q: error = %v, want retryable", res.ID, res.Err)
+		}
+	}
+	if n := len(fake.GetRequests()); n != 1 {
+		t.Errorf("Made %d requests with HSM down, want 1", n)
+	}
+}
+
+func TestCreateComponents(t *testing.T) {
+	fake := NewFakeHSM()
+	SetClient(fake)
+	defer SetClient(nil)
+
+	comps := []base.Component{{ID: "x3000c0s1b0n0"}, {ID: ""}, {ID: "x3000c0s3b0n0"}}
+	results := CreateComponents(comps, 5)
+	if results[0].Err != nil || results[2].Err != nil || StatusCode(results[1].Err) != http.StatusBadRequest {
+		t.Errorf("Results were %+v", results)
+	}
+	if _, ok := fake.Components["x3000c0s3b0n0"]; !ok {
+		t.Errorf("Good component after a bad one was not created")
+	}
+	if n := len(fake.GetRequests()); n != 4 {
+		t.Errorf("Made %d requests, want one bulk and three single", n)
+	}
+}
+
+func TestSyncComponents(t *testing.T) {
+	fake := NewFakeHSM()
+	SetClient(fake)
+	defer SetClient(nil)
+
+	enabled := true
+	fake.Components["x3000c0s1b0n0"] = base.Component{ID: "x3000c0s1b0n0", Type: "Node", State: "Ready",
+		Flag: "OK", Enabled: &enabled, Role: "Management", SubRole: "Master", NID: "100001",
+		Arch: "X86", NetType: "Sling", Class: "River"}
+	fake.Components["x3000c0s3b0n0"] = base.Component{ID: "x3000c0s3b0n0", Type: "Node", State: "On",
+		Role: "Management", SubRole: "Master", NID: "100002", Arch: "X86", NetType: "Sling", Class: "River"}
+
+	want := []base.Component{
+		// Changed in SLS
+		{ID: "x3000c0s1b0n0", State: "Populated", Role: "Management", SubRole: "Master", NID: "100001",
+			Arch: "ARM", NetType: "Sling", Class: "River"},
+		// Unchanged
+		{ID: "x3000c0s3b0n0", State: "Populated", Role: "Management", SubRole: "Master", NID: "100002",
+			Arch: "X86", NetType: "Sling", Class: "River"},
+		// New
+		{ID: "x3000c0s5b0n0", State: "Populated", Role: "Management", SubRole: "Master", NID: "100003",
+			Arch: "X86", NetType: "Sling", Class: "River"},
+	}
+	results := SyncComponents(want, 10)
+	for _, res := range results {
+		if res.Err != nil {
+			t.Errorf("SyncComponents() error for %s = %v", res.ID, res.Err)
+		}
+	}
+	if len(results[0].Changes) != 1 || results[0].Changes[0].Field != "Arch" || len(results[1].Changes) != 0 {
+		t.Errorf("Changes were %+v and %+v", results[0].Changes, results[1].Changes)
+	}
+
+	if c := fake.Components["x3000c0s1b0n0"]; c.Arch != "ARM" || c.State != "Ready" || c.Flag != "OK" ||
+		c.Enabled == nil || !*c.Enabled {
+		t.Errorf("Changed component was %+v, should keep its State, Flag and Enabled", c)
+	}
+	if c := fake.Components["x3000c0s3b0n0"]; c.State != "On" {
+		t.Errorf("Unchanged component was %+v", c)
+	}
+	if c, ok := fake.Components["x3000c0s5b0n0"]; !ok || c.State != "Populated" {
+		t.Errorf("New component was %+v", c)
+	}
+
+	var posts []HSMCompNotification
+	for _, req := range fake.GetRequests() {
+		if req.Method == http.MethodPost && req.Path == "/State/Components" {
+			posts = append(posts, req.Body.(HSMCompNotification))
+		}
+	}
+	if len(posts) != 2 || posts[0].Force || posts[0].Components[0].ID != "x3000c0s5b0n0" ||
+		!posts[1].Force || posts[1].Components[0].ID != "x3000c0s1b0n0" {
+		t.Errorf("POSTs were %+v, want the new component then the forced update", posts)
+	}
+}
@@ -0,0 +1,125 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base"
+	"gopkg.in/resty.v1"
+)
+
+// HSMError describes a failed call to HSM.  StatusCode is 0 if HSM never
+// answered, in which case Err holds the transport error.
+type HSMError struct {
+	Op         string
+	Xname      string
+	StatusCode int
+	Problem    *base.ProblemDetails
+	Body       string
+	Retryable  bool
+	Err        error
+}
+
+func (e *HSMError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s for %s failed: %v", e.Op, e.Xname, e.Err)
+	}
+	detail := e.Body
+	if e.Problem != nil && e.Problem.Detail != "" {
+		detail = e.Problem.Detail
+	}
+	return fmt.Sprintf("%s for %s failed: HSM returned %d: %s", e.Op, e.Xname, e.StatusCode,
+		strings.TrimSpace(detail))
+}
+
+func (e *HSMError) Unwrap() error {
+	return e.Err
+}
+
+// isRetryableStatus reports whether an HSM response code is worth retrying.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// newHSMError builds an HSMError from the outcome of a resty call.
+func newHSMError(op string, xname string, resp *resty.Response, err error) *HSMError {
+	herr := &HSMError{
+		Op:    op,
+		Xname: xname,
+		Err:   err,
+	}
+	if err != nil || resp == nil {
+		// Never got an answer: network trouble, timeouts and the like.
+		herr.Retryable = true
+		return herr
+	}
+
+	herr.StatusCode = resp.StatusCode()
+	herr.Body = string(resp.Body())
+	herr.Retryable = isRetryableStatus(herr.StatusCode)
+
+	var problem base.ProblemDetails
+	if jerr := json.Unmarshal(resp.Body(), &problem); jerr == nil &&
+		(problem.Title != "" || problem.Detail != "") {
+		herr.Problem = &problem
+	}
+	return herr
+}
+
+// IsRetryable reports whether err is a transient HSM failure worth retrying.
+func IsRetryable(err error) bool {
+	var herr *HSMError
+	if errors.As(err, &herr) {
+		return herr.Retryable
+	}
+	return false
+}
+
+// StatusCode returns the HTTP status HSM answered with, or 0 if err isn't an
+// HSMError or HSM never answered.
+func StatusCode(err error) int {
+	var herr *HSMError
+	if errors.As(err, &herr) {
+		return herr.StatusCode
+	}
+	return 0
+}
+
+// IsConflict reports whether HSM rejected the request because the object
+// already exists.
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
@@ -0,0 +1,119 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base"
+)
+
+func TestHSMError_Classification(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		retryable bool
+		conflict  bool
+		detail    string
+	}{{
+		name:      "Conflict",
+		status:    http.StatusConflict,
+		body:      `{"type":"about:blank","title":"Conflict","detail":"operation would conflict with an existing resource","status":409}`,
+		retryable: false,
+		conflict:  true,
+		detail:    "operation would conflict with an existing resource",
+	}, {
+		name:      "BadRequest",
+		status:    http.StatusBadRequest,
+		body:      `{"type":"about:blank","title":"Bad Request","detail":"invalid xname ID","status":400}`,
+		retryable: false,
+		detail:    "invalid xname ID",
+	}, {
+		name:      "Unavailable",
+		status:    http.StatusServiceUnavailable,
+		body:      "upstream connect error",
+		retryable: true,
+	}, {
+		name:      "InternalError",
+		status:    http.StatusInternalServerError,
+		body:      `{"title":"Internal Server Error","detail":"database error","status":500}`,
+		retryable: true,
+		detail:    "database error",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", base.ProblemDetailContentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+			Init(0, 5, ts.URL, "SmdclientTest")
+
+			err := SetHSMXnameEnabled("x3000c0s1b0", true)
+			if err == nil {
+				t.Fatalf("SetHSMXnameEnabled() returned no error for %d", tt.status)
+			}
+			var herr *HSMError
+			if !errors.As(err, &herr) {
+				t.Fatalf("SetHSMXnameEnabled() returned %T, want *HSMError", err)
+			}
+			if herr.StatusCode != tt.status || StatusCode(err) != tt.status {
+				t.Errorf("StatusCode = %d, want %d", herr.StatusCode, tt.status)
+			}
+			if IsRetryable(err) != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", IsRetryable(err), tt.retryable)
+			}
+			if IsConflict(err) != tt.conflict {
+				t.Errorf("IsConflict() = %v, want %v", IsConflict(err), tt.conflict)
+			}
+			if tt.detail != "" && (herr.Problem == nil || herr.Problem.Detail != tt.detail) {
+				t.Errorf("Problem = %+v, want detail %q", herr.Problem, tt.detail)
+			}
+			if want := fmt.Sprintf("HSM returned %d", tt.status); !strings.Contains(err.Error(), want) {
+				t.Errorf("Error() = %q, should contain %q", err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestHSMError_NoResponse(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+	Init(0, 1, url, "SmdclientTest")
+
+	err := HSMCreateComponent(HSMCompNotification{Components: []base.Component{{ID: "x3000c0s1b0n0"}}})
+	if err == nil {
+		t.Fatalf("HSMCreateComponent() returned no error with HSM down")
+	}
+	if !IsRetryable(err) || StatusCode(err) != 0 {
+		t.Errorf("Network failure should be retryable with no status, got %v", err)
+	}
+}
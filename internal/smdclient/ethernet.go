@@ -0,0 +1,156 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Description given to EthernetInterfaces REDS creates
+const ethernetInterfaceDescription = "BMC MAC registered by REDS"
+
+// NormalizeMAC returns a MAC address the way HSM keys EthernetInterfaces:
+// lower case hex without separators.
+func NormalizeMAC(mac string) (string, error) {
+	id := strings.ToLower(strings.NewReplacer(":", "", "-", "", ".", "").Replace(mac))
+	if len(id) != 12 || strings.Trim(id, "0123456789abcdef") != "" {
+		return "", fmt.Errorf("invalid MAC address %q", mac)
+	}
+	return id, nil
+}
+
+// formatMAC turns a normalized MAC into the colon separated form.
+func formatMAC(id string) string {
+	parts := make([]string, 0, 6)
+	for i := 0; i < len(id); i += 2 {
+		parts = append(parts, id[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+// UpsertEthernetInterface records in HSM that mac belongs to xname, with ip
+// if it's known.  A new MAC gets an EthernetInterface; a MAC HSM already has
+// is updated if its component or address differ.  A MAC HSM has down for a
+// different xname has moved, e.g. because the BMC was swapped into another
+// slot; it's reassigned to xname and the move is logged.  Interfaces REDS
+// registered for xname under other MACs are unassigned, as the BMC has a new
+// MAC.  HSM failures are returned as *HSMError.
+func UpsertEthernetInterface(xname string, mac string, ip string) error {
+	id, err := NormalizeMAC(mac)
+	if err != nil {
+		return err
+	}
+	err = upsertEthernetInterface(xname, id, ip)
+	if err != nil {
+		return err
+	}
+	return releaseEthernetInterfaces(xname, id)
+}
+
+func upsertEthernetInterface(xname string, id string, ip string) error {
+	var ips []EthernetIP
+	if ip != "" {
+		ips = []EthernetIP{{IPAddress: ip}}
+	}
+
+	existing, err := hsmClient.GetEthernetInterface(id)
+	if StatusCode(err) == 404 {
+		log.Printf("INFO: Adding EthernetInterface %s for %s", id, xname)
+		err = hsmClient.CreateEthernetInterface(EthernetInterface{
+			Description: ethernetInterfaceDescription,
+			MACAddress:  formatMAC(id),
+			IPAddresses: ips,
+			ComponentID: xname,
+		})
+		if !IsConflict(err) {
+			return err
+		}
+		// Someone else added it in the meantime; update theirs.
+		existing, err = hsmClient.GetEthernetInterface(id)
+	}
+	if err != nil {
+		return err
+	}
+
+	var patch EthernetInterfacePatch
+	changed := false
+	if existing.ComponentID != xname {
+		if existing.ComponentID != "" {
+			log.Printf("WARNING: MAC %s moved from %s to %s, reassigning its EthernetInterface",
+				id, existing.ComponentID, xname)
+		}
+		patch.ComponentID = &xname
+		// Addresses go with the old component.
+		if ip != "" || len(existing.IPAddresses) > 0 {
+			if ips == nil {
+				ips = []EthernetIP{}
+			}
+			patch.IPAddresses = &ips
+		}
+		changed = true
+	} else if ip != "" && !hasIP(existing.IPAddresses, ip) {
+		ips = append(ips, existing.IPAddresses...)
+		patch.IPAddresses = &ips
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	log.Printf("INFO: Updating EthernetInterface %s for %s", id, xname)
+	return hsmClient.PatchEthernetInterface(id, patch)
+}
+
+// releaseEthernetInterfaces unassigns the interfaces REDS registered for
+// xname under MACs other than keep, left behind when the BMC got a new MAC,
+// e.g. because it was replaced.  Interfaces HSM found itself are left alone;
+// a BMC may have more than one.
+func releaseEthernetInterfaces(xname string, keep string) error {
+	eis, err := hsmClient.GetEthernetInterfaces(xname)
+	if err != nil {
+		return err
+	}
+	for _, ei := range eis {
+		if ei.ID == keep || ei.Description != ethernetInterfaceDescription {
+			continue
+		}
+		log.Printf("WARNING: %s has a new MAC %s, unassigning its old MAC %s", xname, keep, ei.ID)
+		none := ""
+		ips := []EthernetIP{}
+		err = hsmClient.PatchEthernetInterface(ei.ID, EthernetInterfacePatch{ComponentID: &none, IPAddresses: &ips})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasIP(ips []EthernetIP, ip string) bool {
+	for _, eip := range ips {
+		if eip.IPAddress == ip {
+			return true
+		}
+	}
+	return false
+}
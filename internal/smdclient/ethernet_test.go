@@ -0,0 +1,122 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeMAC(t *testing.T) {
+	tests := []struct {
+		mac     string
+		want    string
+		wantErr bool
+	}{
+		{mac: "B4:2E:99:3B:70:28", want: "b42e993b7028"},
+		{mac: "b4-2e-99-3b-70-28", want: "b42e993b7028"},
+		{mac: "b42e.993b.7028", want: "b42e993b7028"},
+		{mac: "b42e993b7028", want: "b42e993b7028"},
+		{mac: "b4:2e:99:3b:70", wantErr: true},
+		{mac: "g4:2e:99:3b:70:28", wantErr: true},
+		{mac: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeMAC(tt.mac)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeMAC(%q) = %q, %v", tt.mac, got, err)
+		}
+	}
+}
+
+func TestUpsertEthernetInterface(t *testing.T) {
+	fake := NewFakeHSM()
+	SetClient(fake)
+	defer SetClient(nil)
+
+	// New MAC
+	if err := UpsertEthernetInterface("x3000c0s7b0", "B4:2E:99:3B:70:28", "10.254.1.14"); err != nil {
+		t.Fatalf("UpsertEthernetInterface() error = %v", err)
+	}
+	ei := fake.EthernetInterfaces["b42e993b7028"]
+	if ei.ComponentID != "x3000c0s7b0" || ei.MACAddress != "b4:2e:99:3b:70:28" ||
+		len(ei.IPAddresses) != 1 || ei.Type != "NodeBMC" {
+		t.Fatalf("EthernetInterface was %+v", ei)
+	}
+
+	// Nothing changed, nothing sent
+	fake.ClearRequests()
+	if err := UpsertEthernetInterface("x3000c0s7b0", "b42e993b7028", "10.254.1.14"); err != nil {
+		t.Fatalf("UpsertEthernetInterface() error = %v", err)
+	}
+	if reqs := fake.GetRequests(); len(reqs) != 2 || reqs[0].Method != http.MethodGet ||
+		reqs[1].Method != http.MethodGet {
+		t.Errorf("Unchanged interface made requests %+v", reqs)
+	}
+
+	// New address for the same component is added
+	if err := UpsertEthernetInterface("x3000c0s7b0", "b42e993b7028", "10.254.1.15"); err != nil {
+		t.Fatalf("UpsertEthernetInterface() error = %v", err)
+	}
+	if ips := fake.EthernetInterfaces["b42e993b7028"].IPAddresses; len(ips) != 2 || ips[0].IPAddress != "10.254.1.15" {
+		t.Errorf("IPAddresses were %+v", ips)
+	}
+
+	// The BMC moved; its old addresses don't follow it
+	fake.ClearRequests()
+	if err := UpsertEthernetInterface("x3000c0s9b0", "b42e993b7028", ""); err != nil {
+		t.Fatalf("UpsertEthernetInterface() error = %v", err)
+	}
+	ei = fake.EthernetInterfaces["b42e993b7028"]
+	if ei.ComponentID != "x3000c0s9b0" || len(ei.IPAddresses) != 0 {
+		t.Errorf("Moved interface was %+v", ei)
+	}
+	reqs := fake.GetRequests()
+	if len(reqs) != 3 || reqs[1].Method != http.MethodPatch {
+		t.Fatalf("Move made requests %+v", reqs)
+	}
+	if patch := reqs[1].Body.(EthernetInterfacePatch); patch.ComponentID == nil || *patch.ComponentID != "x3000c0s9b0" {
+		t.Errorf("Move sent %+v", patch)
+	}
+
+	// The BMC got a new MAC; the old one is unassigned.  Interfaces HSM
+	// found itself are left alone.
+	fake.EthernetInterfaces["b42e993b7029"] = EthernetInterface{ID: "b42e993b7029",
+		MACAddress: "b4:2e:99:3b:70:29", ComponentID: "x3000c0s9b0", Description: "Discovered"}
+	if err := UpsertEthernetInterface("x3000c0s9b0", "b4:2e:99:3b:70:30", "10.254.1.16"); err != nil {
+		t.Fatalf("UpsertEthernetInterface() error = %v", err)
+	}
+	if ei := fake.EthernetInterfaces["b42e993b7028"]; ei.ComponentID != "" || len(ei.IPAddresses) != 0 {
+		t.Errorf("Old interface was %+v", ei)
+	}
+	if ei := fake.EthernetInterfaces["b42e993b7030"]; ei.ComponentID != "x3000c0s9b0" {
+		t.Errorf("New interface was %+v", ei)
+	}
+	if ei := fake.EthernetInterfaces["b42e993b7029"]; ei.ComponentID != "x3000c0s9b0" {
+		t.Errorf("Interface HSM found was %+v", ei)
+	}
+
+	if err := UpsertEthernetInterface("x3000c0s9b0", "not-a-mac", ""); err == nil {
+		t.Errorf("UpsertEthernetInterface() accepted a bad MAC")
+	}
+}
@@ -0,0 +1,411 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	base "github.com/Cray-HPE/hms-base"
+)
+
+// FakeRequest is a request made to a FakeHSM.  Body holds the payload as
+// passed in: an HSMNotification, []HSMNotification, RedfishEndpointPatch,
+// HSMCompNotification, EthernetInterface or EthernetInterfacePatch.
+type FakeRequest struct {
+	Method string
+	Path   string
+	Body   interface{}
+}
+
+// FakeHSM is an in-memory HSM for tests.  It answers the way HSM does: 201
+// for a new endpoint and 409 for an existing one, 204 for components (leaving
+// existing ones alone unless forced) and 404 for anything it doesn't have.
+// Every request is recorded so tests can check exactly what was sent.
+type FakeHSM struct {
+	lock sync.Mutex
+
+	RedfishEndpoints   map[string]RedfishEndpoint
+	Components         map[string]base.Component
+	EthernetInterfaces map[string]EthernetInterface
+	Locks              map[string]LockStatus
+	Requests           []FakeRequest
+
+	// If set, called before each request; a non-zero return is sent back
+	// as the HTTP status instead of handling the request.
+	FailWith func(method string, path string) int
+}
+
+// NewFakeHSM creates an empty FakeHSM.
+func NewFakeHSM() *FakeHSM {
+	return &FakeHSM{
+		RedfishEndpoints:   make(map[string]RedfishEndpoint),
+		Components:         make(map[string]base.Component),
+		EthernetInterfaces: make(map[string]EthernetInterface),
+		Locks:              make(map[string]LockStatus),
+	}
+}
+
+// GetRequests returns a copy of the requests made so far.
+func (f *FakeHSM) GetRequests() []FakeRequest {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]FakeRequest(nil), f.Requests...)
+}
+
+// ClearRequests forgets the requests made so far.
+func (f *FakeHSM) ClearRequests() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.Requests = nil
+}
+
+// record notes a request and returns a forced failure, if any.  op names
+// the operation the way RestClient does.  Must be called with the lock held.
+func (f *FakeHSM) record(op string, method string, path string, xname string, body interface{}) error {
+	f.Requests = append(f.Requests, FakeRequest{Method: method, Path: path, Body: body})
+	if f.FailWith != nil {
+		if status := f.FailWith(method, path); status != 0 {
+			return fakeError(op, xname, status)
+		}
+	}
+	return nil
+}
+
+// fakeError builds the error a RestClient would return for status.
+func fakeError(op string, xname string, status int) *HSMError {
+	problem := base.NewProblemDetailsStatus(fmt.Sprintf("%s %s: %s", op, xname,
+		http.StatusText(status)), status)
+	return &HSMError{
+		Op:         op,
+		Xname:      xname,
+		StatusCode: status,
+		Problem:    problem,
+		Body:       problem.Detail,
+		Retryable:  isRetryableStatus(status),
+	}
+}
+
+func (f *FakeHSM) CreateRedfishEndpoint(ep HSMNotification) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "POST /Inventory/RedfishEndpoints"
+	if err := f.record(op, http.MethodPost, "/Inventory/RedfishEndpoints", ep.ID, ep); err != nil {
+		return err
+	}
+	if status := f.checkNewEndpoint(ep); status != 0 {
+		return fakeError(op, ep.ID, status)
+	}
+	f.addEndpoint(ep)
+	return nil
+}
+
+func (f *FakeHSM) CreateRedfishEndpoints(eps []HSMNotification) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "POST /Inventory/RedfishEndpoints"
+	xname := ""
+	if len(eps) > 0 {
+		xname = eps[0].ID
+	}
+	if err := f.record(op, http.MethodPost, "/Inventory/RedfishEndpoints", xname, eps); err != nil {
+		return err
+	}
+	// All or nothing, like HSM.
+	seen := make(map[string]bool)
+	for _, ep := range eps {
+		if status := f.checkNewEndpoint(ep); status != 0 {
+			return fakeError(op, ep.ID, status)
+		}
+		if seen[ep.ID] {
+			return fakeError(op, ep.ID, http.StatusConflict)
+		}
+		seen[ep.ID] = true
+	}
+	for _, ep := range eps {
+		f.addEndpoint(ep)
+	}
+	return nil
+}
+
+// checkNewEndpoint returns the status HSM gives for adding ep, or 0 if it
+// can be added.  Must be called with the lock held.
+func (f *FakeHSM) checkNewEndpoint(ep HSMNotification) int {
+	if ep.ID == "" {
+		return http.StatusBadRequest
+	}
+	if _, ok := f.RedfishEndpoints[ep.ID]; ok {
+		return http.StatusConflict
+	}
+	return 0
+}
+
+// addEndpoint stores ep.  Must be called with the lock held.
+func (f *FakeHSM) addEndpoint(ep HSMNotification) {
+	enabled := true
+	if ep.Enabled != nil {
+		enabled = *ep.Enabled
+	}
+	f.RedfishEndpoints[ep.ID] = RedfishEndpoint{
+		ID:                 ep.ID,
+		Type:               base.GetHMSTypeString(ep.ID),
+		FQDN:               ep.FQDN,
+		Enabled:            &enabled,
+		User:               ep.User,
+		Password:           ep.Password,
+		MACAddr:            ep.MACAddr,
+		IPAddress:          ep.IPAddress,
+		RediscoverOnUpdate: ep.RediscoverOnUpdate,
+		DiscoveryInfo:      DiscoveryInfo{LastDiscoveryStatus: DiscoveryStatusNotQueried},
+	}
+}
+
+func (f *FakeHSM) PatchRedfishEndpoint(xname string, patch RedfishEndpointPatch) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "PATCH /Inventory/RedfishEndpoints"
+	path := "/Inventory/RedfishEndpoints/" + xname
+	if err := f.record(op, http.MethodPatch, path, xname, patch); err != nil {
+		return err
+	}
+	ep, ok := f.RedfishEndpoints[xname]
+	if !ok {
+		return fakeError(op, xname, http.StatusNotFound)
+	}
+	if patch.FQDN != nil {
+		ep.FQDN = *patch.FQDN
+	}
+	if patch.IPAddress != nil {
+		ep.IPAddress = *patch.IPAddress
+	}
+	if patch.MACAddr != nil {
+		ep.MACAddr = *patch.MACAddr
+	}
+	if patch.User != nil {
+		ep.User = *patch.User
+	}
+	if patch.Password != nil {
+		ep.Password = *patch.Password
+	}
+	if patch.Enabled != nil {
+		enabled := *patch.Enabled
+		ep.Enabled = &enabled
+	}
+	if patch.RediscoverOnUpdate != nil {
+		ep.RediscoverOnUpdate = *patch.RediscoverOnUpdate
+	}
+	if ep.RediscoverOnUpdate && ep.Enabled != nil && *ep.Enabled {
+		ep.DiscoveryInfo.LastDiscoveryStatus = DiscoveryStatusStarted
+	}
+	f.RedfishEndpoints[xname] = ep
+	return nil
+}
+
+// SetDiscoveryStatus sets the LastDiscoveryStatus of an endpoint, as HSM
+// does once it has tried to discover it.
+func (f *FakeHSM) SetDiscoveryStatus(xname string, status string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if ep, ok := f.RedfishEndpoints[xname]; ok {
+		ep.DiscoveryInfo.LastDiscoveryStatus = status
+		f.RedfishEndpoints[xname] = ep
+	}
+}
+
+func (f *FakeHSM) GetRedfishEndpoint(xname string) (*RedfishEndpoint, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "GET /Inventory/RedfishEndpoints"
+	path := "/Inventory/RedfishEndpoints/" + xname
+	if err := f.record(op, http.MethodGet, path, xname, nil); err != nil {
+		return nil, err
+	}
+	ep, ok := f.RedfishEndpoints[xname]
+	if !ok {
+		return nil, fakeError(op, xname, http.StatusNotFound)
+	}
+	return &ep, nil
+}
+
+func (f *FakeHSM) CreateComponents(comps HSMCompNotification) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "POST /State/Components"
+	path := "/State/Components"
+	xname := ""
+	if len(comps.Components) > 0 {
+		xname = comps.Components[0].ID
+	}
+	if err := f.record(op, http.MethodPost, path, xname, comps); err != nil {
+		return err
+	}
+	for _, comp := range comps.Components {
+		if comp.ID == "" {
+			return fakeError(op, comp.ID, http.StatusBadRequest)
+		}
+	}
+	for _, comp := range comps.Components {
+		if _, ok := f.Components[comp.ID]; ok && !comps.Force {
+			continue
+		}
+		if comp.Type == "" {
+			comp.Type = base.GetHMSTypeString(comp.ID)
+		}
+		f.Components[comp.ID] = comp
+	}
+	return nil
+}
+
+func (f *FakeHSM) GetComponent(xname string) (*base.Component, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "GET /State/Components"
+	path := "/State/Components/" + xname
+	if err := f.record(op, http.MethodGet, path, xname, nil); err != nil {
+		return nil, err
+	}
+	comp, ok := f.Components[xname]
+	if !ok {
+		return nil, fakeError(op, xname, http.StatusNotFound)
+	}
+	return &comp, nil
+}
+
+func (f *FakeHSM) CreateEthernetInterface(ei EthernetInterface) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "POST /Inventory/EthernetInterfaces"
+	if err := f.record(op, http.MethodPost, "/Inventory/EthernetInterfaces", ei.ComponentID, ei); err != nil {
+		return err
+	}
+	id, err := NormalizeMAC(ei.MACAddress)
+	if err != nil {
+		return fakeError(op, ei.ComponentID, http.StatusBadRequest)
+	}
+	if _, ok := f.EthernetInterfaces[id]; ok {
+		return fakeError(op, ei.ComponentID, http.StatusConflict)
+	}
+	ei.ID = id
+	if ei.ComponentID != "" {
+		ei.Type = base.GetHMSTypeString(ei.ComponentID)
+	}
+	f.EthernetInterfaces[id] = ei
+	return nil
+}
+
+func (f *FakeHSM) GetEthernetInterface(id string) (*EthernetInterface, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "GET /Inventory/EthernetInterfaces"
+	path := "/Inventory/EthernetInterfaces/" + id
+	if err := f.record(op, http.MethodGet, path, id, nil); err != nil {
+		return nil, err
+	}
+	ei, ok := f.EthernetInterfaces[id]
+	if !ok {
+		return nil, fakeError(op, id, http.StatusNotFound)
+	}
+	return &ei, nil
+}
+
+func (f *FakeHSM) GetEthernetInterfaces(xname string) ([]EthernetInterface, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "GET /Inventory/EthernetInterfaces"
+	path := "/Inventory/EthernetInterfaces?ComponentID=" + xname
+	if err := f.record(op, http.MethodGet, path, xname, nil); err != nil {
+		return nil, err
+	}
+	eis := []EthernetInterface{}
+	for _, ei := range f.EthernetInterfaces {
+		if ei.ComponentID == xname {
+			eis = append(eis, ei)
+		}
+	}
+	sort.Slice(eis, func(i, j int) bool { return eis[i].ID < eis[j].ID })
+	return eis, nil
+}
+
+func (f *FakeHSM) PatchEthernetInterface(id string, patch EthernetInterfacePatch) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "PATCH /Inventory/EthernetInterfaces"
+	path := "/Inventory/EthernetInterfaces/" + id
+	if err := f.record(op, http.MethodPatch, path, id, patch); err != nil {
+		return err
+	}
+	ei, ok := f.EthernetInterfaces[id]
+	if !ok {
+		return fakeError(op, id, http.StatusNotFound)
+	}
+	if patch.Description != nil {
+		ei.Description = *patch.Description
+	}
+	if patch.IPAddresses != nil {
+		ei.IPAddresses = append([]EthernetIP(nil), *patch.IPAddresses...)
+	}
+	if patch.ComponentID != nil {
+		ei.ComponentID = *patch.ComponentID
+		ei.Type = ""
+		if ei.ComponentID != "" {
+			ei.Type = base.GetHMSTypeString(ei.ComponentID)
+		}
+	}
+	f.EthernetInterfaces[id] = ei
+	return nil
+}
+
+func (f *FakeHSM) GetLockStatus(xnames []string) ([]LockStatus, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	op := "POST /locks/status"
+	xname := ""
+	if len(xnames) > 0 {
+		xname = xnames[0]
+	}
+	if err := f.record(op, http.MethodPost, "/locks/status", xname, lockStatusRequest{ComponentIDs: xnames}); err != nil {
+		return nil, err
+	}
+	var locks []LockStatus
+	for _, id := range xnames {
+		_, isComp := f.Components[id]
+		_, isEndpoint := f.RedfishEndpoints[id]
+		lock, isLock := f.Locks[id]
+		if !isComp && !isEndpoint && !isLock {
+			continue
+		}
+		lock.ID = id
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// SetLock sets the lock status of a component, as another service taking
+// or releasing a lock or reservation would.
+func (f *FakeHSM) SetLock(xname string, locked bool, reserved bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.Locks[xname] = LockStatus{ID: xname, Locked: locked, Reserved: reserved}
+}
@@ -0,0 +1,101 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"net/http"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base"
+)
+
+func TestFakeHSM(t *testing.T) {
+	fake := NewFakeHSM()
+	SetClient(fake)
+	defer SetClient(nil)
+
+	notification := HSMNotification{ID: "x3000c0s1b0", FQDN: "x3000c0s1b0", IPAddress: "10.254.1.10"}
+	if err := NotifyHSMDiscoveredWithGeolocation(notification); err != nil {
+		t.Fatalf("NotifyHSMDiscoveredWithGeolocation() error = %v", err)
+	}
+	ep, err := fake.GetRedfishEndpoint("x3000c0s1b0")
+	if err != nil || ep.IPAddress != "10.254.1.10" || !*ep.Enabled || ep.Type != "NodeBMC" {
+		t.Fatalf("GetRedfishEndpoint() = %+v, %v", ep, err)
+	}
+
+	// Disable it, then have it show up again: REDS should re-enable it.
+	if err := SetHSMXnameEnabled("x3000c0s1b0", false); err != nil {
+		t.Fatalf("SetHSMXnameEnabled() error = %v", err)
+	}
+	if *fake.RedfishEndpoints["x3000c0s1b0"].Enabled {
+		t.Errorf("Endpoint should be disabled")
+	}
+	fake.ClearRequests()
+	if err := NotifyHSMDiscoveredWithGeolocation(notification); err != nil {
+		t.Fatalf("NotifyHSMDiscoveredWithGeolocation() of an existing endpoint error = %v", err)
+	}
+	reqs := fake.GetRequests()
+	if len(reqs) != 4 || reqs[0].Method != http.MethodPost || reqs[1].Method != http.MethodGet ||
+		reqs[2].Path != "/locks/status" ||
+		reqs[3].Method != http.MethodPatch || reqs[3].Path != "/Inventory/RedfishEndpoints/x3000c0s1b0" {
+		t.Fatalf("Requests = %+v, want POST, GET, lock check then PATCH", reqs)
+	}
+	if !*fake.RedfishEndpoints["x3000c0s1b0"].Enabled {
+		t.Errorf("Endpoint should have been re-enabled")
+	}
+
+	if err := SetHSMXnameEnabled("x3000c0s2b0", true); StatusCode(err) != http.StatusNotFound {
+		t.Errorf("SetHSMXnameEnabled() of a missing endpoint: error = %v, want 404", err)
+	}
+
+	// Components are only replaced when forced.
+	comp := HSMCompNotification{Components: []base.Component{{ID: "x3000c0s1b0n0", State: "On"}}}
+	if err := HSMCreateComponent(comp); err != nil {
+		t.Fatalf("HSMCreateComponent() error = %v", err)
+	}
+	comp.Components[0].State = "Ready"
+	if err := HSMCreateComponent(comp); err != nil {
+		t.Fatalf("HSMCreateComponent() error = %v", err)
+	}
+	if c, _ := fake.GetComponent("x3000c0s1b0n0"); c.State != "On" {
+		t.Errorf("Unforced create changed State to %s", c.State)
+	}
+	comp.Force = true
+	if err := HSMCreateComponent(comp); err != nil {
+		t.Fatalf("HSMCreateComponent() error = %v", err)
+	}
+	if c, _ := fake.GetComponent("x3000c0s1b0n0"); c.State != "Ready" || c.Type != "Node" {
+		t.Errorf("Forced create gave %+v", c)
+	}
+
+	fake.FailWith = func(method string, path string) int {
+		if method == http.MethodPost {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	}
+	err = HSMCreateComponent(comp)
+	if !IsRetryable(err) || StatusCode(err) != http.StatusServiceUnavailable {
+		t.Errorf("Forced failure: error = %v, want retryable 503", err)
+	}
+}
@@ -0,0 +1,312 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base"
+	"gopkg.in/resty.v1"
+)
+
+// HSMClient covers the Hardware State Manager operations REDS relies on.
+// Every method returns an *HSMError when HSM refuses the request or can't be
+// reached.
+type HSMClient interface {
+	// CreateRedfishEndpoint adds an endpoint.  HSM answers 201, or 409 if
+	// the endpoint already exists.
+	CreateRedfishEndpoint(ep HSMNotification) error
+	// CreateRedfishEndpoints adds several endpoints in one request.  HSM
+	// adds all of them or none; it answers 409 if any already exists.
+	CreateRedfishEndpoints(eps []HSMNotification) error
+	// PatchRedfishEndpoint updates the fields set in patch.  HSM answers
+	// 200, or 404 if there is no such endpoint.
+	PatchRedfishEndpoint(xname string, patch RedfishEndpointPatch) error
+	// GetRedfishEndpoint returns an endpoint, or a 404 error.
+	GetRedfishEndpoint(xname string) (*RedfishEndpoint, error)
+	// CreateComponents adds components under /State/Components.  HSM
+	// answers 204; existing components are left alone unless Force is set.
+	CreateComponents(comps HSMCompNotification) error
+	// GetComponent returns a component, or a 404 error.
+	GetComponent(xname string) (*base.Component, error)
+	// CreateEthernetInterface adds an interface.  HSM answers 201, or 409
+	// if there is already one with that MAC.
+	CreateEthernetInterface(ei EthernetInterface) error
+	// GetEthernetInterface returns the interface with the given ID (the
+	// normalized MAC), or a 404 error.
+	GetEthernetInterface(id string) (*EthernetInterface, error)
+	// GetEthernetInterfaces returns the interfaces HSM has for a component.
+	GetEthernetInterfaces(xname string) ([]EthernetInterface, error)
+	// PatchEthernetInterface updates the fields set in patch.  HSM answers
+	// 200, or 404 if there is no such interface.
+	PatchEthernetInterface(id string, patch EthernetInterfacePatch) error
+	// GetLockStatus returns the lock and reservation status of components.
+	// Components HSM doesn't have are left out.
+	GetLockStatus(xnames []string) ([]LockStatus, error)
+}
+
+// LastDiscoveryStatus values.  DiscoveryStarted and NotYetQueried mean HSM
+// hasn't finished with an endpoint; anything but DiscoverOK is a failure.
+const (
+	DiscoveryStatusOK         = "DiscoverOK"
+	DiscoveryStatusStarted    = "DiscoveryStarted"
+	DiscoveryStatusNotQueried = "NotYetQueried"
+)
+
+// DiscoveryInfo is HSM's record of its last attempt to discover an endpoint.
+type DiscoveryInfo struct {
+	LastDiscoveryAttempt string `json:"LastDiscoveryAttempt,omitempty"`
+	LastDiscoveryStatus  string `json:"LastDiscoveryStatus"`
+	RedfishVersion       string `json:"RedfishVersion,omitempty"`
+}
+
+// RedfishEndpoint is an endpoint as HSM returns it.
+type RedfishEndpoint struct {
+	ID                 string        `json:"ID"`
+	Type               string        `json:"Type,omitempty"`
+	Hostname           string        `json:"Hostname,omitempty"`
+	Domain             string        `json:"Domain,omitempty"`
+	FQDN               string        `json:"FQDN"`
+	Enabled            *bool         `json:"Enabled,omitempty"`
+	User               string        `json:"User"`
+	Password           string        `json:"Password"`
+	MACAddr            string        `json:"MACAddr"`
+	IPAddress          string        `json:"IPAddress"`
+	RediscoverOnUpdate bool          `json:"RediscoverOnUpdate"`
+	DiscoveryInfo      DiscoveryInfo `json:"DiscoveryInfo"`
+}
+
+// RedfishEndpointArray is the body of a bulk endpoint POST.
+type RedfishEndpointArray struct {
+	RedfishEndpoints []HSMNotification `json:"RedfishEndpoints"`
+}
+
+// RedfishEndpointPatch holds the endpoint fields to change; nil fields are
+// left as they are.
+type RedfishEndpointPatch struct {
+	FQDN               *string `json:"FQDN,omitempty"`
+	IPAddress          *string `json:"IPAddress,omitempty"`
+	MACAddr            *string `json:"MACAddr,omitempty"`
+	User               *string `json:"User,omitempty"`
+	Password           *string `json:"Password,omitempty"`
+	Enabled            *bool   `json:"Enabled,omitempty"`
+	RediscoverOnUpdate *bool   `json:"RediscoverOnUpdate,omitempty"`
+}
+
+// EthernetIP is an IP address HSM has for an EthernetInterface.
+type EthernetIP struct {
+	IPAddress string `json:"IPAddress"`
+	Network   string `json:"Network,omitempty"`
+}
+
+// EthernetInterface ties a MAC address to a component in HSM.  Its ID is the
+// MAC address in lower case without separators.
+type EthernetInterface struct {
+	ID          string       `json:"ID,omitempty"`
+	Description string       `json:"Description,omitempty"`
+	MACAddress  string       `json:"MACAddress"`
+	IPAddresses []EthernetIP `json:"IPAddresses,omitempty"`
+	LastUpdate  string       `json:"LastUpdate,omitempty"`
+	ComponentID string       `json:"ComponentID,omitempty"`
+	Type        string       `json:"Type,omitempty"`
+}
+
+// EthernetInterfacePatch holds the interface fields to change; nil fields
+// are left as they are.
+type EthernetInterfacePatch struct {
+	Description *string       `json:"Description,omitempty"`
+	IPAddresses *[]EthernetIP `json:"IPAddresses,omitempty"`
+	ComponentID *string       `json:"ComponentID,omitempty"`
+}
+
+// RestClient talks to a real HSM over HTTP.  HTTPS certificates are
+// verified against the system roots unless SetTLSConfig says otherwise.
+type RestClient struct {
+	lock        sync.RWMutex
+	client      *resty.Client
+	restRetry   int
+	restTimeout int
+	url         string
+	serviceName string
+}
+
+// NewRestClient creates a client for the HSM at hsmURL.
+func NewRestClient(restRetry int, restTimeout int, hsmURL string, svcName string) *RestClient {
+	c := &RestClient{
+		restRetry:   restRetry,
+		restTimeout: restTimeout,
+		url:         hsmURL,
+		serviceName: svcName,
+	}
+	c.SetTLSConfig(&tls.Config{})
+	return c
+}
+
+// SetTLSConfig replaces the TLS settings used for HTTPS.  Requests already
+// under way finish with the old settings.
+func (c *RestClient) SetTLSConfig(tlsConfig *tls.Config) {
+	rClient := resty.New().
+		SetTLSClientConfig(tlsConfig).
+		SetTimeout(time.Duration(time.Duration(c.restTimeout) * time.Second)).
+		SetRetryCount(c.restRetry). // This uses a default backoff algorithm
+		SetRESTMode()               // This enables automatic unmarshalling to JSON and no redirects
+
+	c.lock.Lock()
+	c.client = rClient
+	c.lock.Unlock()
+}
+
+func (c *RestClient) request() *resty.Request {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.client.R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader(base.USERAGENT, c.serviceName)
+}
+
+// expect turns anything but the wanted status into an *HSMError.
+func expect(op string, xname string, resp *resty.Response, err error, status int) error {
+	if err != nil || resp.StatusCode() != status {
+		return newHSMError(op, xname, resp, err)
+	}
+	return nil
+}
+
+func (c *RestClient) CreateRedfishEndpoint(ep HSMNotification) error {
+	resp, err := c.request().
+		SetBody(ep).
+		Post(c.url + "/Inventory/RedfishEndpoints")
+	return expect("POST /Inventory/RedfishEndpoints", ep.ID, resp, err, http.StatusCreated)
+}
+
+func (c *RestClient) CreateRedfishEndpoints(eps []HSMNotification) error {
+	xname := ""
+	if len(eps) > 0 {
+		xname = eps[0].ID
+	}
+	resp, err := c.request().
+		SetBody(RedfishEndpointArray{RedfishEndpoints: eps}).
+		Post(c.url + "/Inventory/RedfishEndpoints")
+	return expect("POST /Inventory/RedfishEndpoints", xname, resp, err, http.StatusCreated)
+}
+
+func (c *RestClient) PatchRedfishEndpoint(xname string, patch RedfishEndpointPatch) error {
+	resp, err := c.request().
+		SetBody(patch).
+		Patch(c.url + "/Inventory/RedfishEndpoints/" + xname)
+	return expect("PATCH /Inventory/RedfishEndpoints", xname, resp, err, http.StatusOK)
+}
+
+func (c *RestClient) GetRedfishEndpoint(xname string) (*RedfishEndpoint, error) {
+	var ep RedfishEndpoint
+	resp, err := c.request().
+		SetResult(&ep).
+		Get(c.url + "/Inventory/RedfishEndpoints/" + xname)
+	err = expect("GET /Inventory/RedfishEndpoints", xname, resp, err, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &ep, nil
+}
+
+func (c *RestClient) CreateComponents(comps HSMCompNotification) error {
+	xname := ""
+	if len(comps.Components) > 0 {
+		xname = comps.Components[0].ID
+	}
+	resp, err := c.request().
+		SetBody(comps).
+		Post(c.url + "/State/Components")
+	return expect("POST /State/Components", xname, resp, err, http.StatusNoContent)
+}
+
+func (c *RestClient) GetComponent(xname string) (*base.Component, error) {
+	var comp base.Component
+	resp, err := c.request().
+		SetResult(&comp).
+		Get(c.url + "/State/Components/" + xname)
+	err = expect("GET /State/Components", xname, resp, err, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &comp, nil
+}
+
+func (c *RestClient) CreateEthernetInterface(ei EthernetInterface) error {
+	resp, err := c.request().
+		SetBody(ei).
+		Post(c.url + "/Inventory/EthernetInterfaces")
+	return expect("POST /Inventory/EthernetInterfaces", ei.ComponentID, resp, err, http.StatusCreated)
+}
+
+func (c *RestClient) GetEthernetInterface(id string) (*EthernetInterface, error) {
+	var ei EthernetInterface
+	resp, err := c.request().
+		SetResult(&ei).
+		Get(c.url + "/Inventory/EthernetInterfaces/" + id)
+	err = expect("GET /Inventory/EthernetInterfaces", id, resp, err, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &ei, nil
+}
+
+func (c *RestClient) GetEthernetInterfaces(xname string) ([]EthernetInterface, error) {
+	var eis []EthernetInterface
+	resp, err := c.request().
+		SetQueryParam("ComponentID", xname).
+		SetResult(&eis).
+		Get(c.url + "/Inventory/EthernetInterfaces")
+	err = expect("GET /Inventory/EthernetInterfaces", xname, resp, err, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return eis, nil
+}
+
+func (c *RestClient) PatchEthernetInterface(id string, patch EthernetInterfacePatch) error {
+	resp, err := c.request().
+		SetBody(patch).
+		Patch(c.url + "/Inventory/EthernetInterfaces/" + id)
+	return expect("PATCH /Inventory/EthernetInterfaces", id, resp, err, http.StatusOK)
+}
+
+func (c *RestClient) GetLockStatus(xnames []string) ([]LockStatus, error) {
+	var result lockStatusResponse
+	xname := ""
+	if len(xnames) > 0 {
+		xname = xnames[0]
+	}
+	resp, err := c.request().
+		SetBody(lockStatusRequest{ComponentIDs: xnames}).
+		SetResult(&result).
+		Post(c.url + "/locks/status")
+	err = expect("POST /locks/status", xname, resp, err, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return result.Components, nil
+}
@@ -0,0 +1,131 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base"
+)
+
+func TestRestClient(t *testing.T) {
+	type request struct {
+		method string
+		path   string
+		body   map[string]interface{}
+	}
+	var got []request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.Path}
+		data, _ := ioutil.ReadAll(r.Body)
+		if len(data) > 0 {
+			json.Unmarshal(data, &req.body)
+		}
+		got = append(got, req)
+		if r.Header.Get(base.USERAGENT) != "SmdclientTest" {
+			t.Errorf("%s %s: User-Agent = %q", r.Method, r.URL.Path, r.Header.Get(base.USERAGENT))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /Inventory/RedfishEndpoints":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`[{"URI":"/hsm/v1/Inventory/RedfishEndpoints/x3000c0s1b0"}]`))
+		case "PATCH /Inventory/RedfishEndpoints/x3000c0s1b0":
+			w.Write(data)
+		case "GET /Inventory/RedfishEndpoints/x3000c0s1b0":
+			w.Write([]byte(`{"ID":"x3000c0s1b0","Type":"NodeBMC","FQDN":"x3000c0s1b0",` +
+				`"Enabled":true,"User":"root","Password":"","MACAddr":"a4bf0138ee65",` +
+				`"IPAddress":"10.254.1.10","RediscoverOnUpdate":true,` +
+				`"DiscoveryInfo":{"LastDiscoveryStatus":"DiscoverOK"}}`))
+		case "POST /State/Components":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /locks/status":
+			w.Write([]byte(`{"Components":[{"ID":"x3000c0s1b0","Locked":true,"Reserved":false,` +
+				`"ReservationDisabled":false}],"NotFound":["x3000c0s2b0"]}`))
+		case "GET /State/Components/x3000c0s1b0n0":
+			w.Write([]byte(`{"ID":"x3000c0s1b0n0","Type":"Node","State":"Ready","Role":"Management"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	c := NewRestClient(0, 5, ts.URL, "SmdclientTest")
+
+	enabled := true
+	if err := c.CreateRedfishEndpoint(HSMNotification{ID: "x3000c0s1b0", FQDN: "x3000c0s1b0",
+		RediscoverOnUpdate: true, Enabled: &enabled}); err != nil {
+		t.Errorf("CreateRedfishEndpoint() error = %v", err)
+	}
+
+	ip := "10.254.1.11"
+	if err := c.PatchRedfishEndpoint("x3000c0s1b0", RedfishEndpointPatch{IPAddress: &ip}); err != nil {
+		t.Errorf("PatchRedfishEndpoint() error = %v", err)
+	}
+
+	ep, err := c.GetRedfishEndpoint("x3000c0s1b0")
+	if err != nil {
+		t.Errorf("GetRedfishEndpoint() error = %v", err)
+	} else if ep.MACAddr != "a4bf0138ee65" || ep.DiscoveryInfo.LastDiscoveryStatus != "DiscoverOK" ||
+		ep.Enabled == nil || !*ep.Enabled {
+		t.Errorf("GetRedfishEndpoint() = %+v", ep)
+	}
+
+	if err := c.CreateComponents(HSMCompNotification{
+		Components: []base.Component{{ID: "x3000c0s1b0n0", State: "On"}}}); err != nil {
+		t.Errorf("CreateComponents() error = %v", err)
+	}
+
+	comp, err := c.GetComponent("x3000c0s1b0n0")
+	if err != nil {
+		t.Errorf("GetComponent() error = %v", err)
+	} else if comp.Role != "Management" || comp.State != "Ready" {
+		t.Errorf("GetComponent() = %+v", comp)
+	}
+
+	locks, err := c.GetLockStatus([]string{"x3000c0s1b0", "x3000c0s2b0"})
+	if err != nil {
+		t.Errorf("GetLockStatus() error = %v", err)
+	} else if len(locks) != 1 || locks[0].ID != "x3000c0s1b0" || !locks[0].Locked {
+		t.Errorf("GetLockStatus() = %+v", locks)
+	}
+
+	_, err = c.GetRedfishEndpoint("x3000c0s2b0")
+	if StatusCode(err) != http.StatusNotFound {
+		t.Errorf("GetRedfishEndpoint() of a missing endpoint: error = %v, want 404", err)
+	}
+
+	if len(got) != 7 {
+		t.Fatalf("Made %d requests, want 7: %+v", len(got), got)
+	}
+	if got[1].method != http.MethodPatch || len(got[1].body) != 1 || got[1].body["IPAddress"] != ip {
+		t.Errorf("PATCH should only carry IPAddress, got %+v", got[1].body)
+	}
+	if ids, ok := got[5].body["ComponentIDs"].([]interface{}); !ok || len(ids) != 2 {
+		t.Errorf("Lock status request was %+v", got[5].body)
+	}
+}
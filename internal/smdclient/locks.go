@@ -0,0 +1,97 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// LockStatus is whether another service holds an HSM lock or reservation
+// on a component, as returned by /locks/status.
+type LockStatus struct {
+	ID                  string `json:"ID"`
+	Locked              bool   `json:"Locked"`
+	Reserved            bool   `json:"Reserved"`
+	ReservationDisabled bool   `json:"ReservationDisabled"`
+	ExpirationTime      string `json:"ExpirationTime,omitempty"`
+}
+
+// Body and answer of a POST to /locks/status
+type lockStatusRequest struct {
+	ComponentIDs []string `json:"ComponentIDs"`
+}
+
+type lockStatusResponse struct {
+	Components []LockStatus `json:"Components"`
+	NotFound   []string     `json:"NotFound"`
+}
+
+// LockedError is returned instead of changing a component another service
+// holds an HSM lock or reservation on, e.g. during a firmware update.
+type LockedError struct {
+	Xname    string
+	Locked   bool
+	Reserved bool
+}
+
+func (e *LockedError) Error() string {
+	var held string
+	switch {
+	case e.Locked && e.Reserved:
+		held = "locked and reserved"
+	case e.Locked:
+		held = "locked"
+	default:
+		held = "reserved"
+	}
+	return fmt.Sprintf("%s is %s in HSM by another service", e.Xname, held)
+}
+
+// IsLocked reports whether err means a change was skipped because the
+// component is locked or reserved in HSM.  The change should be tried again
+// later.
+func IsLocked(err error) bool {
+	var lerr *LockedError
+	return errors.As(err, &lerr)
+}
+
+// checkUnlocked returns a *LockedError if another service holds a lock or
+// reservation on xname, or an *HSMError if the locks can't be checked.
+// Components HSM doesn't have can't be locked.
+func checkUnlocked(xname string) error {
+	locks, err := hsmClient.GetLockStatus([]string{xname})
+	if err != nil {
+		log.Printf("WARNING: Unable to check HSM locks on %s: %s", xname, err)
+		return err
+	}
+	for _, lock := range locks {
+		if lock.ID == xname && (lock.Locked || lock.Reserved) {
+			lerr := &LockedError{Xname: xname, Locked: lock.Locked, Reserved: lock.Reserved}
+			log.Printf("WARNING: Not changing %s: %s", xname, lerr)
+			return lerr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLocks(t *testing.T) {
+	fake := NewFakeHSM()
+	fake.addEndpoint(HSMNotification{ID: "x3000c0s1b0", IPAddress: "10.254.1.10"})
+	fake.SetLock("x3000c0s1b0", false, true)
+	SetClient(fake)
+	defer SetClient(nil)
+
+	err := SetHSMXnameEnabled("x3000c0s1b0", false)
+	if !IsLocked(err) || IsRetryable(err) || err.Error() != "x3000c0s1b0 is reserved in HSM by another service" {
+		t.Errorf("SetHSMXnameEnabled() of a reserved endpoint error = %v", err)
+	}
+	if err := RediscoverRedfishEndpoint("x3000c0s1b0", "", ""); !IsLocked(err) {
+		t.Errorf("RediscoverRedfishEndpoint() of a reserved endpoint error = %v", err)
+	}
+	_, err = UpdateRedfishEndpoint(HSMNotification{ID: "x3000c0s1b0", IPAddress: "10.254.1.11"})
+	if !IsLocked(err) {
+		t.Errorf("UpdateRedfishEndpoint() of a reserved endpoint error = %v", err)
+	}
+	for _, req := range fake.GetRequests() {
+		if req.Method == http.MethodPatch {
+			t.Errorf("Reserved endpoint was patched: %+v", req)
+		}
+	}
+
+	fake.SetLock("x3000c0s1b0", false, false)
+	if err := SetHSMXnameEnabled("x3000c0s1b0", false); err != nil {
+		t.Errorf("SetHSMXnameEnabled() once released error = %v", err)
+	}
+	if *fake.RedfishEndpoints["x3000c0s1b0"].Enabled {
+		t.Errorf("Endpoint should be disabled")
+	}
+
+	// Components HSM doesn't have can't be locked, but a failed check stops
+	// the change.
+	fake.FailWith = func(method string, path string) int {
+		if path == "/locks/status" {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	}
+	if err := SetHSMXnameEnabled("x3000c0s1b0", true); !IsRetryable(err) || IsLocked(err) {
+		t.Errorf("SetHSMXnameEnabled() with HSM locks unavailable error = %v", err)
+	}
+}
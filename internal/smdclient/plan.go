@@ -0,0 +1,271 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"net/http"
+	"sync"
+
+	base "github.com/Cray-HPE/hms-base"
+	"github.com/Cray-HPE/hms-reds/internal/plan"
+)
+
+// PlanningClient is an HSMClient for dry-run mode.  Reads go to the real
+// HSM; writes are recorded in a plan and applied to an in-memory overlay
+// instead, so later reads see what HSM would hold had they been made.
+// Endpoints created or rediscovered in the plan read back as DiscoverOK,
+// since HSM never gets to discover them.
+type PlanningClient struct {
+	real    HSMClient
+	rec     *plan.Recorder
+	lock    sync.Mutex
+	overlay *FakeHSM
+}
+
+// NewPlanningClient wraps real so writes are recorded to rec.
+func NewPlanningClient(real HSMClient, rec *plan.Recorder) *PlanningClient {
+	return &PlanningClient{
+		real:    real,
+		rec:     rec,
+		overlay: NewFakeHSM(),
+	}
+}
+
+// seedEndpoint copies an endpoint from the real HSM into the overlay, unless
+// the overlay already has it.  Must be called with the lock held.
+func (p *PlanningClient) seedEndpoint(xname string) error {
+	p.overlay.lock.Lock()
+	_, ok := p.overlay.RedfishEndpoints[xname]
+	p.overlay.lock.Unlock()
+	if ok || xname == "" {
+		return nil
+	}
+	ep, err := p.real.GetRedfishEndpoint(xname)
+	if err != nil {
+		if StatusCode(err) == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	p.overlay.lock.Lock()
+	p.overlay.RedfishEndpoints[xname] = *ep
+	p.overlay.lock.Unlock()
+	return nil
+}
+
+// seedComponent copies a component from the real HSM into the overlay.
+// Must be called with the lock held.
+func (p *PlanningClient) seedComponent(xname string) error {
+	p.overlay.lock.Lock()
+	_, ok := p.overlay.Components[xname]
+	p.overlay.lock.Unlock()
+	if ok || xname == "" {
+		return nil
+	}
+	comp, err := p.real.GetComponent(xname)
+	if err != nil {
+		if StatusCode(err) == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	p.overlay.lock.Lock()
+	p.overlay.Components[xname] = *comp
+	p.overlay.lock.Unlock()
+	return nil
+}
+
+// seedEthernetInterface copies an interface from the real HSM into the
+// overlay.  Must be called with the lock held.
+func (p *PlanningClient) seedEthernetInterface(id string) error {
+	p.overlay.lock.Lock()
+	_, ok := p.overlay.EthernetInterfaces[id]
+	p.overlay.lock.Unlock()
+	if ok || id == "" {
+		return nil
+	}
+	ei, err := p.real.GetEthernetInterface(id)
+	if err != nil {
+		if StatusCode(err) == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	p.overlay.lock.Lock()
+	p.overlay.EthernetInterfaces[id] = *ei
+	p.overlay.lock.Unlock()
+	return nil
+}
+
+// markDiscovered makes planned endpoints look discovered.  Must be called
+// with the lock held.
+func (p *PlanningClient) markDiscovered(xnames ...string) {
+	for _, xname := range xnames {
+		p.overlay.SetDiscoveryStatus(xname, DiscoveryStatusOK)
+	}
+	p.overlay.ClearRequests()
+}
+
+func (p *PlanningClient) CreateRedfishEndpoint(ep HSMNotification) error {
+	return p.CreateRedfishEndpoints([]HSMNotification{ep})
+}
+
+func (p *PlanningClient) CreateRedfishEndpoints(eps []HSMNotification) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var xnames []string
+	for _, ep := range eps {
+		if err := p.seedEndpoint(ep.ID); err != nil {
+			return err
+		}
+		xnames = append(xnames, ep.ID)
+	}
+	if err := p.overlay.CreateRedfishEndpoints(eps); err != nil {
+		p.overlay.ClearRequests()
+		return err
+	}
+	p.rec.Record(plan.SERVICE_HSM, "POST /Inventory/RedfishEndpoints", xnames, eps)
+	p.markDiscovered(xnames...)
+	return nil
+}
+
+func (p *PlanningClient) PatchRedfishEndpoint(xname string, patch RedfishEndpointPatch) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err := p.seedEndpoint(xname); err != nil {
+		return err
+	}
+	if err := p.overlay.PatchRedfishEndpoint(xname, patch); err != nil {
+		p.overlay.ClearRequests()
+		return err
+	}
+	p.rec.Record(plan.SERVICE_HSM, "PATCH /Inventory/RedfishEndpoints/"+xname,
+		[]string{xname}, patch)
+	p.markDiscovered(xname)
+	return nil
+}
+
+func (p *PlanningClient) GetRedfishEndpoint(xname string) (*RedfishEndpoint, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err := p.seedEndpoint(xname); err != nil {
+		return nil, err
+	}
+	defer p.overlay.ClearRequests()
+	return p.overlay.GetRedfishEndpoint(xname)
+}
+
+func (p *PlanningClient) CreateComponents(comps HSMCompNotification) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var xnames []string
+	for _, comp := range comps.Components {
+		if err := p.seedComponent(comp.ID); err != nil {
+			return err
+		}
+		xnames = append(xnames, comp.ID)
+	}
+	defer p.overlay.ClearRequests()
+	if err := p.overlay.CreateComponents(comps); err != nil {
+		return err
+	}
+	p.rec.Record(plan.SERVICE_HSM, "POST /State/Components", xnames, comps)
+	return nil
+}
+
+func (p *PlanningClient) GetComponent(xname string) (*base.Component, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err := p.seedComponent(xname); err != nil {
+		return nil, err
+	}
+	defer p.overlay.ClearRequests()
+	return p.overlay.GetComponent(xname)
+}
+
+func (p *PlanningClient) CreateEthernetInterface(ei EthernetInterface) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if id, err := NormalizeMAC(ei.MACAddress); err == nil {
+		if err := p.seedEthernetInterface(id); err != nil {
+			return err
+		}
+	}
+	defer p.overlay.ClearRequests()
+	if err := p.overlay.CreateEthernetInterface(ei); err != nil {
+		return err
+	}
+	p.rec.Record(plan.SERVICE_HSM, "POST /Inventory/EthernetInterfaces",
+		[]string{ei.ComponentID}, ei)
+	return nil
+}
+
+func (p *PlanningClient) GetEthernetInterface(id string) (*EthernetInterface, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err := p.seedEthernetInterface(id); err != nil {
+		return nil, err
+	}
+	defer p.overlay.ClearRequests()
+	return p.overlay.GetEthernetInterface(id)
+}
+
+func (p *PlanningClient) GetEthernetInterfaces(xname string) ([]EthernetInterface, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	eis, err := p.real.GetEthernetInterfaces(xname)
+	if err != nil {
+		return nil, err
+	}
+	for _, ei := range eis {
+		if err := p.seedEthernetInterface(ei.ID); err != nil {
+			return nil, err
+		}
+	}
+	defer p.overlay.ClearRequests()
+	return p.overlay.GetEthernetInterfaces(xname)
+}
+
+func (p *PlanningClient) PatchEthernetInterface(id string, patch EthernetInterfacePatch) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err := p.seedEthernetInterface(id); err != nil {
+		return err
+	}
+	defer p.overlay.ClearRequests()
+	if err := p.overlay.PatchEthernetInterface(id, patch); err != nil {
+		return err
+	}
+	var xnames []string
+	if patch.ComponentID != nil && *patch.ComponentID != "" {
+		xnames = []string{*patch.ComponentID}
+	} else if ei, err := p.overlay.GetEthernetInterface(id); err == nil && ei.ComponentID != "" {
+		xnames = []string{ei.ComponentID}
+	}
+	p.rec.Record(plan.SERVICE_HSM, "PATCH /Inventory/EthernetInterfaces/"+id, xnames, patch)
+	return nil
+}
+
+func (p *PlanningClient) GetLockStatus(xnames []string) ([]LockStatus, error) {
+	return p.real.GetLockStatus(xnames)
+}
@@ -0,0 +1,100 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"net/http"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base"
+	"github.com/Cray-HPE/hms-reds/internal/plan"
+)
+
+func TestPlanningClient(t *testing.T) {
+	fake := NewFakeHSM()
+	fake.addEndpoint(HSMNotification{ID: "x3000c0s2b0", IPAddress: "10.254.1.12", User: "root", Password: "old"})
+	rec := plan.NewRecorder(0)
+	SetClient(NewPlanningClient(fake, rec))
+	defer SetClient(nil)
+
+	// A new endpoint is planned, and reads back as discovered.
+	if err := NotifyHSMDiscoveredWithGeolocation(HSMNotification{ID: "x3000c0s1b0", IPAddress: "10.254.1.10"}); err != nil {
+		t.Fatalf("NotifyHSMDiscoveredWithGeolocation() error = %v", err)
+	}
+	ep, err := GetRedfishEndpoint("x3000c0s1b0")
+	if err != nil || ep.DiscoveryInfo.LastDiscoveryStatus != DiscoveryStatusOK {
+		t.Errorf("GetRedfishEndpoint() of a planned endpoint = %+v, %v", ep, err)
+	}
+
+	// An existing one gets a planned PATCH of what changed.
+	if err := NotifyHSMDiscoveredWithGeolocation(HSMNotification{ID: "x3000c0s2b0", IPAddress: "10.254.1.22", User: "root", Password: "old"}); err != nil {
+		t.Fatalf("NotifyHSMDiscoveredWithGeolocation() of an existing endpoint error = %v", err)
+	}
+	if err := SetHSMXnameEnabled("x3000c0s2b0", false); err != nil {
+		t.Fatalf("SetHSMXnameEnabled() error = %v", err)
+	}
+	comp := base.Component{ID: "x3000c0s1b0n0", Role: "Management"}
+	if err := HSMCreateComponent(HSMCompNotification{Components: []base.Component{comp}}); err != nil {
+		t.Fatalf("HSMCreateComponent() error = %v", err)
+	}
+
+	// Nothing reached HSM but reads and lock checks.
+	for _, req := range fake.GetRequests() {
+		if req.Method != http.MethodGet && req.Path != "/locks/status" {
+			t.Errorf("%s %s was sent to HSM", req.Method, req.Path)
+		}
+	}
+	if len(fake.RedfishEndpoints) != 1 || fake.RedfishEndpoints["x3000c0s2b0"].IPAddress != "10.254.1.12" ||
+		len(fake.Components) != 0 {
+		t.Errorf("HSM was changed: %+v, %+v", fake.RedfishEndpoints, fake.Components)
+	}
+
+	entries, _ := rec.Entries()
+	want := []string{
+		"POST /Inventory/RedfishEndpoints",
+		"PATCH /Inventory/RedfishEndpoints/x3000c0s2b0",
+		"PATCH /Inventory/RedfishEndpoints/x3000c0s2b0",
+		"POST /State/Components",
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Entries() = %+v, want %v", entries, want)
+	}
+	for i, entry := range entries {
+		if entry.Operation != want[i] || entry.Service != plan.SERVICE_HSM {
+			t.Errorf("Entry %d = %+v, want %s", i, entry, want[i])
+		}
+	}
+	payload := entries[0].Payload.([]interface{})[0].(map[string]interface{})
+	if payload["ID"] != "x3000c0s1b0" {
+		t.Errorf("Payload = %v", payload)
+	}
+
+	// A failure in HSM isn't planned.
+	fake.FailWith = func(method, path string) int { return http.StatusServiceUnavailable }
+	if err := NotifyHSMDiscoveredWithGeolocation(HSMNotification{ID: "x3000c0s3b0"}); !IsRetryable(err) {
+		t.Errorf("NotifyHSMDiscoveredWithGeolocation() error = %v, want a retryable error", err)
+	}
+	if entries, _ := rec.Entries(); len(entries) != len(want) {
+		t.Errorf("Entries() after a failure = %+v", entries)
+	}
+}
@@ -23,18 +23,12 @@
 package smdclient
 
 import (
-	"crypto/tls"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
-	"net/http"
-	"time"
 
 	base "github.com/Cray-HPE/hms-base"
 	compcreds "github.com/Cray-HPE/hms-compcredentials"
-	sstorage "github.com/Cray-HPE/hms-securestorage"
-	"gopkg.in/resty.v1"
+	"github.com/Cray-HPE/hms-reds/internal/securestore"
 )
 
 // HSMNotification is used to send newly discovered devices to HSM
@@ -58,15 +52,9 @@ type HSMCompNotification struct {
 	Force      bool             `json:"Force,omitempty"`
 }
 
-// Have we initialized this module?
-var doneInit = false
-
-// A custom client for ReST calls to use instead of resty.DefaultClient
-//   This will be created in the init() method
-var rClient *resty.Client
-
-// The URL to use to talk to HSM
-var hsm string
+// The HSM client used by the functions below.  Init sets up a RestClient;
+//   tests can substitute a FakeHSM with SetClient.
+var hsmClient HSMClient
 
 // The HSM Credentials store
 var hcs *compcreds.CompCredStore
@@ -100,96 +88,89 @@ func Init(restRetry int, restTimeout int, hsmURL string, svcName string) error {
 	// Setup connection to HSM Vault
 	log.Printf("Connecting to HSM secure store (Vault)...")
 	// Start a connection to Vault
-	if ss, err := sstorage.NewVaultAdapter(""); err != nil {
+	if ss, err := securestore.Connect(); err != nil {
 		log.Printf("Error: HSM Secure Store connection failed - %s", err)
 	} else {
 		log.Printf("Connection to HSM secure store (Vault) succeeded")
-		hcs = compcreds.NewCompCredStore("secret/hms-creds", ss)
+		hcs = compcreds.NewCompCredStore(securestore.GetConfig().HMSCredsPath(), ss)
 	}
 
-	rClient = resty.New().
-		SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true}).
-		SetTimeout(time.Duration(time.Duration(restTimeout) * time.Second)).
-		SetRetryCount(restRetry). // This uses a default backoff algorithm
-		SetRESTMode()             // This enables automatic unmarshalling to JSON and no redirects
-
-	hsm = hsmURL
+	hsmClient = NewRestClient(restRetry, restTimeout, hsmURL, svcName)
 
 	return nil
 }
 
+// SetClient replaces the HSM client, e.g. with a FakeHSM.
+func SetClient(client HSMClient) {
+	hsmClient = client
+}
+
+// GetClient returns the HSM client in use.
+func GetClient() HSMClient {
+	return hsmClient
+}
+
 // NotifyHSMDiscoveredWithGeolocation performs the task of adding discovered items
-// to HSM once they've been geolocated and put in an HSMNotification struct
-func NotifyHSMDiscoveredWithGeolocation(payload HSMNotification) bool {
+// to HSM once they've been geolocated and put in an HSMNotification struct.
+// If HSM already has the endpoint it is updated instead; see
+// UpdateRedfishEndpoint.  Failures are returned as *HSMError.
+func NotifyHSMDiscoveredWithGeolocation(payload HSMNotification) error {
+	_, err := registerEndpoint(payload)
+	return err
+}
+
+// registerEndpoint adds payload to HSM, or updates the endpoint HSM already
+// has, in which case it also returns what was updated.
+func registerEndpoint(payload HSMNotification) (*EndpointUpdate, error) {
 	log.Printf("INFO: Notifying HSM we discovered %s:\n\t"+
 		"BMC IP %s\n\tBMC MAC: %s\n\tBMC Username: %s\n\tBMC Password: ***",
 		payload.ID, payload.IPAddress, payload.MACAddr, payload.User)
-	_, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("WARNING: Could not encode JSON for %s: %v (%s)", payload.ID,
-			err, payload.String())
-	}
 
-	log.Printf("DEBUG: POST to %s with %s", hsm+"/Inventory/RedfishEndpoints",
-		payload.String())
+	log.Printf("DEBUG: POST to /Inventory/RedfishEndpoints with %s", payload.String())
 
-	resp, err := rClient.
-		R().
-		SetBody(payload).
-		SetHeader(base.USERAGENT, serviceName).
-		Post(hsm + "/Inventory/RedfishEndpoints")
-	if err != nil {
-		log.Printf("WARNING: Unable to send information for %s: %v", payload.ID, err)
-		log.Printf("WARNING: Errors occured and %s was not added to HSM.", payload.ID)
-		return false
-	}
-
-	if resp.StatusCode() == http.StatusCreated {
+	err := hsmClient.CreateRedfishEndpoint(payload)
+	if err == nil {
 		log.Printf("INFO: Successfully added %s to HSM", payload.ID)
-		return true
-	} else if resp.StatusCode() == http.StatusConflict {
-		log.Printf("INFO: %s alredy present; patching instead", payload.ID)
-		enableResult, _ := SetHSMXnameEnabled(payload.ID, true)
-		return enableResult
-	} else {
-		log.Printf("WARNING: An error occurred uploading %s: %s %v", payload.ID,
-			resp.Status(), resp)
-		log.Printf("WARNING: Errors occured and %s was not added to HSM.", payload.ID)
-		return false
+		return nil, nil
+	} else if IsConflict(err) {
+		log.Printf("INFO: %s alredy present; comparing with what HSM has", payload.ID)
+		return UpdateRedfishEndpoint(payload)
 	}
-	// TODO put error logic in switch stmt
+	log.Printf("WARNING: An error occurred uploading %s: %s", payload.ID, err)
+	log.Printf("WARNING: Errors occured and %s was not added to HSM.", payload.ID)
+	return nil, err
 }
 
-func SetHSMXnameEnabled(xname string, enabled bool) (bool, error) {
-	payload := HSMNotification{
-		ID:      xname,
+// GetRedfishEndpoint returns the endpoint HSM has for xname.  Failures,
+// including HSM not having it (404), are returned as *HSMError.
+func GetRedfishEndpoint(xname string) (*RedfishEndpoint, error) {
+	return hsmClient.GetRedfishEndpoint(xname)
+}
+
+// SetHSMXnameEnabled enables or disables a RedfishEndpoint in HSM.  If
+// another service has the endpoint locked or reserved it is left alone and
+// a *LockedError is returned.  Other failures are returned as *HSMError.
+func SetHSMXnameEnabled(xname string, enabled bool) error {
+	if err := checkUnlocked(xname); err != nil {
+		return err
+	}
+
+	patch := RedfishEndpointPatch{
 		Enabled: &enabled,
 		// Match the 'enabled' bool so HSM will rediscover only when
 		// we are setting the redfishEndpoint to 'Enabled'.
-		RediscoverOnUpdate: enabled,
+		RediscoverOnUpdate: &enabled,
 	}
 
-	log.Printf("DEBUG: PATCH to %s/Inventory/RedfishEndpoints/%s", hsm, xname)
+	log.Printf("DEBUG: PATCH to /Inventory/RedfishEndpoints/%s", xname)
 
-	req := rClient.R()
-	req.SetHeader("Content-Type", "application/json")
-	req.SetHeader(base.USERAGENT, serviceName)
-	req.SetBody(payload)
-	resp, err := req.Patch(hsm + "/Inventory/RedfishEndpoints/" + xname)
+	err := hsmClient.PatchRedfishEndpoint(xname, patch)
 	if err != nil {
-		log.Printf("WARNING: Unable to patch %s: %v", xname, err)
-		return false, err
+		log.Printf("WARNING: An error occurred patching %s: %s", xname, err)
+		return err
 	}
-
-	if resp.StatusCode() == http.StatusOK {
-		log.Printf("INFO: Successfully patched %s", xname)
-	} else {
-		strbody := string(resp.Body())
-		log.Printf("WARNING: An error occurred patching %s: %s %v", xname, resp.Status(), string(strbody))
-		rerr := errors.New("Unable to patch information for " + xname + " to HSM: " + string(resp.StatusCode()) + "\n" + string(strbody))
-		return false, rerr
-	}
-	return true, nil
+	log.Printf("INFO: Successfully patched %s", xname)
+	return nil
 }
 
 // HSMCreateComponent performs the task of adding a discovered component
@@ -197,36 +178,18 @@ func SetHSMXnameEnabled(xname string, enabled bool) (bool, error) {
 //   process. This is typically to add a Master node that is not being added
 //   to the management network. This will never fail on conflict. Instead HSM
 //   will skip changes to already existing components unless we set Force=true
-//   which we're not.
-func HSMCreateComponent(payload HSMCompNotification) bool {
+//   which we're not.  Failures are returned as *HSMError.
+func HSMCreateComponent(payload HSMCompNotification) error {
 	log.Printf("INFO: Creating a component in HSM, %s.", payload.Components[0].ID)
-	_, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("WARNING: Could not encode JSON for %s: %v (%v)", payload.Components[0].ID,
-			err, payload)
-	}
 
-	log.Printf("DEBUG: POST to %s with %v", hsm+"/State/Components",
-		payload)
+	log.Printf("DEBUG: POST to /State/Components with %v", payload)
 
-	resp, err := rClient.
-		R().
-		SetBody(payload).
-		SetHeader(base.USERAGENT, serviceName).
-		Post(hsm + "/State/Components")
+	err := hsmClient.CreateComponents(payload)
 	if err != nil {
-		log.Printf("WARNING: Unable to send information for %s: %v", payload.Components[0].ID, err)
+		log.Printf("WARNING: An error occurred uploading %s: %s", payload.Components[0].ID, err)
 		log.Printf("WARNING: Errors occured and %s was not added to HSM.", payload.Components[0].ID)
-		return false
-	}
-
-	if resp.StatusCode() == http.StatusNoContent {
-		log.Printf("INFO: Successfully added %s to HSM", payload.Components[0].ID)
-		return true
-	} else {
-		log.Printf("WARNING: An error occurred uploading %s: %s %v", payload.Components[0].ID,
-			resp.Status(), resp)
-		log.Printf("WARNING: Errors occured and %s was not added to HSM.", payload.Components[0].ID)
-		return false
+		return err
 	}
+	log.Printf("INFO: Successfully added %s to HSM", payload.Components[0].ID)
+	return nil
 }
@@ -0,0 +1,87 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log"
+
+	"github.com/Cray-HPE/hms-certs/pkg/hms_certs"
+)
+
+// ConfigureTLS sets how HSM's certificate is checked.  With insecure set it
+// isn't checked at all.  Otherwise it's verified against the system roots
+// plus, if caURI is set, the CA bundle there (a file or
+// hms_certs.VaultCAChainURI), which is watched so a rotated bundle is picked
+// up without a restart.  Does nothing if the client in use isn't a
+// RestClient.
+func ConfigureTLS(insecure bool, caURI string) error {
+	rc, ok := hsmClient.(*RestClient)
+	if !ok {
+		return nil
+	}
+
+	if insecure {
+		log.Printf("WARNING: Not verifying the HSM certificate")
+		rc.SetTLSConfig(&tls.Config{InsecureSkipVerify: true})
+		return nil
+	}
+	if caURI == "" {
+		log.Printf("INFO: Verifying the HSM certificate against the system roots")
+		rc.SetTLSConfig(&tls.Config{})
+		return nil
+	}
+
+	chain, err := hms_certs.FetchCAChain(caURI)
+	if err != nil {
+		return err
+	}
+	if err := applyCAChain(rc, chain); err != nil {
+		return err
+	}
+	log.Printf("INFO: Verifying the HSM certificate against the CA bundle at %s", caURI)
+
+	return hms_certs.CAUpdateRegister(caURI, func(chain string) {
+		if err := applyCAChain(rc, chain); err != nil {
+			log.Printf("ERROR: Ignoring updated CA bundle from %s: %s", caURI, err)
+			return
+		}
+		log.Printf("INFO: Picked up updated CA bundle from %s", caURI)
+	})
+}
+
+// applyCAChain has rc trust the certificates in chain as well as the system
+// roots.
+func applyCAChain(rc *RestClient, chain string) error {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM([]byte(hms_certs.TupleToNewline(chain))) {
+		return errors.New("no certificates found in CA bundle")
+	}
+	rc.SetTLSConfig(&tls.Config{RootCAs: pool})
+	return nil
+}
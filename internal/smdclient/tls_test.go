@@ -0,0 +1,112 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base"
+)
+
+// otherCA returns a PEM encoded self-signed CA certificate.
+func otherCA(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Rotated CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestConfigureTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	create := func() error {
+		return HSMCreateComponent(HSMCompNotification{Components: []base.Component{{ID: "x3000c0s1b0n0"}}})
+	}
+
+	Init(0, 5, ts.URL, "SmdclientTest")
+	if err := create(); err == nil {
+		t.Errorf("Unknown CA was trusted by default")
+	}
+
+	if err := ConfigureTLS(false, caFile); err != nil {
+		t.Fatalf("ConfigureTLS() error = %v", err)
+	}
+	if err := create(); err != nil {
+		t.Errorf("Call with the CA bundle failed: %v", err)
+	}
+
+	// A rotated bundle that no longer has the CA stops trusting it.
+	if err := applyCAChain(hsmClient.(*RestClient), otherCA(t)); err != nil {
+		t.Fatalf("applyCAChain() error = %v", err)
+	}
+	if err := create(); err == nil {
+		t.Errorf("CA from before the rotation is still trusted")
+	}
+	if err := applyCAChain(hsmClient.(*RestClient), "not a certificate"); err == nil {
+		t.Errorf("applyCAChain() accepted a bundle with no certificates")
+	}
+
+	if err := ConfigureTLS(true, ""); err != nil {
+		t.Fatalf("ConfigureTLS() error = %v", err)
+	}
+	if err := create(); err != nil {
+		t.Errorf("Insecure call failed: %v", err)
+	}
+
+	if err := ConfigureTLS(false, filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Errorf("ConfigureTLS() accepted a missing CA bundle")
+	}
+}
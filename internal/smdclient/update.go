@@ -0,0 +1,208 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Endpoint fields REDS leaves alone on an existing endpoint unless told to
+// overwrite them, since an admin may have changed them on purpose.
+var protectedFields = map[string]bool{
+	"User":     true,
+	"Password": true,
+}
+
+// Whether protected fields are overwritten anyway
+var overwriteProtected = false
+
+// SetOverwriteProtected sets whether REDS may overwrite the protected fields
+// (User and Password) of an endpoint HSM already has.
+func SetOverwriteProtected(overwrite bool) {
+	overwriteProtected = overwrite
+}
+
+// FieldChange is a difference between an endpoint in HSM and what REDS
+// knows about it.  Passwords are never included.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s %q -> %q", c.Field, c.Old, c.New)
+}
+
+// EndpointUpdate describes what REDS did to an endpoint HSM already had.
+// Held lists the protected fields that differ but were left alone.
+type EndpointUpdate struct {
+	ID      string        `json:"id"`
+	Changes []FieldChange `json:"changes,omitempty"`
+	Held    []FieldChange `json:"held,omitempty"`
+}
+
+func (u EndpointUpdate) String() string {
+	describe := func(changes []FieldChange) string {
+		s := make([]string, len(changes))
+		for i, c := range changes {
+			s[i] = c.String()
+		}
+		return strings.Join(s, ", ")
+	}
+	if len(u.Changes) == 0 && len(u.Held) == 0 {
+		return u.ID + ": up to date"
+	}
+	str := u.ID + ": changed [" + describe(u.Changes) + "]"
+	if len(u.Held) > 0 {
+		str += ", held [" + describe(u.Held) + "]"
+	}
+	return str
+}
+
+// diffEndpoint works out the PATCH that brings existing in line with want.
+// Fields REDS doesn't know (empty in want) are left alone, as are protected
+// fields unless overwriteProtected is set.
+func diffEndpoint(existing RedfishEndpoint, want HSMNotification) (RedfishEndpointPatch, EndpointUpdate) {
+	var patch RedfishEndpointPatch
+	update := EndpointUpdate{ID: want.ID}
+
+	compare := func(field string, old string, new string, same bool, set func(string)) {
+		if new == "" || same {
+			return
+		}
+		change := FieldChange{Field: field, Old: old, New: new}
+		if field == "Password" {
+			change.Old, change.New = "<REDACTED>", "<REDACTED>"
+		}
+		if protectedFields[field] && !overwriteProtected {
+			update.Held = append(update.Held, change)
+			return
+		}
+		update.Changes = append(update.Changes, change)
+		set(new)
+	}
+	compare("FQDN", existing.FQDN, want.FQDN, strings.EqualFold(existing.FQDN, want.FQDN),
+		func(v string) { patch.FQDN = &v })
+	compare("IPAddress", existing.IPAddress, want.IPAddress, existing.IPAddress == want.IPAddress,
+		func(v string) { patch.IPAddress = &v })
+	compare("MACAddr", existing.MACAddr, want.MACAddr, sameMAC(existing.MACAddr, want.MACAddr),
+		func(v string) { patch.MACAddr = &v })
+	compare("User", existing.User, want.User, existing.User == want.User,
+		func(v string) { patch.User = &v })
+	compare("Password", existing.Password, want.Password, existing.Password == want.Password,
+		func(v string) { patch.Password = &v })
+
+	enabled := true
+	if want.Enabled != nil {
+		enabled = *want.Enabled
+	}
+	if existing.Enabled == nil || *existing.Enabled != enabled {
+		old := "unset"
+		if existing.Enabled != nil {
+			old = fmt.Sprint(*existing.Enabled)
+		}
+		update.Changes = append(update.Changes,
+			FieldChange{Field: "Enabled", Old: old, New: fmt.Sprint(enabled)})
+		patch.Enabled = &enabled
+	}
+
+	// Have HSM rediscover the endpoint with what's changed.
+	if len(update.Changes) > 0 && enabled {
+		rediscover := true
+		patch.RediscoverOnUpdate = &rediscover
+	}
+	return patch, update
+}
+
+func sameMAC(a string, b string) bool {
+	na, errA := NormalizeMAC(a)
+	nb, errB := NormalizeMAC(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	return na == nb
+}
+
+// UpdateRedfishEndpoint brings an endpoint HSM already has in line with
+// payload, patching only the fields that differ.  The returned update says
+// what was changed and what was held back.  An endpoint another service has
+// locked or reserved is left alone and a *LockedError is returned.  HSM
+// failures are returned as *HSMError.
+func UpdateRedfishEndpoint(payload HSMNotification) (*EndpointUpdate, error) {
+	existing, err := hsmClient.GetRedfishEndpoint(payload.ID)
+	if err != nil {
+		log.Printf("WARNING: An error occurred fetching %s: %s", payload.ID, err)
+		return nil, err
+	}
+
+	patch, update := diffEndpoint(*existing, payload)
+	for _, held := range update.Held {
+		log.Printf("WARNING: %s: not overwriting protected field %s", payload.ID, held)
+	}
+	if len(update.Changes) == 0 {
+		log.Printf("INFO: %s is already up to date in HSM", payload.ID)
+		return &update, nil
+	}
+
+	if err := checkUnlocked(payload.ID); err != nil {
+		return nil, err
+	}
+	log.Printf("INFO: Updating %s", update)
+	err = hsmClient.PatchRedfishEndpoint(payload.ID, patch)
+	if err != nil {
+		log.Printf("WARNING: An error occurred patching %s: %s", payload.ID, err)
+		return nil, err
+	}
+	log.Printf("INFO: Successfully patched %s", payload.ID)
+	return &update, nil
+}
+
+// RediscoverRedfishEndpoint has HSM discover an endpoint again.  If user and
+// password are set they're sent along, replacing the credentials HSM has.
+// A locked or reserved endpoint is left alone and a *LockedError returned.
+// HSM failures are returned as *HSMError.
+func RediscoverRedfishEndpoint(xname string, user string, password string) error {
+	if err := checkUnlocked(xname); err != nil {
+		return err
+	}
+
+	enabled := true
+	patch := RedfishEndpointPatch{
+		Enabled:            &enabled,
+		RediscoverOnUpdate: &enabled,
+	}
+	if user != "" && password != "" {
+		patch.User = &user
+		patch.Password = &password
+	}
+
+	log.Printf("INFO: Asking HSM to rediscover %s", xname)
+	err := hsmClient.PatchRedfishEndpoint(xname, patch)
+	if err != nil {
+		log.Printf("WARNING: An error occurred patching %s: %s", xname, err)
+	}
+	return err
+}
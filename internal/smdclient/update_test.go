@@ -0,0 +1,123 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package smdclient
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestUpdateRedfishEndpoint(t *testing.T) {
+	enabled := true
+	existing := RedfishEndpoint{
+		ID:        "x3000c0s7b0",
+		FQDN:      "x3000c0s7b0",
+		Enabled:   &enabled,
+		User:      "admin",
+		Password:  "changed-by-admin",
+		MACAddr:   "b42e993b7028",
+		IPAddress: "10.254.1.14",
+	}
+	tests := []struct {
+		name        string
+		payload     HSMNotification
+		overwrite   bool
+		wantChanges []string
+		wantHeld    []string
+		wantPatch   RedfishEndpointPatch
+	}{{
+		name: "UpToDate",
+		// Unknown fields are left alone and MACs compare however written.
+		payload: HSMNotification{ID: "x3000c0s7b0", MACAddr: "B4:2E:99:3B:70:28"},
+	}, {
+		name:        "NewAddress",
+		payload:     HSMNotification{ID: "x3000c0s7b0", IPAddress: "10.254.1.15", MACAddr: "b4:2e:99:3b:70:29"},
+		wantChanges: []string{"IPAddress", "MACAddr"},
+		wantPatch: RedfishEndpointPatch{
+			IPAddress:          strPtr("10.254.1.15"),
+			MACAddr:            strPtr("b4:2e:99:3b:70:29"),
+			RediscoverOnUpdate: boolPtr(true),
+		},
+	}, {
+		name:     "CredentialsHeld",
+		payload:  HSMNotification{ID: "x3000c0s7b0", User: "root", Password: "initial0"},
+		wantHeld: []string{"User", "Password"},
+	}, {
+		name:        "CredentialsOverwritten",
+		payload:     HSMNotification{ID: "x3000c0s7b0", User: "root", Password: "initial0"},
+		overwrite:   true,
+		wantChanges: []string{"User", "Password"},
+		wantPatch: RedfishEndpointPatch{
+			User:               strPtr("root"),
+			Password:           strPtr("initial0"),
+			RediscoverOnUpdate: boolPtr(true),
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeHSM()
+			fake.RedfishEndpoints[existing.ID] = existing
+			SetClient(fake)
+			defer SetClient(nil)
+			SetOverwriteProtected(tt.overwrite)
+			defer SetOverwriteProtected(false)
+
+			update, err := UpdateRedfishEndpoint(tt.payload)
+			if err != nil {
+				t.Fatalf("UpdateRedfishEndpoint() error = %v", err)
+			}
+			var changes, held []string
+			for _, c := range update.Changes {
+				changes = append(changes, c.Field)
+			}
+			for _, c := range update.Held {
+				held = append(held, c.Field)
+				if c.Field == "Password" && (c.Old != "<REDACTED>" || c.New != "<REDACTED>") {
+					t.Errorf("Password not redacted: %+v", c)
+				}
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) || !reflect.DeepEqual(held, tt.wantHeld) {
+				t.Errorf("Changed %v and held %v, want %v and %v", changes, held, tt.wantChanges, tt.wantHeld)
+			}
+
+			reqs := fake.GetRequests()
+			if tt.wantChanges == nil {
+				if len(reqs) != 1 {
+					t.Errorf("Nothing to change but made requests %+v", reqs)
+				}
+				return
+			}
+			if len(reqs) != 3 || reqs[1].Path != "/locks/status" || reqs[2].Method != http.MethodPatch {
+				t.Fatalf("Requests were %+v, want GET, lock check then PATCH", reqs)
+			}
+			if patch := reqs[2].Body.(RedfishEndpointPatch); !reflect.DeepEqual(patch, tt.wantPatch) {
+				t.Errorf("PATCH was %+v, want %+v", patch, tt.wantPatch)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func boolPtr(b bool) *bool { return &b }
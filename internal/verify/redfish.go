@@ -0,0 +1,141 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// BMCVerifier checks that a BMC takes a username and password.
+type BMCVerifier interface {
+	VerifyBMC(ctx context.Context, host string, username string, password string) error
+}
+
+// RedfishVerifier logs in to BMCs through Redfish.
+type RedfishVerifier struct {
+	Client *http.Client
+}
+
+// NewRedfishVerifier creates a RedfishVerifier.  BMCs being onboarded still
+// have self-signed certificates, so they aren't verified.
+func NewRedfishVerifier(timeout time.Duration) *RedfishVerifier {
+	return &RedfishVerifier{
+		Client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+	}
+}
+
+// VerifyBMC opens a session through the SessionService and closes it
+// again.  BMCs without a SessionService are asked for their Systems
+// collection with basic authentication instead; the service root itself
+// is readable without credentials so it proves nothing.
+func (v *RedfishVerifier) VerifyBMC(ctx context.Context, host string, username string, password string) error {
+	body, _ := json.Marshal(map[string]string{"UserName": username, "Password": password})
+	resp, err := v.do(ctx, http.MethodPost, "https://"+host+"/redfish/v1/SessionService/Sessions",
+		"", "", body)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated:
+		v.closeSession(ctx, host, resp)
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: session login answered %s", ErrRejected, resp.Status)
+	case resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusMethodNotAllowed &&
+		resp.StatusCode != http.StatusNotImplemented:
+		return fmt.Errorf("session login answered %s", resp.Status)
+	}
+
+	resp, err = v.do(ctx, http.MethodGet, "https://"+host+"/redfish/v1/Systems", username, password, nil)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %s answered %s", ErrRejected, resp.Request.URL.Path, resp.Status)
+	default:
+		return fmt.Errorf("%s answered %s", resp.Request.URL.Path, resp.Status)
+	}
+}
+
+// closeSession deletes a session opened by VerifyBMC so it doesn't use up
+// one of the BMC's few session slots.  Failing to is only logged by the BMC
+// timing it out.
+func (v *RedfishVerifier) closeSession(ctx context.Context, host string, resp *http.Response) {
+	location := resp.Header.Get("Location")
+	token := resp.Header.Get("X-Auth-Token")
+	if location == "" || token == "" {
+		return
+	}
+	if location[0] == '/' {
+		location = "https://" + host + location
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, location, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("X-Auth-Token", token)
+	del, err := v.Client.Do(req)
+	if err == nil {
+		io.Copy(ioutil.Discard, del.Body)
+		del.Body.Close()
+	}
+}
+
+// do sends a request, with basic authentication if username is set, and
+// returns the response with its body already read and closed.
+func (v *RedfishVerifier) do(ctx context.Context, method string, url string, username string, password string,
+	body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	return resp, nil
+}
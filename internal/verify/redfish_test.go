@@ -0,0 +1,95 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedfishVerifier_VerifyBMC(t *testing.T) {
+	sessions := true
+	var deleted []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /redfish/v1/SessionService/Sessions":
+			if !sessions {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["UserName"] != "root" || body["Password"] != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/7")
+			w.Header().Set("X-Auth-Token", "token7")
+			w.WriteHeader(http.StatusCreated)
+		case "DELETE /redfish/v1/SessionService/Sessions/7":
+			deleted = append(deleted, r.Header.Get("X-Auth-Token"))
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /redfish/v1/Systems":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "root" || pass != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"Members":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "https://")
+	v := NewRedfishVerifier(5 * time.Second)
+
+	if err := v.VerifyBMC(context.Background(), host, "root", "s3cret"); err != nil {
+		t.Errorf("VerifyBMC() error = %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "token7" {
+		t.Errorf("Sessions deleted with tokens %v, want [token7]", deleted)
+	}
+	if err := v.VerifyBMC(context.Background(), host, "root", "wrong"); !IsRejected(err) {
+		t.Errorf("VerifyBMC() with the wrong password error = %v, want rejected", err)
+	}
+
+	// Without a SessionService the Systems collection is used.
+	sessions = false
+	if err := v.VerifyBMC(context.Background(), host, "root", "s3cret"); err != nil {
+		t.Errorf("VerifyBMC() without sessions error = %v", err)
+	}
+	if err := v.VerifyBMC(context.Background(), host, "root", "wrong"); !IsRejected(err) {
+		t.Errorf("VerifyBMC() without sessions with the wrong password error = %v, want rejected", err)
+	}
+
+	ts.Close()
+	err := v.VerifyBMC(context.Background(), host, "root", "s3cret")
+	if err == nil || IsRejected(err) || Outcome(err) != CHECK_UNREACHABLE {
+		t.Errorf("VerifyBMC() of a BMC that's gone error = %v, want unreachable", err)
+	}
+}
@@ -0,0 +1,200 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Cray-HPE/hms-reds/internal/model"
+	"github.com/gosnmp/gosnmp"
+)
+
+// sysObjectID, which every SNMP agent has
+const SNMP_SYS_OBJECT_ID = ".1.3.6.1.2.1.1.2.0"
+
+// SNMPCredentials are the SNMPv3 user based security settings of a switch.
+type SNMPCredentials struct {
+	Username     string
+	AuthProtocol string
+	AuthPassword string
+	PrivProtocol string
+	PrivPassword string
+	// noAuthNoPriv, authNoPriv or authPriv; authPriv if empty
+	SecurityLevel string
+	Context       string
+}
+
+// SwitchVerifier checks that a switch takes SNMPv3 credentials.
+type SwitchVerifier interface {
+	VerifySwitch(ctx context.Context, address string, creds SNMPCredentials) error
+}
+
+// SNMPVerifier asks switches for their sysObjectID over SNMPv3.
+type SNMPVerifier struct {
+	Port    uint16
+	Timeout time.Duration
+	Retries int
+}
+
+// NewSNMPVerifier creates an SNMPVerifier using the standard SNMP port.
+func NewSNMPVerifier(timeout time.Duration) *SNMPVerifier {
+	return &SNMPVerifier{Port: 161, Timeout: timeout, Retries: 1}
+}
+
+// gosnmp's protocols and security levels by the names model gives them
+var snmpAuthProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"MD5":     gosnmp.MD5,
+	"SHA":     gosnmp.SHA,
+	"SHA-224": gosnmp.SHA224,
+	"SHA-256": gosnmp.SHA256,
+	"SHA-384": gosnmp.SHA384,
+	"SHA-512": gosnmp.SHA512,
+}
+
+var snmpPrivProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"DES":     gosnmp.DES,
+	"AES":     gosnmp.AES,
+	"AES-192": gosnmp.AES192,
+	"AES-256": gosnmp.AES256,
+}
+
+var snmpSecurityLevels = map[string]gosnmp.SnmpV3MsgFlags{
+	model.SNMP_LEVEL_NO_AUTH_NO_PRIV: gosnmp.NoAuthNoPriv,
+	model.SNMP_LEVEL_AUTH_NO_PRIV:    gosnmp.AuthNoPriv,
+	model.SNMP_LEVEL_AUTH_PRIV:       gosnmp.AuthPriv,
+}
+
+// snmpAuthProtocol returns gosnmp's authentication protocol for one named
+// as SLS and the switch defaults do; MD5 if neither says.
+func snmpAuthProtocol(protocol string) (gosnmp.SnmpV3AuthProtocol, error) {
+	if protocol == "" {
+		protocol = model.DefaultSNMPAuthProtocol
+	}
+	name, _ := model.CanonicalSNMPAuthProtocol(protocol)
+	auth, ok := snmpAuthProtocols[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown SNMP authentication protocol %q", protocol)
+	}
+	return auth, nil
+}
+
+// snmpPrivProtocol returns gosnmp's privacy protocol for one named as SLS
+// and the switch defaults do; DES if neither says.
+func snmpPrivProtocol(protocol string) (gosnmp.SnmpV3PrivProtocol, error) {
+	if protocol == "" {
+		protocol = model.DefaultSNMPPrivProtocol
+	}
+	name, _ := model.CanonicalSNMPPrivProtocol(protocol)
+	priv, ok := snmpPrivProtocols[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown SNMP privacy protocol %q", protocol)
+	}
+	return priv, nil
+}
+
+// snmpParameters returns the message flags and USM parameters for creds.
+// Protocols above the security level are left out.
+func snmpParameters(creds SNMPCredentials) (gosnmp.SnmpV3MsgFlags, *gosnmp.UsmSecurityParameters, error) {
+	level := model.SNMP_LEVEL_AUTH_PRIV
+	if creds.SecurityLevel != "" {
+		level, _ = model.CanonicalSNMPSecurityLevel(creds.SecurityLevel)
+	}
+	flags, ok := snmpSecurityLevels[level]
+	if !ok {
+		return 0, nil, fmt.Errorf("unknown SNMP security level %q", creds.SecurityLevel)
+	}
+	params := &gosnmp.UsmSecurityParameters{
+		UserName:               creds.Username,
+		AuthenticationProtocol: gosnmp.NoAuth,
+		PrivacyProtocol:        gosnmp.NoPriv,
+	}
+	if flags&gosnmp.AuthNoPriv != 0 {
+		auth, err := snmpAuthProtocol(creds.AuthProtocol)
+		if err != nil {
+			return 0, nil, err
+		}
+		params.AuthenticationProtocol = auth
+		params.AuthenticationPassphrase = creds.AuthPassword
+	}
+	if flags&gosnmp.AuthPriv == gosnmp.AuthPriv {
+		priv, err := snmpPrivProtocol(creds.PrivProtocol)
+		if err != nil {
+			return 0, nil, err
+		}
+		params.PrivacyProtocol = priv
+		params.PrivacyPassphrase = creds.PrivPassword
+	}
+	return flags, params, nil
+}
+
+// VerifySwitch gets the switch's sysObjectID at the security level of
+// creds.  Some agents silently drop requests they can't decrypt, so a
+// wrong privacy password may look like an unreachable switch.
+func (v *SNMPVerifier) VerifySwitch(ctx context.Context, address string, creds SNMPCredentials) error {
+	flags, params, err := snmpParameters(creds)
+	if err != nil {
+		return err
+	}
+	client := &gosnmp.GoSNMP{
+		Target:             address,
+		Port:               v.Port,
+		Transport:          "udp",
+		Version:            gosnmp.Version3,
+		Timeout:            v.Timeout,
+		Retries:            v.Retries,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           flags,
+		SecurityParameters: params,
+		ContextName:        creds.Context,
+		Context:            ctx,
+		MaxOids:            gosnmp.MaxOids,
+	}
+	err = client.Connect()
+	if err != nil {
+		return err
+	}
+	defer client.Conn.Close()
+
+	result, err := client.Get([]string{SNMP_SYS_OBJECT_ID})
+	if err != nil {
+		return snmpError(err)
+	}
+	if result.Error != gosnmp.NoError {
+		return fmt.Errorf("GET %s answered %s", SNMP_SYS_OBJECT_ID, result.Error)
+	}
+	return nil
+}
+
+// snmpError marks the USM errors that mean the credentials are wrong.
+func snmpError(err error) error {
+	for _, rejected := range []error{gosnmp.ErrUnknownUsername, gosnmp.ErrWrongDigest,
+		gosnmp.ErrDecryption, gosnmp.ErrUnknownSecurityLevel} {
+		if errors.Is(err, rejected) {
+			return fmt.Errorf("%w: %s", ErrRejected, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package verify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-reds/internal/model"
+	"github.com/gosnmp/gosnmp"
+)
+
+func Test_snmpParameters(t *testing.T) {
+	flags, params, err := snmpParameters(SNMPCredentials{Username: "testuser", AuthPassword: "a", PrivPassword: "p"})
+	if err != nil || flags != gosnmp.AuthPriv || params.AuthenticationProtocol != gosnmp.MD5 ||
+		params.PrivacyProtocol != gosnmp.DES {
+		t.Errorf("snmpParameters() with no protocols = %v, %+v, %v, want authPriv with MD5 and DES", flags, params, err)
+	}
+	_, params, err = snmpParameters(SNMPCredentials{Username: "testuser", AuthProtocol: "sha1", PrivProtocol: "AES"})
+	if err != nil || params.AuthenticationProtocol != gosnmp.SHA || params.PrivacyProtocol != gosnmp.AES {
+		t.Errorf("snmpParameters() with SHA1 and AES = %+v, %v", params, err)
+	}
+	_, params, err = snmpParameters(SNMPCredentials{Username: "testuser", AuthProtocol: "SHA-512", PrivProtocol: "aes-256"})
+	if err != nil || params.AuthenticationProtocol != gosnmp.SHA512 || params.PrivacyProtocol != gosnmp.AES256 {
+		t.Errorf("snmpParameters() with SHA-512 and AES-256 = %+v, %v", params, err)
+	}
+	flags, params, err = snmpParameters(SNMPCredentials{Username: "testuser", SecurityLevel: "authNoPriv",
+		AuthProtocol: "SHA-256", AuthPassword: "a", PrivProtocol: "bogus"})
+	if err != nil || flags != gosnmp.AuthNoPriv || params.AuthenticationProtocol != gosnmp.SHA256 ||
+		params.PrivacyProtocol != gosnmp.NoPriv {
+		t.Errorf("snmpParameters() with authNoPriv = %v, %+v, %v", flags, params, err)
+	}
+	flags, params, err = snmpParameters(SNMPCredentials{Username: "testuser", SecurityLevel: "noauthnopriv"})
+	if err != nil || flags != gosnmp.NoAuthNoPriv || params.AuthenticationProtocol != gosnmp.NoAuth {
+		t.Errorf("snmpParameters() with noAuthNoPriv = %v, %+v, %v", flags, params, err)
+	}
+	// Whatever the switch defaults accept can be used
+	for _, protocol := range []string{"md5", "SHA", "sha1", "SHA_224", "sha256", "SHA-384", "sha512"} {
+		if _, ok := model.CanonicalSNMPAuthProtocol(protocol); !ok {
+			t.Errorf("model doesn't know the SNMP authentication protocol %s", protocol)
+		} else if _, err := snmpAuthProtocol(protocol); err != nil {
+			t.Errorf("snmpAuthProtocol(%s) error = %v", protocol, err)
+		}
+	}
+	for _, protocol := range []string{"des", "AES", "aes128", "AES_192", "aes-256"} {
+		if _, ok := model.CanonicalSNMPPrivProtocol(protocol); !ok {
+			t.Errorf("model doesn't know the SNMP privacy protocol %s", protocol)
+		} else if _, err := snmpPrivProtocol(protocol); err != nil {
+			t.Errorf("snmpPrivProtocol(%s) error = %v", protocol, err)
+		}
+	}
+	if _, _, err := snmpParameters(SNMPCredentials{AuthProtocol: "SHA3"}); err == nil {
+		t.Errorf("snmpParameters() with an unknown protocol succeeded")
+	}
+	if _, _, err := snmpParameters(SNMPCredentials{SecurityLevel: "paranoid"}); err == nil {
+		t.Errorf("snmpParameters() with an unknown security level succeeded")
+	}
+}
+
+func Test_snmpError(t *testing.T) {
+	for _, err := range []error{gosnmp.ErrUnknownUsername, gosnmp.ErrWrongDigest, gosnmp.ErrDecryption} {
+		if got := snmpError(err); Outcome(got) != CHECK_REJECTED {
+			t.Errorf("snmpError(%v) outcome = %s, want %s", err, Outcome(got), CHECK_REJECTED)
+		}
+	}
+	if got := snmpError(fmt.Errorf("request timeout")); Outcome(got) != CHECK_UNREACHABLE {
+		t.Errorf("snmpError() of a timeout outcome = %s, want %s", Outcome(got), CHECK_UNREACHABLE)
+	}
+}
+
+func TestSNMPVerifier_VerifySwitch_silent(t *testing.T) {
+	// A switch that never answers
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	defer conn.Close()
+
+	v := NewSNMPVerifier(100 * time.Millisecond)
+	v.Port = uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+	v.Retries = 0
+	err = v.VerifySwitch(context.Background(), "127.0.0.1", SNMPCredentials{
+		Username:     "testuser",
+		AuthPassword: "authpassword",
+		PrivPassword: "privpassword",
+	})
+	if err == nil || Outcome(err) != CHECK_UNREACHABLE {
+		t.Errorf("VerifySwitch() of a silent switch error = %v, want unreachable", err)
+	}
+}
@@ -0,0 +1,58 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package verify checks that credentials REDS seeds actually work, by
+// logging in to BMCs through Redfish and asking switches for their
+// sysObjectID over SNMPv3.
+package verify
+
+import (
+	"errors"
+)
+
+// Outcomes of a credential check
+const (
+	CHECK_VERIFIED    = "Verified"
+	CHECK_REJECTED    = "Rejected"
+	CHECK_UNREACHABLE = "Unreachable"
+)
+
+// ErrRejected is wrapped by errors for credentials the device turned down,
+// as opposed to a device that couldn't be asked.
+var ErrRejected = errors.New("credentials rejected")
+
+// IsRejected reports whether err means the credentials were turned down.
+func IsRejected(err error) bool {
+	return errors.Is(err, ErrRejected)
+}
+
+// Outcome returns the check outcome for an error from a verifier.
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return CHECK_VERIFIED
+	case IsRejected(err):
+		return CHECK_REJECTED
+	default:
+		return CHECK_UNREACHABLE
+	}
+}